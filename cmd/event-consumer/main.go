@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gorm.io/gorm"
+
+	"github.com/lorenaziviani/txstream/internal/application/events"
+	"github.com/lorenaziviani/txstream/internal/application/usecases"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/consumer"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/database"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/inbox"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/tracing"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	logger, logCloser, err := logging.Setup(cfg.Logging)
+	if err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	defer logCloser.Close()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, "txstream-event-consumer")
+	if err != nil {
+		logging.Fatal(logger, "Failed to set up tracing", "error", err)
+	}
+
+	registry := metrics.NewRegistry()
+	consumerMetrics := metrics.NewMetrics(registry)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// with the inbox, redelivered events are skipped and its entries are purged after the retention
+	var eventInbox *inbox.Inbox
+	if cfg.Inbox.Enabled {
+		db, err := database.Connect(cfg.Database, consumerMetrics, logger)
+		if err != nil {
+			logging.Fatal(logger, "Failed to connect to database", "error", err)
+		}
+		defer func() {
+			if sqlDB, err := db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		}()
+
+		if sqlDB, err := db.DB(); err == nil {
+			if err := metrics.RegisterDBStats(registry, sqlDB, cfg.Database.Name); err != nil {
+				logger.Warn("Database pool metrics unavailable", "error", err)
+			}
+		}
+		eventInbox = inbox.NewInbox(db, cfg.Kafka.GroupID, consumerMetrics, logger)
+
+		retentionJob := inbox.NewRetentionJob(cfg.Inbox, repositories.NewInboxRepository(db), consumerMetrics, logger)
+		retentionJob.Start(ctx)
+		defer retentionJob.Stop()
+	}
+
+	handlers, err := newHandlers(eventInbox, logger)
+	if err != nil {
+		logging.Fatal(logger, "Failed to register event handlers", "error", err)
+	}
+
+	eventConsumer, err := consumer.NewConsumer(&cfg.Kafka, cfg.Consumer, handlers, consumerMetrics, logger)
+	if err != nil {
+		logging.Fatal(logger, "Failed to create event consumer", "error", err)
+	}
+
+	var metricsServer *http.Server
+	if cfg.Metrics.Enabled {
+		metricsServer = metrics.NewServer(cfg.Metrics, registry)
+		go func() {
+			logger.Info("Starting metrics server", "addr", metricsServer.Addr, "path", cfg.Metrics.Path, "pprof", cfg.Metrics.PprofEnabled)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("Metrics server failed", "error", err)
+			}
+		}()
+	}
+
+	if err := eventConsumer.Run(ctx); err != nil {
+		logger.Error("Event consumer failed", "error", err)
+	}
+	logger.Info("Shutdown signal received, leaving the consumer group")
+
+	if err := eventConsumer.Close(); err != nil {
+		logger.Warn("Event consumer shutdown incomplete", "error", err)
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Consumer.HandlerTimeout)
+	defer shutdownCancel()
+
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("Metrics server shutdown incomplete", "error", err)
+		}
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Warn("Tracing shutdown incomplete", "error", err)
+	}
+
+	logger.Info("Event consumer stopped")
+}
+
+// newHandlers registers the handlers of the consumed event types, deduplicated by eventInbox
+// when it is not nil. The handlers of this binary only log the orders, services embed the
+// consumer and inbox packages with their own handlers.
+func newHandlers(eventInbox *inbox.Inbox, logger *slog.Logger) (*consumer.Registry, error) {
+	registry := consumer.NewRegistry()
+
+	err := register(registry, eventInbox, models.EventTypeOrderCreated,
+		func(ctx context.Context, event *consumer.Event, order events.OrderCreated) error {
+			logger.InfoContext(ctx, "Order created",
+				"order_number", order.OrderNumber, "customer_id", order.CustomerID,
+				"total_amount", order.TotalAmount, "currency", order.Currency)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	err = register(registry, eventInbox, usecases.OrderSnapshotEventType,
+		func(ctx context.Context, event *consumer.Event, order events.OrderSnapshot) error {
+			logger.InfoContext(ctx, "Order snapshot",
+				"order_number", order.OrderNumber, "status", order.Status, "items", len(order.Items))
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return registry, nil
+}
+
+// register registers the typed handle for eventType, running it in the inbox transaction
+// when eventInbox is not nil
+func register[T any](registry *consumer.Registry, eventInbox *inbox.Inbox, eventType string, handle func(ctx context.Context, event *consumer.Event, data T) error) error {
+	if eventInbox == nil {
+		return registry.Register(eventType, consumer.Typed(handle))
+	}
+
+	return registry.Register(eventType, inbox.Typed(eventInbox,
+		func(ctx context.Context, _ *gorm.DB, event *consumer.Event, data T) error {
+			return handle(ctx, event, data)
+		}))
+}
@@ -4,32 +4,54 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
 )
 
 func main() {
-	// Carrega variáveis de ambiente
-	if err := godotenv.Load(); err != nil {
-		log.Println("Arquivo .env não encontrado, usando variáveis de ambiente do sistema")
+	// Load environment variables
+	envErr := godotenv.Load()
+
+	logger, logCloser, err := logging.Setup(loggingConfig())
+	if err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	defer logCloser.Close()
+
+	if envErr != nil {
+		logger.Info(".env file not found, using system environment variables")
 	}
 
-	// Conecta ao banco de dados
+	// Connect to the database
 	db, err := connectDB()
 	if err != nil {
-		log.Fatalf("Erro ao conectar ao banco de dados: %v", err)
+		logging.Fatal(logger, "Failed to connect to database", "error", err)
 	}
 	defer db.Close()
 
-	// Executa as migrações
-	if err := runMigrations(db); err != nil {
-		log.Fatalf("Erro ao executar migrações: %v", err)
+	// Run the migrations
+	if err := runMigrations(db, logger); err != nil {
+		logging.Fatal(logger, "Failed to run migrations", "error", err)
 	}
 
-	log.Println("✅ Migrações executadas com sucesso")
+	logger.Info("Migrations completed")
+}
+
+// loggingConfig returns the logging configuration, or the defaults when the configuration
+// cannot be loaded, since the migrations only need the DB_* variables
+func loggingConfig() config.LoggingConfig {
+	cfg, err := config.Load()
+	if err != nil {
+		return config.LoggingConfig{Level: "info", Format: "json", MaxSizeMB: 100}
+	}
+	return cfg.Logging
 }
 
 func connectDB() (*sql.DB, error) {
@@ -65,29 +87,43 @@ func connectDB() (*sql.DB, error) {
 	return sql.Open("postgres", dsn)
 }
 
-func runMigrations(db *sql.DB) error {
-	// Lista de migrações em ordem
+func runMigrations(db *sql.DB, logger *slog.Logger) error {
+	// Migrations in order
 	migrations := []string{
 		"001_create_outbox_table.sql",
 		"002_create_orders_table.sql",
 		"003_create_events_table.sql",
+		"004_add_outbox_expires_at.sql",
+		"005_add_outbox_priority.sql",
+		"006_add_outbox_claims.sql",
+		"007_create_replay_jobs.sql",
+		"008_create_backfill_checkpoints.sql",
+		"009_add_outbox_delivery_receipts.sql",
+		"010_create_inbox_table.sql",
+		"011_add_outbox_aggregate_version.sql",
+		"012_add_outbox_correlation.sql",
+		"013_add_outbox_transaction_groups.sql",
+		"014_add_backfill_cursor.sql",
+		"015_create_outbox_transactions.sql",
+		"016_create_backfill_order_snapshots.sql",
+		"017_add_outbox_original_event_id.sql",
 	}
 
 	for _, migration := range migrations {
-		log.Printf("Executando migração: %s", migration)
+		logger.Info("Running migration", "migration", migration)
 
-		// Lê o arquivo de migração
+		// Read the migration file
 		content, err := os.ReadFile(filepath.Join("migrations", migration))
 		if err != nil {
-			return fmt.Errorf("erro ao ler arquivo de migração %s: %v", migration, err)
+			return fmt.Errorf("failed to read migration %s: %w", migration, err)
 		}
 
-		// Executa a migração
+		// Run the migration
 		if _, err := db.Exec(string(content)); err != nil {
-			return fmt.Errorf("erro ao executar migração %s: %v", migration, err)
+			return fmt.Errorf("failed to run migration %s: %w", migration, err)
 		}
 
-		log.Printf("✅ Migração %s executada com sucesso", migration)
+		logger.Info("Migration completed", "migration", migration)
 	}
 
 	return nil
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/database"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/partitioning"
+)
+
+const usage = `Usage: outbox-partitions <command>
+
+Commands:
+  convert   convert an existing unpartitioned outbox table into a partitioned table
+  maintain  create the future partitions and drop or detach the expired ones
+  list      list the partitions of the outbox table
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	logger, logCloser, err := logging.Setup(cfg.Logging)
+	if err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	defer logCloser.Close()
+
+	db, err := database.Connect(cfg.Database, nil, logger)
+	if err != nil {
+		logging.Fatal(logger, "Failed to connect to database", "error", err)
+	}
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	manager := partitioning.NewManager(cfg.Partitioning, db, nil, logger)
+
+	switch flag.Arg(0) {
+	case "convert":
+		converted, err := manager.Convert(ctx)
+		if err != nil {
+			logging.Fatal(logger, "Failed to convert outbox table", "error", err)
+		}
+		if !converted {
+			logger.Info("Outbox table is already partitioned")
+		}
+	case "maintain":
+		result, err := manager.Maintain(ctx)
+		if err != nil {
+			logging.Fatal(logger, "Partition maintenance failed", "error", err)
+		}
+		logger.Info("Partition maintenance completed",
+			"created", len(result.Created),
+			"dropped", len(result.Dropped),
+			"detached", len(result.Detached),
+			"kept", len(result.Skipped))
+	case "list":
+		partitions, err := manager.Partitions(ctx)
+		if err != nil {
+			logging.Fatal(logger, "Failed to list partitions", "error", err)
+		}
+		for _, partition := range partitions {
+			fmt.Printf("%s\t%s\t%s\n", partition.Name, formatBound(partition.From, "MINVALUE"), formatBound(partition.To, "MAXVALUE"))
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func formatBound(bound *time.Time, unbounded string) string {
+	if bound == nil {
+		return unbounded
+	}
+	return bound.Format(time.RFC3339)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/database"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/retention"
+)
+
+const usage = `Usage: outbox-retention <command> [arguments]
+
+Commands:
+  purge                 hard-delete published events older than the retention period
+  segments              list the archived segments
+  restore <segment>...  restore archived segments into the outbox (use "all" for every segment)
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	logger, logCloser, err := logging.Setup(cfg.Logging)
+	if err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	defer logCloser.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	switch flag.Arg(0) {
+	case "purge":
+		job := newJob(cfg, logger)
+		result, err := job.Run(ctx)
+		if err != nil {
+			logging.Fatal(logger, "Retention run failed", "error", err)
+		}
+		logger.Info("Retention run completed", "purged", result.Purged, "archived", result.Archived, "segments", len(result.Segments))
+	case "segments":
+		archive, err := retention.NewArchive(cfg.Retention.ArchiveDir)
+		if err != nil {
+			logging.Fatal(logger, "Failed to open archive", "error", err)
+		}
+		segments, err := archive.Segments()
+		if err != nil {
+			logging.Fatal(logger, "Failed to list segments", "error", err)
+		}
+		for _, segment := range segments {
+			fmt.Printf("%s\t%d rows\t%s - %s\n", segment.File, segment.Rows,
+				segment.FirstCreatedAt.Format("2006-01-02T15:04:05Z07:00"), segment.LastCreatedAt.Format("2006-01-02T15:04:05Z07:00"))
+		}
+	case "restore":
+		if flag.NArg() < 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		cfg.Retention.ArchiveEnabled = true
+		job := newJob(cfg, logger)
+		for _, segment := range segmentsToRestore(job, flag.Args()[1:], logger) {
+			if _, err := job.Restore(ctx, segment); err != nil {
+				logging.Fatal(logger, "Failed to restore segment", "segment", segment, "error", err)
+			}
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// newJob connects to the database and creates the retention job
+func newJob(cfg *config.Config, logger *slog.Logger) *retention.Job {
+	db, err := database.Connect(cfg.Database, nil, logger)
+	if err != nil {
+		logging.Fatal(logger, "Failed to connect to database", "error", err)
+	}
+
+	job, err := retention.NewJob(cfg.Retention, repositories.NewOutboxRepository(db, logger), nil, logger)
+	if err != nil {
+		logging.Fatal(logger, "Failed to create retention job", "error", err)
+	}
+	return job
+}
+
+// segmentsToRestore expands "all" to every segment in the archive index
+func segmentsToRestore(job *retention.Job, args []string, logger *slog.Logger) []string {
+	if len(args) != 1 || args[0] != "all" {
+		return args
+	}
+
+	segments, err := job.Archive().Segments()
+	if err != nil {
+		logging.Fatal(logger, "Failed to list segments", "error", err)
+	}
+
+	files := make([]string, len(segments))
+	for i, segment := range segments {
+		files[i] = segment.File
+	}
+	return files
+}
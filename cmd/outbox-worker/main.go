@@ -2,28 +2,64 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+
+	"github.com/lorenaziviani/txstream/internal/application/usecases"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/database"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/handlers"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/kafka"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/partitioning"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/replay"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/retention"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/tracing"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/worker"
 )
 
+const (
+	// serverShutdownTimeout bounds the wait for the requests in progress on the admin and metrics servers
+	serverShutdownTimeout = 10 * time.Second
+	// tracingShutdownTimeout bounds the flush of the spans still buffered by the exporter
+	tracingShutdownTimeout = 5 * time.Second
+)
+
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	db, err := database.Connect(cfg.Database)
+	logger, logCloser, err := logging.Setup(cfg.Logging)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	defer logCloser.Close()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, "txstream-outbox-worker")
+	if err != nil {
+		logging.Fatal(logger, "Failed to set up tracing", "error", err)
+	}
+
+	registry := metrics.NewRegistry()
+	workerMetrics := metrics.NewMetrics(registry)
+
+	db, err := database.Connect(cfg.Database, workerMetrics, logger)
+	if err != nil {
+		logging.Fatal(logger, "Failed to connect to database", "error", err)
 	}
 	defer func() {
 		if sqlDB, err := db.DB(); err == nil {
@@ -31,32 +67,194 @@ func main() {
 		}
 	}()
 
-	outboxRepo := repositories.NewOutboxRepository(db)
+	if sqlDB, err := db.DB(); err == nil {
+		if err := metrics.RegisterDBStats(registry, sqlDB, cfg.Database.Name); err != nil {
+			logger.Warn("Database pool metrics unavailable", "error", err)
+		}
+	}
 
-	metrics := metrics.NewMetrics()
+	outboxRepo := repositories.NewOutboxRepository(db, logger)
 
-	kafkaProducer, err := kafka.NewProducer(&cfg.Kafka, metrics)
+	kafkaProducer, err := kafka.NewProducer(&cfg.Kafka, workerMetrics, logger)
 	if err != nil {
-		log.Fatalf("Failed to create Kafka producer: %v", err)
+		logging.Fatal(logger, "Failed to create Kafka producer", "error", err)
 	}
 
-	outboxWorker := worker.NewOutboxWorker(cfg, outboxRepo, kafkaProducer)
+	outboxWorker := worker.NewOutboxWorker(cfg, outboxRepo, kafkaProducer, workerMetrics, logger)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go func() {
-		log.Printf("Starting outbox worker with %d workers, batch size %d, interval %v",
-			cfg.Worker.PoolSize, cfg.Worker.BatchSize, cfg.Worker.Interval)
-		outboxWorker.Start(ctx)
-	}()
+	if cfg.Retention.Enabled {
+		retentionJob, err := retention.NewJob(cfg.Retention, outboxRepo, workerMetrics, logger)
+		if err != nil {
+			logging.Fatal(logger, "Failed to create retention job", "error", err)
+		}
+		retentionJob.Start(ctx)
+		defer retentionJob.Stop()
+	}
+
+	if cfg.Watchdog.Enabled {
+		watchdog := worker.NewWatchdog(cfg.Watchdog, outboxRepo, workerMetrics, logger)
+		watchdog.Start(ctx)
+		defer watchdog.Stop()
+	}
+
+	if cfg.SLO.Enabled {
+		sloEvaluator := worker.NewSLOEvaluator(cfg.SLO, outboxRepo, workerMetrics, logger)
+		sloEvaluator.Start(ctx)
+		defer sloEvaluator.Stop()
+	}
+
+	if cfg.Partitioning.Enabled {
+		partitionManager := partitioning.NewManager(cfg.Partitioning, db, workerMetrics, logger)
+		partitionManager.Start(ctx)
+		defer partitionManager.Stop()
+	}
+
+	var reconciler *usecases.OrderReconciler
+	if cfg.Reconcile.Enabled || cfg.Admin.Enabled {
+		verifier := newDeliveryVerifier(cfg, logger)
+		if verifier != nil {
+			defer verifier.Close()
+		}
+
+		reconciler = newReconciler(cfg, db, outboxRepo, verifier, workerMetrics, logger)
+		if cfg.Reconcile.Enabled {
+			reconciler.Start(ctx)
+			defer reconciler.Stop()
+		}
+	}
+
+	var metricsServer *http.Server
+	if cfg.Metrics.Enabled {
+		metricsServer = metrics.NewServer(cfg.Metrics, registry)
+		go func() {
+			logger.Info("Starting metrics server", "addr", metricsServer.Addr, "path", cfg.Metrics.Path, "pprof", cfg.Metrics.PprofEnabled)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("Metrics server failed", "error", err)
+			}
+		}()
+	}
+
+	var adminServer *http.Server
+	if cfg.Admin.Enabled {
+		replayer, err := newReplayer(cfg, db, outboxRepo, kafkaProducer, workerMetrics, logger)
+		if err != nil {
+			logging.Fatal(logger, "Failed to create replayer", "error", err)
+		}
+		defer replayer.Stop()
+
+		adminServer = newAdminServer(cfg, outboxWorker, kafkaProducer, replayer, reconciler)
+		// Bind before starting the worker, so a worker without its control plane never runs
+		listener, err := net.Listen("tcp", adminServer.Addr)
+		if err != nil {
+			logging.Fatal(logger, "Failed to bind admin server", "addr", adminServer.Addr, "error", err)
+		}
+		go func() {
+			logger.Info("Starting admin server", "addr", adminServer.Addr)
+			if err := adminServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+				logger.Error("Admin server failed", "error", err)
+			}
+		}()
+	}
+
+	go outboxWorker.Start(ctx)
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	<-sigChan
-	log.Println("Shutdown signal received, stopping worker...")
+	logger.Info("Shutdown signal received, draining worker", "deadline", cfg.Worker.ShutdownTimeout)
 
-	outboxWorker.Stop()
-	log.Println("Outbox Worker stopped")
+	// Each component gets its own deadline, so a worker drain using its whole timeout
+	// still leaves the servers and the tracing exporter time to stop cleanly
+	if err := shutdownWithin(cfg.Worker.ShutdownTimeout, outboxWorker.Shutdown); err != nil {
+		logger.Warn("Outbox Worker shutdown incomplete", "error", err)
+	}
+
+	if adminServer != nil {
+		if err := shutdownWithin(serverShutdownTimeout, adminServer.Shutdown); err != nil {
+			logger.Warn("Admin server shutdown incomplete", "error", err)
+		}
+	}
+
+	if metricsServer != nil {
+		if err := shutdownWithin(serverShutdownTimeout, metricsServer.Shutdown); err != nil {
+			logger.Warn("Metrics server shutdown incomplete", "error", err)
+		}
+	}
+
+	if err := shutdownWithin(tracingShutdownTimeout, shutdownTracing); err != nil {
+		logger.Warn("Tracing shutdown incomplete", "error", err)
+	}
+
+	logger.Info("Outbox Worker stopped")
+}
+
+// shutdownWithin stops a component with a context bounded by its own timeout
+func shutdownWithin(timeout time.Duration, shutdown func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return shutdown(ctx)
+}
+
+// newAdminServer creates the authenticated control-plane server of the worker
+func newAdminServer(cfg *config.Config, outboxWorker *worker.OutboxWorker, producer kafka.EventProducer, replayer *replay.Replayer, reconciler *usecases.OrderReconciler) *http.Server {
+	router := mux.NewRouter()
+
+	adminRouter := router.PathPrefix("/admin/v1").Subrouter()
+	adminRouter.Use(handlers.BearerTokenMiddleware(cfg.Admin.Token))
+	handlers.NewWorkerAdminHandler(outboxWorker, producer, cfg.Worker).RegisterRoutes(adminRouter)
+	handlers.NewReplayHandler(replayer).RegisterRoutes(adminRouter)
+	handlers.NewReconciliationHandler(reconciler).RegisterRoutes(adminRouter)
+
+	return &http.Server{
+		Addr:         fmt.Sprintf("%s:%d", cfg.Admin.Host, cfg.Admin.Port),
+		Handler:      router,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: cfg.Worker.ShutdownTimeout + 15*time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+}
+
+// newReplayer creates the replayer of the admin API, archived events are replayed when archiving is enabled
+func newReplayer(cfg *config.Config, db *gorm.DB, outboxRepo repositories.OutboxRepository, producer kafka.EventProducer, metrics *metrics.Metrics, logger *slog.Logger) (*replay.Replayer, error) {
+	var archive *retention.Archive
+	if cfg.Retention.ArchiveEnabled {
+		var err error
+		if archive, err = retention.NewArchive(cfg.Retention.ArchiveDir); err != nil {
+			return nil, err
+		}
+	}
+
+	return replay.NewReplayer(cfg.Replay, repositories.NewReplayRepository(db), outboxRepo, archive, producer, metrics, logger), nil
+}
+
+// newReconciler creates the reconciler of orders, outbox events and Kafka, the delivery
+// receipts are not verified when verifier is nil
+func newReconciler(cfg *config.Config, db *gorm.DB, outboxRepo repositories.OutboxRepository, verifier *kafka.DeliveryVerifier, metrics *metrics.Metrics, logger *slog.Logger) *usecases.OrderReconciler {
+	var deliveryVerifier usecases.DeliveryVerifier
+	if verifier != nil {
+		deliveryVerifier = verifier
+	}
+
+	return usecases.NewOrderReconciler(cfg.Reconcile, repositories.NewOrderRepository(db), outboxRepo,
+		deliveryVerifier, &cfg.Worker, metrics, logger)
+}
+
+// newDeliveryVerifier connects the delivery receipt verifier, returning nil when the
+// verification is disabled or Kafka is unavailable
+func newDeliveryVerifier(cfg *config.Config, logger *slog.Logger) *kafka.DeliveryVerifier {
+	if !cfg.Reconcile.VerifyKafka || !cfg.Kafka.IsKafkaEnabled() {
+		return nil
+	}
+
+	verifier, err := kafka.NewDeliveryVerifier(&cfg.Kafka)
+	if err != nil {
+		logger.Warn("Delivery receipts will not be verified", "error", err)
+		return nil
+	}
+	return verifier
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,15 +14,22 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+
 	"github.com/lorenaziviani/txstream/internal/application/usecases"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/database"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/handlers"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/tracing"
 )
 
 var (
-	cfg *config.Config
+	cfg    *config.Config
+	logger *slog.Logger
 )
 
 func main() {
@@ -31,28 +40,65 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Initialize logging
+	var logCloser io.Closer
+	logger, logCloser, err = logging.Setup(cfg.Logging)
+	if err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	defer logCloser.Close()
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, "txstream-api")
+	if err != nil {
+		logging.Fatal(logger, "Failed to set up tracing", "error", err)
+	}
+
+	// Initialize metrics
+	registry := metrics.NewRegistry()
+	apiMetrics := metrics.NewMetrics(registry)
+
 	// Initialize database
-	if err := database.InitializeDatabase(); err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	if err := database.InitializeDatabase(apiMetrics, logger); err != nil {
+		logging.Fatal(logger, "Failed to initialize database", "error", err)
 	}
 	defer database.CloseDatabase()
 
 	// Get database connection
 	db := database.GetDB()
 
+	// Expose metrics
+	if sqlDB, err := db.DB(); err == nil {
+		if err := metrics.RegisterDBStats(registry, sqlDB, cfg.Database.Name); err != nil {
+			logger.Warn("Database pool metrics unavailable", "error", err)
+		}
+	}
+	metricsServer := startMetricsServer(registry)
+
 	// Initialize repositories
 	orderRepo := repositories.NewOrderRepository(db)
-	outboxRepo := repositories.NewOutboxRepository(db)
+	outboxRepo := repositories.NewOutboxRepository(db, logger)
 
 	// Initialize use cases
-	orderUseCase := usecases.NewOrderUseCase(orderRepo, outboxRepo, db)
+	orderUseCase := usecases.NewOrderUseCase(orderRepo, outboxRepo, db, &cfg.Worker)
+	outboxUseCase := usecases.NewOutboxUseCase(outboxRepo)
 
 	// Initialize handlers
-	orderHandler := handlers.NewOrderHandler(orderUseCase)
+	orderHandler := handlers.NewOrderHandler(orderUseCase, apiMetrics, logger)
+	outboxHandler := handlers.NewOutboxHandler(outboxUseCase, logger)
 
 	// Setup router
 	router := mux.NewRouter()
-	router.Use(loggingMiddleware)
+	router.Use(otelmux.Middleware("txstream-api"))
+	router.Use(handlers.RequestIDMiddleware)
+	accessLog := handlers.AccessLogMiddleware(apiMetrics, logger)
+	router.Use(accessLog)
+
+	// mux skips the middlewares when no route matches, so the fallback handlers are wrapped explicitly
+	router.NotFoundHandler = handlers.RequestIDMiddleware(accessLog(http.NotFoundHandler()))
+	router.MethodNotAllowedHandler = handlers.RequestIDMiddleware(accessLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	})))
 
 	// Health and readiness endpoints
 	router.HandleFunc("/health", healthCheckHandler).Methods("GET")
@@ -60,7 +106,7 @@ func main() {
 
 	// API routes
 	apiRouter := router.PathPrefix("/api/v1").Subrouter()
-	setupAPIRoutes(apiRouter, orderHandler)
+	setupAPIRoutes(apiRouter, orderHandler, outboxHandler)
 
 	// Create server
 	serverAddr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
@@ -74,9 +120,9 @@ func main() {
 
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Starting server on %s", serverAddr)
+		logger.Info("Starting server", "addr", serverAddr)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			logging.Fatal(logger, "Failed to start server", "error", err)
 		}
 	}()
 
@@ -85,7 +131,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("Shutting down server...")
+	logger.Info("Shutting down server")
 
 	// Create a deadline for server shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -93,31 +139,53 @@ func main() {
 
 	// Attempt graceful shutdown
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		logger.Error("Server forced to shutdown", "error", err)
+	}
+
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			logger.Warn("Metrics server shutdown incomplete", "error", err)
+		}
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Warn("Tracing shutdown incomplete", "error", err)
+	}
+
+	logger.Info("Server exited")
+}
+
+// startMetricsServer serves the metrics of registry on their own port, returning nil when
+// the metrics are disabled
+func startMetricsServer(registry *prometheus.Registry) *http.Server {
+	if !cfg.Metrics.Enabled {
+		return nil
 	}
 
-	log.Println("Server exited")
+	server := metrics.NewServer(cfg.Metrics, registry)
+	go func() {
+		logger.Info("Starting metrics server", "addr", server.Addr, "path", cfg.Metrics.Path, "pprof", cfg.Metrics.PprofEnabled)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("Metrics server failed", "error", err)
+		}
+	}()
+	return server
 }
 
-func setupAPIRoutes(router *mux.Router, orderHandler *handlers.OrderHandler) {
+func setupAPIRoutes(router *mux.Router, orderHandler *handlers.OrderHandler, outboxHandler *handlers.OutboxHandler) {
 	router.HandleFunc("/orders", orderHandler.CreateOrderHandler).Methods("POST")
 	router.HandleFunc("/orders", orderHandler.ListOrdersHandler).Methods("GET")
 	router.HandleFunc("/orders/{id}", orderHandler.GetOrderByIDHandler).Methods("GET")
 	router.HandleFunc("/orders/number/{orderNumber}", orderHandler.GetOrderByNumberHandler).Methods("GET")
 
-	router.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"message": "Events endpoint - em desenvolvimento"}`))
-	}).Methods("GET")
-}
-
-func loggingMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		next.ServeHTTP(w, r)
-		log.Printf("%s %s %v", r.Method, r.URL.Path, time.Since(start))
-	})
+	// The outbox administration routes can change events, so they are only served with the admin token
+	if cfg.Admin.Token == "" {
+		logger.Warn("ADMIN_TOKEN is not set, the outbox administration API is disabled")
+		return
+	}
+	eventsRouter := router.NewRoute().Subrouter()
+	eventsRouter.Use(handlers.BearerTokenMiddleware(cfg.Admin.Token))
+	outboxHandler.RegisterRoutes(eventsRouter)
 }
 
 func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
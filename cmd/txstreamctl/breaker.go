@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// adminTimeout bounds a request to the worker admin API
+const adminTimeout = 10 * time.Second
+
+func (c *cli) runBreaker(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	var method, path string
+	switch args[0] {
+	case "status":
+		method, path = http.MethodGet, "/circuit-breaker"
+	case "open":
+		method, path = http.MethodPost, "/circuit-breaker/open"
+	case "close":
+		method, path = http.MethodPost, "/circuit-breaker/close"
+	default:
+		return errUsage
+	}
+
+	var stats map[string]interface{}
+	if err := c.callAdmin(ctx, method, path, &stats); err != nil {
+		return err
+	}
+
+	return c.print(stats, func() {
+		keys := make([]string, 0, len(stats))
+		for key := range stats {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fields := make([][2]string, 0, len(keys))
+		for _, key := range keys {
+			fields = append(fields, [2]string{key, fmt.Sprintf("%v", stats[key])})
+		}
+		printFields(fields)
+	})
+}
+
+// callAdmin calls the worker admin API and decodes the JSON response into out
+func (c *cli) callAdmin(ctx context.Context, method, path string, out interface{}) error {
+	baseURL := c.adminURL
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://%s:%d/admin/v1", c.cfg.Admin.Host, c.cfg.Admin.Port)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, adminTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(baseURL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.cfg.Admin.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Admin.Token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call admin API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return fmt.Errorf("admin API returned %s: %s", resp.Status, body.Error)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gorm.io/gorm"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/database"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/kafka"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+)
+
+const usage = `Usage: txstreamctl [-o table|json] [--admin-url url] <command> [arguments]
+
+Commands:
+  outbox ls [flags]                      list outbox events, newest first
+  outbox show <id>                       show an outbox event
+  outbox trace <correlation-id>          list the events of a correlation, oldest first
+  outbox retry [flags] [<id>...]         retry failed and dead-lettered events
+  outbox requeue [flags] [<id>...]       reset the retry count and retry events
+  outbox purge                           hard-delete published events older than the retention period
+  outbox stats                           show the event counts by status and priority
+  breaker status|open|close              inspect or force the worker circuit breaker (admin API)
+  orders show <id|number>                show an order
+  orders backfill [flags]                write an OrderSnapshot event for every order
+  replay [flags]                         republish events selected by aggregate, type or time window
+  replay ls|show <id>|resume <id>        list, inspect or resume replay jobs
+  reconcile [--repair]                   find orders without outbox event and events missing from Kafka
+
+Run "txstreamctl <command> <subcommand> -h" for the flags of a command.
+`
+
+// cli holds the state shared by the commands
+type cli struct {
+	cfg      *config.Config
+	logger   *slog.Logger
+	output   string
+	adminURL string
+	conn     *gorm.DB
+	producer kafka.EventProducer
+}
+
+func main() {
+	log.SetFlags(0)
+
+	app := &cli{}
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+	}
+	flag.StringVar(&app.output, "o", outputTable, "output format: table or json")
+	flag.StringVar(&app.adminURL, "admin-url", "", "base URL of the worker admin API (default http://ADMIN_HOST:ADMIN_PORT/admin/v1)")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if app.output != outputTable && app.output != outputJSON {
+		log.Fatalf("Invalid output format: %s", app.output)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	app.cfg = cfg
+
+	// The command output goes to stdout, so the logs go to stderr unless a file is configured
+	loggingConfig := cfg.Logging
+	if loggingConfig.OutputPath == "" {
+		loggingConfig.OutputPath = "stderr"
+	}
+	logger, logCloser, err := logging.New(loggingConfig)
+	if err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	defer logCloser.Close()
+	app.logger = logger
+	defer app.close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	args := flag.Args()
+	switch args[0] {
+	case "outbox":
+		err = app.runOutbox(ctx, args[1:])
+	case "breaker":
+		err = app.runBreaker(ctx, args[1:])
+	case "orders":
+		err = app.runOrders(ctx, args[1:])
+	case "replay":
+		err = app.runReplay(ctx, args[1:])
+	case "reconcile":
+		err = app.runReconcile(ctx, args[1:])
+	default:
+		err = errUsage
+	}
+
+	if err == errUsage {
+		flag.Usage()
+		app.close()
+		os.Exit(2)
+	}
+	if err != nil {
+		app.close()
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+// db connects to the database on first use, so the commands that only call the admin API
+// do not need it
+func (c *cli) db() (*gorm.DB, error) {
+	if c.conn != nil {
+		return c.conn, nil
+	}
+
+	db, err := database.Connect(c.cfg.Database, nil, c.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	c.conn = db
+	return db, nil
+}
+
+func (c *cli) close() {
+	if c.producer != nil {
+		c.producer.Close()
+		c.producer = nil
+	}
+	if c.conn != nil {
+		if sqlDB, err := c.conn.DB(); err == nil {
+			sqlDB.Close()
+		}
+		c.conn = nil
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/lorenaziviani/txstream/internal/application/dto"
+	"github.com/lorenaziviani/txstream/internal/application/usecases"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+func (c *cli) runOrders(ctx context.Context, args []string) error {
+	if len(args) > 0 && args[0] == "backfill" {
+		return c.ordersBackfill(ctx, args[1:])
+	}
+	if len(args) != 2 || args[0] != "show" {
+		return errUsage
+	}
+
+	db, err := c.db()
+	if err != nil {
+		return err
+	}
+	orderUseCase := usecases.NewOrderUseCase(
+		repositories.NewOrderRepository(db),
+		repositories.NewOutboxRepository(db, c.logger),
+		db,
+		&c.cfg.Worker,
+	)
+
+	// An argument that is not a UUID is an order number
+	var order *dto.OrderResponse
+	if _, parseErr := uuid.Parse(args[1]); parseErr == nil {
+		order, err = orderUseCase.GetOrderByID(ctx, args[1])
+	} else {
+		order, err = orderUseCase.GetOrderByNumber(ctx, args[1])
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.print(order, func() { printOrder(order) })
+}
+
+// ordersBackfill writes the missing OrderSnapshot events. An interrupted backfill resumes
+// from its checkpoint when run again.
+func (c *cli) ordersBackfill(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("orders backfill", flag.ExitOnError)
+	rate := fs.Float64("rate", c.cfg.Backfill.RateLimit, "orders per second, 0 disables throttling")
+	pageSize := fs.Int("page-size", c.cfg.Backfill.PageSize, "orders read per page")
+	restart := fs.Bool("restart", false, "start from the first order instead of the checkpoint")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return errUsage
+	}
+
+	cfg := config.BackfillConfig{RateLimit: *rate, PageSize: *pageSize}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	db, err := c.db()
+	if err != nil {
+		return err
+	}
+	backfill := usecases.NewOrderSnapshotBackfill(
+		cfg,
+		repositories.NewOrderRepository(db),
+		repositories.NewBackfillRepository(db),
+		&c.cfg.Worker,
+	)
+
+	checkpoint, runErr := backfill.Run(ctx, *restart)
+	if checkpoint != nil {
+		if err := c.print(checkpoint, func() { printBackfillCheckpoint(checkpoint) }); err != nil {
+			return err
+		}
+	}
+	if runErr != nil {
+		return fmt.Errorf("backfill stopped, run it again to resume from the checkpoint: %w", runErr)
+	}
+	return nil
+}
+
+func printBackfillCheckpoint(checkpoint *models.BackfillCheckpoint) {
+	printFields([][2]string{
+		{"Name", checkpoint.Name},
+		{"Cursor", formatBackfillCursor(checkpoint)},
+		{"Processed", strconv.FormatInt(checkpoint.Processed, 10)},
+		{"Created", strconv.FormatInt(checkpoint.Created, 10)},
+		{"Started", formatTime(&checkpoint.StartedAt)},
+		{"Completed", formatTime(checkpoint.CompletedAt)},
+	})
+}
+
+// formatBackfillCursor describes the last order processed by a backfill
+func formatBackfillCursor(checkpoint *models.BackfillCheckpoint) string {
+	if checkpoint.CursorID == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%s)", checkpoint.CursorID, formatTime(checkpoint.CursorCreatedAt))
+}
+
+func printOrder(order *dto.OrderResponse) {
+	printFields([][2]string{
+		{"ID", order.ID.String()},
+		{"Number", order.OrderNumber},
+		{"Customer", order.CustomerID},
+		{"Status", order.Status},
+		{"Total", fmt.Sprintf("%.2f %s", order.TotalAmount, order.Currency)},
+		{"Shipping", formatAddress(order.ShippingAddress)},
+		{"Billing", formatAddress(order.BillingAddress)},
+		{"Created", formatTime(&order.CreatedAt)},
+		{"Updated", formatTime(&order.UpdatedAt)},
+	})
+
+	fmt.Println()
+	t := newTable("PRODUCT", "NAME", "QUANTITY", "UNIT PRICE", "TOTAL")
+	for _, item := range order.Items {
+		t.row(item.ProductID, item.ProductName, strconv.Itoa(item.Quantity),
+			fmt.Sprintf("%.2f", item.UnitPrice), fmt.Sprintf("%.2f", item.TotalPrice))
+	}
+	t.flush()
+}
+
+func formatAddress(address dto.AddressResponse) string {
+	return fmt.Sprintf("%s %s, %s - %s, %s, %s", address.Street, address.Number, address.City, address.State, address.ZipCode, address.Country)
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lorenaziviani/txstream/internal/application/dto"
+	"github.com/lorenaziviani/txstream/internal/application/usecases"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/retention"
+)
+
+// eventFilterFlags are the flags that select outbox events
+type eventFilterFlags struct {
+	statuses      string
+	eventType     string
+	aggregateID   string
+	aggregateType string
+	correlationID string
+	since         string
+	until         string
+}
+
+func (f *eventFilterFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.statuses, "status", "", "comma separated statuses")
+	fs.StringVar(&f.eventType, "type", "", "event type")
+	fs.StringVar(&f.aggregateID, "aggregate", "", "aggregate id")
+	fs.StringVar(&f.aggregateType, "aggregate-type", "", "aggregate type")
+	fs.StringVar(&f.correlationID, "correlation", "", "correlation id")
+	fs.StringVar(&f.since, "since", "", "events created after a RFC3339 time or a duration ago (ex: 2h)")
+	fs.StringVar(&f.until, "until", "", "events created before a RFC3339 time or a duration ago")
+}
+
+func (f *eventFilterFlags) empty() bool {
+	return *f == eventFilterFlags{}
+}
+
+func (f *eventFilterFlags) toDTO() (*dto.OutboxEventFilterDTO, error) {
+	filter := &dto.OutboxEventFilterDTO{
+		EventType:     f.eventType,
+		AggregateID:   f.aggregateID,
+		AggregateType: f.aggregateType,
+		CorrelationID: f.correlationID,
+	}
+	if f.statuses != "" {
+		filter.Statuses = strings.Split(f.statuses, ",")
+	}
+
+	var err error
+	if filter.CreatedAfter, err = parseSince(f.since); err != nil {
+		return nil, fmt.Errorf("invalid --since: %w", err)
+	}
+	if filter.CreatedBefore, err = parseSince(f.until); err != nil {
+		return nil, fmt.Errorf("invalid --until: %w", err)
+	}
+
+	return filter, nil
+}
+
+// parseSince parses a RFC3339 time or a duration relative to now
+func parseSince(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		t := time.Now().Add(-d)
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (c *cli) runOutbox(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	db, err := c.db()
+	if err != nil {
+		return err
+	}
+	outboxRepo := repositories.NewOutboxRepository(db, c.logger)
+	outboxUseCase := usecases.NewOutboxUseCase(outboxRepo)
+
+	switch args[0] {
+	case "ls":
+		return c.outboxList(ctx, outboxUseCase, args[1:])
+	case "show":
+		if len(args) != 2 {
+			return errUsage
+		}
+		event, err := outboxUseCase.GetEvent(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		return c.print(event, func() { printEvent(event) })
+	case "trace":
+		if len(args) != 2 {
+			return errUsage
+		}
+		return c.outboxTrace(ctx, outboxUseCase, args[1])
+	case "retry":
+		return c.outboxAction(ctx, outboxUseCase, "retry", args[1:])
+	case "requeue":
+		return c.outboxAction(ctx, outboxUseCase, "requeue", args[1:])
+	case "purge":
+		return c.outboxPurge(ctx, outboxRepo, args[1:])
+	case "stats":
+		return c.outboxStats(ctx, outboxRepo)
+	default:
+		return errUsage
+	}
+}
+
+func (c *cli) outboxList(ctx context.Context, outboxUseCase usecases.OutboxUseCase, args []string) error {
+	fs := flag.NewFlagSet("outbox ls", flag.ExitOnError)
+	var filter eventFilterFlags
+	filter.register(fs)
+	limit := fs.Int("limit", 50, "maximum number of events")
+	cursor := fs.String("cursor", "", "cursor of the next page")
+	fs.Parse(args)
+
+	filterDTO, err := filter.toDTO()
+	if err != nil {
+		return err
+	}
+
+	page, err := outboxUseCase.ListEvents(ctx, &dto.ListOutboxEventsRequest{
+		Filter: *filterDTO,
+		Cursor: *cursor,
+		Limit:  *limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.print(page, func() {
+		t := newTable("ID", "TYPE", "AGGREGATE", "STATUS", "PRIORITY", "RETRIES", "CREATED")
+		for _, event := range page.Events {
+			t.row(event.ID.String(), event.EventType, event.AggregateType+"/"+event.AggregateID,
+				event.Status, event.Priority, strconv.Itoa(event.RetryCount), formatTime(&event.CreatedAt))
+		}
+		t.flush()
+		if page.NextCursor != "" {
+			fmt.Printf("\nNext page: --cursor %s\n", page.NextCursor)
+		}
+	})
+}
+
+// outboxTrace lists the events of a correlation in the order they were written
+func (c *cli) outboxTrace(ctx context.Context, outboxUseCase usecases.OutboxUseCase, correlationID string) error {
+	correlated, err := outboxUseCase.ListCorrelatedEvents(ctx, correlationID)
+	if err != nil {
+		return err
+	}
+
+	return c.print(correlated, func() {
+		t := newTable("ID", "TYPE", "AGGREGATE", "VERSION", "CAUSED BY", "STATUS", "CREATED")
+		for _, event := range correlated.Events {
+			t.row(event.ID.String(), event.EventType, event.AggregateType+"/"+event.AggregateID,
+				strconv.FormatInt(event.AggregateVersion, 10), orDash(event.CausationID), event.Status, formatTime(&event.CreatedAt))
+		}
+		t.flush()
+		if correlated.Truncated {
+			fmt.Printf("\nOnly the first %d events are shown\n", correlated.Count)
+		}
+	})
+}
+
+// outboxAction retries the selected events, requeue resets their retry count first
+// so they get the full retry budget again
+func (c *cli) outboxAction(ctx context.Context, outboxUseCase usecases.OutboxUseCase, name string, args []string) error {
+	fs := flag.NewFlagSet("outbox "+name, flag.ExitOnError)
+	var filter eventFilterFlags
+	filter.register(fs)
+	all := fs.Bool("all", false, "select every event the action applies to")
+	dryRun := fs.Bool("dry-run", false, "only report the selected events")
+	limit := fs.Int("limit", 0, "maximum number of events (default 1000)")
+	fs.Parse(args)
+
+	request := &dto.OutboxBulkActionRequest{
+		IDs:    fs.Args(),
+		DryRun: *dryRun,
+		Limit:  *limit,
+	}
+	if !filter.empty() || *all {
+		filterDTO, err := filter.toDTO()
+		if err != nil {
+			return err
+		}
+		request.Filter = filterDTO
+	}
+	if len(request.IDs) == 0 && request.Filter == nil {
+		return fmt.Errorf("select events by id, filter flags or --all")
+	}
+
+	action := usecases.OutboxActionRetry
+	if name == "requeue" {
+		action = usecases.OutboxActionResetRetry
+	}
+
+	response, err := outboxUseCase.ApplyAction(ctx, action, request)
+	if err != nil {
+		return err
+	}
+
+	if name == "requeue" && !request.DryRun && len(response.IDs) > 0 {
+		// Retry only the reset events, pending ones are left untouched
+		retried, err := outboxUseCase.ApplyAction(ctx, usecases.OutboxActionRetry, &dto.OutboxBulkActionRequest{
+			IDs: uuidStrings(response),
+		})
+		if err != nil {
+			return err
+		}
+		response.Action = "requeue"
+		response.Affected = retried.Affected
+	}
+
+	return c.print(response, func() { printActionResult(response) })
+}
+
+func (c *cli) outboxPurge(ctx context.Context, outboxRepo repositories.OutboxRepository, args []string) error {
+	fs := flag.NewFlagSet("outbox purge", flag.ExitOnError)
+	olderThan := fs.Duration("older-than", c.cfg.Retention.Period, "purge the published events older than this")
+	fs.Parse(args)
+
+	cfg := c.cfg.Retention
+	cfg.Period = *olderThan
+
+	job, err := retention.NewJob(cfg, outboxRepo, nil, c.logger)
+	if err != nil {
+		return err
+	}
+
+	result, err := job.Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	output := map[string]interface{}{
+		"purged":   result.Purged,
+		"archived": result.Archived,
+		"segments": result.Segments,
+	}
+	return c.print(output, func() {
+		fmt.Printf("Purged %d events, archived %d events in %d segments\n", result.Purged, result.Archived, len(result.Segments))
+	})
+}
+
+// outboxStats is the output of the outbox stats command
+type outboxStats struct {
+	ByStatus          map[models.OutboxStatus]int64 `json:"by_status"`
+	PendingByPriority map[string]int64              `json:"pending_by_priority"`
+	OldestPending     *time.Time                    `json:"oldest_pending,omitempty"`
+	OldestPendingAge  string                        `json:"oldest_pending_age,omitempty"`
+}
+
+func (c *cli) outboxStats(ctx context.Context, outboxRepo repositories.OutboxRepository) error {
+	byStatus, err := outboxRepo.CountEventsByStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	byPriority, err := outboxRepo.CountPendingEventsByPriority(ctx)
+	if err != nil {
+		return err
+	}
+
+	oldest, err := outboxRepo.GetOldestPendingCreatedAt(ctx)
+	if err != nil {
+		return err
+	}
+
+	stats := outboxStats{
+		ByStatus:          byStatus,
+		PendingByPriority: make(map[string]int64, len(byPriority)),
+		OldestPending:     oldest,
+	}
+	for priority, count := range byPriority {
+		stats.PendingByPriority[priority.String()] = count
+	}
+	if oldest != nil {
+		stats.OldestPendingAge = time.Since(*oldest).Round(time.Second).String()
+	}
+
+	return c.print(stats, func() {
+		t := newTable("STATUS", "EVENTS")
+		for _, status := range models.OutboxStatuses {
+			t.row(string(status), strconv.FormatInt(byStatus[status], 10))
+		}
+		t.flush()
+
+		fmt.Println()
+		t = newTable("PRIORITY", "PENDING")
+		for _, priority := range models.OutboxPriorities {
+			t.row(priority.String(), strconv.FormatInt(byPriority[priority], 10))
+		}
+		t.flush()
+
+		fmt.Println()
+		printFields([][2]string{
+			{"Oldest pending", formatTime(oldest)},
+			{"Oldest pending age", orDash(stats.OldestPendingAge)},
+		})
+	})
+}
+
+func printEvent(event *dto.OutboxEventResponse) {
+	printFields([][2]string{
+		{"ID", event.ID.String()},
+		{"Event type", event.EventType},
+		{"Aggregate", event.AggregateType + "/" + event.AggregateID},
+		{"Version", strconv.FormatInt(event.AggregateVersion, 10)},
+		{"Correlation", orDash(event.CorrelationID)},
+		{"Caused by", orDash(event.CausationID)},
+		{"Transaction", formatTransaction(event)},
+		{"Status", event.Status},
+		{"Priority", event.Priority},
+		{"Retries", strconv.Itoa(event.RetryCount)},
+		{"Error", orDash(event.ErrorMessage)},
+		{"Claimed by", orDash(event.ClaimedBy)},
+		{"Claimed until", formatTime(event.ClaimedUntil)},
+		{"Created", formatTime(&event.CreatedAt)},
+		{"Published", formatTime(event.PublishedAt)},
+		{"Expires", formatTime(event.ExpiresAt)},
+		{"Delivery", formatReceipt(event)},
+		{"Metadata", formatJSON(event.EventMetadata)},
+		{"Data", formatJSON(event.EventData)},
+	})
+}
+
+func printActionResult(response *dto.OutboxBulkActionResponse) {
+	if response.DryRun {
+		fmt.Printf("Dry run: %s would apply to %d events\n", response.Action, response.Matched)
+	} else {
+		fmt.Printf("%s: matched %d, affected %d events\n", response.Action, response.Matched, response.Affected)
+	}
+	for _, id := range response.IDs {
+		fmt.Println(id)
+	}
+}
+
+func uuidStrings(response *dto.OutboxBulkActionResponse) []string {
+	ids := make([]string, len(response.IDs))
+	for i, id := range response.IDs {
+		ids[i] = id.String()
+	}
+	return ids
+}
+
+// formatTransaction formats the transaction of an event with the number of events it wrote
+func formatTransaction(event *dto.OutboxEventResponse) string {
+	if event.TransactionID == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%d events)", event.TransactionID, event.TransactionEventCount)
+}
+
+func formatReceipt(event *dto.OutboxEventResponse) string {
+	if event.KafkaPartition == nil || event.KafkaOffset == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s/%d@%d", event.KafkaTopic, *event.KafkaPartition, *event.KafkaOffset)
+}
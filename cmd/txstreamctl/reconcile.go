@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/lorenaziviani/txstream/internal/application/usecases"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/kafka"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+// runReconcile runs a reconciliation in the foreground and prints its report
+func (c *cli) runReconcile(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	repair := fs.Bool("repair", false, "enqueue compensating events for the discrepancies")
+	lookback := fs.Duration("lookback", c.cfg.Reconcile.Lookback, "check the orders and events created since this long ago")
+	grace := fs.Duration("grace", c.cfg.Reconcile.Grace, "skip the orders and events newer than this")
+	verifyKafka := fs.Bool("verify-kafka", c.cfg.Reconcile.VerifyKafka, "check the delivery receipts against Kafka")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return errUsage
+	}
+
+	cfg := c.cfg.Reconcile
+	cfg.Lookback = *lookback
+	cfg.Grace = *grace
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	db, err := c.db()
+	if err != nil {
+		return err
+	}
+
+	var verifier usecases.DeliveryVerifier
+	if *verifyKafka && c.cfg.Kafka.IsKafkaEnabled() {
+		deliveryVerifier, err := kafka.NewDeliveryVerifier(&c.cfg.Kafka)
+		if err != nil {
+			return err
+		}
+		defer deliveryVerifier.Close()
+		verifier = deliveryVerifier
+	} else if *verifyKafka {
+		c.logger.Warn("Kafka is not enabled, delivery receipts will not be verified")
+	}
+
+	reconciler := usecases.NewOrderReconciler(cfg, repositories.NewOrderRepository(db),
+		repositories.NewOutboxRepository(db, c.logger), verifier, &c.cfg.Worker, nil, c.logger)
+
+	report, err := reconciler.Reconcile(ctx, *repair)
+	if report != nil {
+		if printErr := c.print(report, func() { printReconciliationReport(report) }); printErr != nil {
+			return printErr
+		}
+	}
+	return err
+}
+
+func printReconciliationReport(report *usecases.ReconciliationReport) {
+	printFields([][2]string{
+		{"Window", formatTime(&report.WindowStart) + " - " + formatTime(&report.WindowEnd)},
+		{"Missing events", strconv.Itoa(report.Counts[usecases.DiscrepancyMissingEvent])},
+		{"Missing in Kafka", strconv.Itoa(report.Counts[usecases.DiscrepancyMissingInKafka])},
+		{"Offset mismatches", strconv.Itoa(report.Counts[usecases.DiscrepancyOffsetMismatch])},
+		{"Events verified", strconv.Itoa(report.EventsVerified)},
+		{"Events expired", strconv.Itoa(report.EventsExpired)},
+		{"Repair", strconv.FormatBool(report.Repair)},
+		{"Repaired", strconv.Itoa(report.Repaired)},
+		{"Error", orDash(report.Error)},
+	})
+
+	if len(report.Discrepancies) == 0 {
+		return
+	}
+
+	fmt.Println()
+	t := newTable("TYPE", "AGGREGATE", "EVENT", "DETAIL", "REPAIR EVENT")
+	for _, discrepancy := range report.Discrepancies {
+		eventID, repairEventID := "-", "-"
+		if discrepancy.EventID != nil {
+			eventID = discrepancy.EventID.String()
+		}
+		if discrepancy.RepairEventID != nil {
+			repairEventID = discrepancy.RepairEventID.String()
+		}
+		t.row(discrepancy.Type, discrepancy.AggregateID, eventID, discrepancy.Detail, repairEventID)
+	}
+	t.flush()
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/kafka"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/replay"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/retention"
+)
+
+// runReplay runs a replay job in the foreground. An interrupted job is resumed from its
+// last checkpoint with "replay resume <id>".
+func (c *cli) runReplay(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "ls":
+			return c.replayList(ctx)
+		case "show":
+			if len(args) != 2 {
+				return errUsage
+			}
+			return c.replayShow(ctx, args[1])
+		case "resume":
+			if len(args) != 2 {
+				return errUsage
+			}
+			return c.replayResume(ctx, args[1])
+		}
+	}
+
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	source := fs.String("source", models.ReplaySourceAll, "where to read events from: outbox, archive or all")
+	aggregateID := fs.String("aggregate", "", "aggregate id")
+	aggregateType := fs.String("aggregate-type", "", "aggregate type")
+	eventType := fs.String("type", "", "event type")
+	since := fs.String("since", "", "events created after a RFC3339 time or a duration ago (ex: 2h)")
+	until := fs.String("until", "", "events created before a RFC3339 time or a duration ago")
+	topic := fs.String("topic", "", "publish to this topic instead of the configured events topic")
+	rate := fs.Float64("rate", c.cfg.Replay.RateLimit, "events per second, 0 disables throttling")
+	dryRun := fs.Bool("dry-run", false, "only count the selected events")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return errUsage
+	}
+
+	req := &replay.Request{
+		Source:        *source,
+		AggregateID:   *aggregateID,
+		AggregateType: *aggregateType,
+		EventType:     *eventType,
+		Topic:         *topic,
+		RateLimit:     rate,
+	}
+
+	var err error
+	if req.CreatedAfter, err = parseSince(*since); err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if req.CreatedBefore, err = parseSince(*until); err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	replayer, err := c.replayer()
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		count, err := replayer.Count(ctx, req)
+		if err != nil {
+			return err
+		}
+		output := map[string]interface{}{"dry_run": true, "matched": count}
+		return c.print(output, func() {
+			fmt.Printf("Dry run: %d events would be replayed\n", count)
+		})
+	}
+
+	job, err := replayer.Create(ctx, req)
+	if err != nil {
+		return err
+	}
+	return c.replayRun(ctx, replayer, job)
+}
+
+func (c *cli) replayResume(ctx context.Context, id string) error {
+	replayer, err := c.replayer()
+	if err != nil {
+		return err
+	}
+
+	job, err := replayer.Resume(ctx, id)
+	if err != nil {
+		return err
+	}
+	return c.replayRun(ctx, replayer, job)
+}
+
+func (c *cli) replayRun(ctx context.Context, replayer *replay.Replayer, job *models.ReplayJob) error {
+	runErr := replayer.Run(ctx, job)
+	if err := c.print(job, func() { printReplayJob(job) }); err != nil {
+		return err
+	}
+	if runErr != nil {
+		return fmt.Errorf("replay job %s stopped, resume it with: txstreamctl replay resume %s: %w", job.ID, job.ID, runErr)
+	}
+	return nil
+}
+
+func (c *cli) replayList(ctx context.Context) error {
+	db, err := c.db()
+	if err != nil {
+		return err
+	}
+
+	jobs, err := repositories.NewReplayRepository(db).List(ctx, 20)
+	if err != nil {
+		return err
+	}
+
+	return c.print(jobs, func() {
+		t := newTable("ID", "STATUS", "SOURCE", "SELECTION", "REPLAYED", "CREATED")
+		for _, job := range jobs {
+			t.row(job.ID.String(), string(job.Status), job.Source, replaySelection(&job),
+				strconv.FormatInt(job.Replayed, 10), formatTime(&job.CreatedAt))
+		}
+		t.flush()
+	})
+}
+
+func (c *cli) replayShow(ctx context.Context, id string) error {
+	db, err := c.db()
+	if err != nil {
+		return err
+	}
+
+	job, err := repositories.NewReplayRepository(db).GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return c.print(job, func() { printReplayJob(job) })
+}
+
+// replayer creates a replayer publishing with its own producer
+func (c *cli) replayer() (*replay.Replayer, error) {
+	db, err := c.db()
+	if err != nil {
+		return nil, err
+	}
+
+	var archive *retention.Archive
+	if c.cfg.Retention.ArchiveEnabled {
+		if archive, err = retention.NewArchive(c.cfg.Retention.ArchiveDir); err != nil {
+			return nil, err
+		}
+	}
+
+	producer, err := kafka.NewProducer(&c.cfg.Kafka, nil, c.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka producer: %w", err)
+	}
+	c.producer = producer
+
+	return replay.NewReplayer(c.cfg.Replay, repositories.NewReplayRepository(db),
+		repositories.NewOutboxRepository(db, c.logger), archive, producer, nil, c.logger), nil
+}
+
+func printReplayJob(job *models.ReplayJob) {
+	printFields([][2]string{
+		{"ID", job.ID.String()},
+		{"Status", string(job.Status)},
+		{"Source", job.Source},
+		{"Selection", replaySelection(job)},
+		{"Topic", orDash(job.Topic)},
+		{"Rate limit", strconv.FormatFloat(job.RateLimit, 'f', -1, 64) + "/s"},
+		{"Replayed", strconv.FormatInt(job.Replayed, 10)},
+		{"Checkpoint", replayCheckpoint(job)},
+		{"Error", orDash(job.ErrorMessage)},
+		{"Heartbeat", formatTime(&job.HeartbeatAt)},
+		{"Created", formatTime(&job.CreatedAt)},
+		{"Completed", formatTime(job.CompletedAt)},
+	})
+}
+
+func replaySelection(job *models.ReplayJob) string {
+	selection := ""
+	add := func(name, value string) {
+		if value == "" {
+			return
+		}
+		if selection != "" {
+			selection += " "
+		}
+		selection += name + "=" + value
+	}
+	add("aggregate_type", job.AggregateType)
+	add("aggregate", job.AggregateID)
+	add("type", job.EventType)
+	if job.CreatedAfter != nil {
+		add("since", formatTime(job.CreatedAfter))
+	}
+	if job.CreatedBefore != nil {
+		add("until", formatTime(job.CreatedBefore))
+	}
+	return orDash(selection)
+}
+
+func replayCheckpoint(job *models.ReplayJob) string {
+	switch job.CheckpointSource {
+	case models.ReplaySourceArchive:
+		return fmt.Sprintf("archive %s after %d events", job.CheckpointSegment, job.CheckpointOffset)
+	case models.ReplaySourceOutbox:
+		return fmt.Sprintf("outbox after %s (%s)", job.CheckpointEventID, formatTime(job.CheckpointCreatedAt))
+	default:
+		return "-"
+	}
+}
@@ -0,0 +1,175 @@
+package dto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+// OutboxEventFilterDTO represents the filter of an outbox events listing or bulk action
+type OutboxEventFilterDTO struct {
+	Statuses      []string   `json:"status,omitempty"`
+	EventType     string     `json:"event_type,omitempty"`
+	AggregateID   string     `json:"aggregate_id,omitempty"`
+	AggregateType string     `json:"aggregate_type,omitempty"`
+	CorrelationID string     `json:"correlation_id,omitempty"`
+	CreatedAfter  *time.Time `json:"created_after,omitempty"`
+	CreatedBefore *time.Time `json:"created_before,omitempty"`
+}
+
+// ListOutboxEventsRequest represents the request to list outbox events
+type ListOutboxEventsRequest struct {
+	Filter OutboxEventFilterDTO
+	Cursor string
+	Limit  int
+}
+
+// OutboxBulkActionRequest represents the request to apply an action to outbox events,
+// selected either by IDs or by filter
+type OutboxBulkActionRequest struct {
+	IDs    []string              `json:"ids,omitempty"`
+	Filter *OutboxEventFilterDTO `json:"filter,omitempty"`
+	DryRun bool                  `json:"dry_run"`
+	Reason string                `json:"reason,omitempty"`
+	Limit  int                   `json:"limit,omitempty"`
+}
+
+// OutboxEventResponse represents an outbox event response
+type OutboxEventResponse struct {
+	ID                    uuid.UUID              `json:"id"`
+	AggregateID           string                 `json:"aggregate_id"`
+	AggregateType         string                 `json:"aggregate_type"`
+	AggregateVersion      int64                  `json:"aggregate_version"`
+	CorrelationID         string                 `json:"correlation_id,omitempty"`
+	CausationID           string                 `json:"causation_id,omitempty"`
+	OriginalEventID       *uuid.UUID             `json:"original_event_id,omitempty"`
+	TransactionID         *uuid.UUID             `json:"transaction_id,omitempty"`
+	TransactionEventCount int                    `json:"transaction_event_count"`
+	EventType             string                 `json:"event_type"`
+	EventData             map[string]interface{} `json:"event_data"`
+	EventMetadata         map[string]interface{} `json:"event_metadata,omitempty"`
+	Status                string                 `json:"status"`
+	Priority              string                 `json:"priority"`
+	RetryCount            int                    `json:"retry_count"`
+	ErrorMessage          string                 `json:"error_message,omitempty"`
+	ClaimedBy             string                 `json:"claimed_by,omitempty"`
+	ClaimedUntil          *time.Time             `json:"claimed_until,omitempty"`
+	CreatedAt             time.Time              `json:"created_at"`
+	PublishedAt           *time.Time             `json:"published_at,omitempty"`
+	ExpiresAt             *time.Time             `json:"expires_at,omitempty"`
+	KafkaTopic            string                 `json:"kafka_topic,omitempty"`
+	KafkaPartition        *int32                 `json:"kafka_partition,omitempty"`
+	KafkaOffset           *int64                 `json:"kafka_offset,omitempty"`
+}
+
+// OutboxEventListResponse represents a page of outbox events
+type OutboxEventListResponse struct {
+	Events     []OutboxEventResponse `json:"events"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+// CorrelatedEventsResponse represents the events of a correlation, oldest first. Truncated
+// is set when the correlation has more events than returned.
+type CorrelatedEventsResponse struct {
+	CorrelationID string                `json:"correlation_id"`
+	Count         int                   `json:"count"`
+	Truncated     bool                  `json:"truncated"`
+	Events        []OutboxEventResponse `json:"events"`
+}
+
+// OutboxBulkActionResponse represents the result of an action on outbox events.
+// On a dry run nothing is changed and Affected is zero.
+type OutboxBulkActionResponse struct {
+	Action   string      `json:"action"`
+	DryRun   bool        `json:"dry_run"`
+	Matched  int         `json:"matched"`
+	Affected int64       `json:"affected"`
+	IDs      []uuid.UUID `json:"ids"`
+}
+
+// FromOutboxModel converts an outbox event model to a response DTO
+func FromOutboxModel(event *models.OutboxEvent) *OutboxEventResponse {
+	return &OutboxEventResponse{
+		ID:                    event.ID,
+		AggregateID:           event.AggregateID,
+		AggregateType:         event.AggregateType,
+		AggregateVersion:      event.AggregateVersion,
+		CorrelationID:         event.CorrelationID,
+		CausationID:           event.CausationID,
+		OriginalEventID:       event.OriginalEventID,
+		TransactionID:         event.TransactionID,
+		TransactionEventCount: event.TransactionEventCount,
+		EventType:             event.EventType,
+		EventData:             event.EventData,
+		EventMetadata:         event.EventMetadata,
+		Status:                string(event.Status),
+		Priority:              event.Priority.String(),
+		RetryCount:            event.RetryCount,
+		ErrorMessage:          event.ErrorMessage,
+		ClaimedBy:             event.ClaimedBy,
+		ClaimedUntil:          event.ClaimedUntil,
+		CreatedAt:             event.CreatedAt,
+		PublishedAt:           event.PublishedAt,
+		ExpiresAt:             event.ExpiresAt,
+		KafkaTopic:            event.KafkaTopic,
+		KafkaPartition:        event.KafkaPartition,
+		KafkaOffset:           event.KafkaOffset,
+	}
+}
+
+// ToRepositoryFilter converts the filter DTO to a repository filter
+func (f *OutboxEventFilterDTO) ToRepositoryFilter() (repositories.OutboxEventFilter, error) {
+	filter := repositories.OutboxEventFilter{
+		EventType:     f.EventType,
+		AggregateID:   f.AggregateID,
+		AggregateType: f.AggregateType,
+		CorrelationID: f.CorrelationID,
+		CreatedAfter:  f.CreatedAfter,
+		CreatedBefore: f.CreatedBefore,
+	}
+
+	for _, value := range f.Statuses {
+		status := models.OutboxStatus(value)
+		if !status.IsValid() {
+			return filter, fmt.Errorf("invalid status: %s", value)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && !f.CreatedAfter.Before(*f.CreatedBefore) {
+		return filter, fmt.Errorf("created_after must be before created_before")
+	}
+
+	return filter, nil
+}
+
+type outboxCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// EncodeOutboxCursor encodes the position after an event as an opaque cursor
+func EncodeOutboxCursor(event *models.OutboxEvent) string {
+	data, _ := json.Marshal(outboxCursor{CreatedAt: event.CreatedAt, ID: event.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeOutboxCursor decodes a cursor returned by EncodeOutboxCursor
+func DecodeOutboxCursor(cursor string) (*repositories.OutboxCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var decoded outboxCursor
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.ID == uuid.Nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &repositories.OutboxCursor{CreatedAt: decoded.CreatedAt, ID: decoded.ID}, nil
+}
@@ -0,0 +1,32 @@
+package events
+
+import "time"
+
+// OrderCreated is the event_data of the OrderCreated events
+type OrderCreated struct {
+	OrderID     string    `json:"order_id"`
+	CustomerID  string    `json:"customer_id"`
+	OrderNumber string    `json:"order_number"`
+	Status      string    `json:"status"`
+	TotalAmount float64   `json:"total_amount"`
+	Currency    string    `json:"currency"`
+	ItemsCount  int       `json:"items_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// OrderSnapshot is the event_data of the OrderSnapshot events published by the backfill,
+// carrying the current version of an order with its items
+type OrderSnapshot struct {
+	OrderCreated
+	Items     []OrderSnapshotItem `json:"items"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// OrderSnapshotItem is an item of an OrderSnapshot
+type OrderSnapshotItem struct {
+	ProductID   string  `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+	TotalPrice  float64 `json:"total_price"`
+}
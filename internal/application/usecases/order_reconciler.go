@@ -0,0 +1,368 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/kafka"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+// Discrepancy types reported by the reconciler
+const (
+	// DiscrepancyMissingEvent is an order without OrderCreated event in the outbox
+	DiscrepancyMissingEvent = "missing_event"
+	// DiscrepancyMissingInKafka is a published event whose receipt offset is not in the partition log
+	DiscrepancyMissingInKafka = "missing_in_kafka"
+	// DiscrepancyOffsetMismatch is a published event whose receipt offset holds another message
+	DiscrepancyOffsetMismatch = "offset_mismatch"
+)
+
+// DiscrepancyTypes lists every discrepancy type
+var DiscrepancyTypes = []string{
+	DiscrepancyMissingEvent,
+	DiscrepancyMissingInKafka,
+	DiscrepancyOffsetMismatch,
+}
+
+// ErrReconcileRunning is returned when a reconciliation is already running
+var ErrReconcileRunning = errors.New("a reconciliation is already running")
+
+// reconcileNamespace derives the IDs of the compensating events from what they compensate
+var reconcileNamespace = uuid.MustParse("3b8e6f0c-5a2d-4c1e-9f7b-8d4a6c2e1f35")
+
+// DeliveryVerifier checks a delivery receipt against the Kafka partition log
+type DeliveryVerifier interface {
+	Verify(ctx context.Context, eventID uuid.UUID, receipt kafka.DeliveryReceipt) (kafka.DeliveryStatus, error)
+}
+
+// Discrepancy is an order or event that failed the reconciliation
+type Discrepancy struct {
+	Type        string     `json:"type"`
+	AggregateID string     `json:"aggregate_id"`
+	EventID     *uuid.UUID `json:"event_id,omitempty"`
+	Detail      string     `json:"detail"`
+	// RepairEventID is the compensating event enqueued for the discrepancy
+	RepairEventID *uuid.UUID `json:"repair_event_id,omitempty"`
+}
+
+// ReconciliationReport is the result of a reconciliation run. Counts has the number of
+// discrepancies by type, Discrepancies lists at most the configured report limit.
+type ReconciliationReport struct {
+	StartedAt      time.Time      `json:"started_at"`
+	FinishedAt     time.Time      `json:"finished_at"`
+	WindowStart    time.Time      `json:"window_start"`
+	WindowEnd      time.Time      `json:"window_end"`
+	Repair         bool           `json:"repair"`
+	EventsVerified int            `json:"events_verified"`
+	EventsExpired  int            `json:"events_expired"`
+	Counts         map[string]int `json:"counts"`
+	Repaired       int            `json:"repaired"`
+	Discrepancies  []Discrepancy  `json:"discrepancies"`
+	Error          string         `json:"error,omitempty"`
+}
+
+// Total returns the number of discrepancies found
+func (r *ReconciliationReport) Total() int {
+	total := 0
+	for _, count := range r.Counts {
+		total += count
+	}
+	return total
+}
+
+// OrderReconciler proves that every order produced its OrderCreated event and that the
+// published events with a delivery receipt exist in Kafka.
+//
+// Only the orders and events of the window between Lookback and Grace ago are checked:
+// the grace period leaves the worker time to publish, and the lookback must stay below the
+// outbox retention, since purged events would be reported as missing. With repair enabled,
+// a missing OrderCreated event is enqueued again and an event missing from Kafka is
+// enqueued as a copy. The IDs of the compensating events are derived from what they
+// compensate, so a discrepancy is never repaired twice.
+type OrderReconciler struct {
+	config       config.ReconcileConfig
+	orderRepo    repositories.OrderRepository
+	outboxRepo   repositories.OutboxRepository
+	verifier     DeliveryVerifier
+	workerConfig *config.WorkerConfig
+	metrics      *metrics.Metrics
+	logger       *slog.Logger
+
+	running  sync.Mutex
+	reportMu sync.RWMutex
+	last     *ReconciliationReport
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewOrderReconciler creates a new OrderReconciler. The delivery receipts are not verified
+// when verifier is nil, and metrics may be nil.
+func NewOrderReconciler(
+	cfg config.ReconcileConfig,
+	orderRepo repositories.OrderRepository,
+	outboxRepo repositories.OutboxRepository,
+	verifier DeliveryVerifier,
+	workerConfig *config.WorkerConfig,
+	metrics *metrics.Metrics,
+	logger *slog.Logger,
+) *OrderReconciler {
+	return &OrderReconciler{
+		config:       cfg,
+		orderRepo:    orderRepo,
+		outboxRepo:   outboxRepo,
+		verifier:     verifier,
+		workerConfig: workerConfig,
+		metrics:      metrics,
+		logger:       logging.OrDefault(logger),
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// Start runs the reconciliation immediately and then periodically until the context is cancelled or Stop is called
+func (r *OrderReconciler) Start(ctx context.Context) {
+	r.logger.InfoContext(ctx, "Starting reconciler",
+		"interval", r.config.Interval,
+		"lookback", r.config.Lookback,
+		"grace", r.config.Grace,
+		"repair", r.config.Repair)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := r.Reconcile(ctx, r.config.Repair); err != nil && !errors.Is(err, ErrReconcileRunning) {
+				r.logger.ErrorContext(ctx, "Reconciliation failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-r.stopChan:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic reconciliation
+func (r *OrderReconciler) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopChan)
+	})
+	r.wg.Wait()
+}
+
+// LastReport returns the report of the last run, nil before the first run
+func (r *OrderReconciler) LastReport() *ReconciliationReport {
+	r.reportMu.RLock()
+	defer r.reportMu.RUnlock()
+	return r.last
+}
+
+// Reconcile runs a single reconciliation, enqueueing compensating events when repair is set
+func (r *OrderReconciler) Reconcile(ctx context.Context, repair bool) (*ReconciliationReport, error) {
+	if !r.running.TryLock() {
+		return nil, ErrReconcileRunning
+	}
+	defer r.running.Unlock()
+
+	now := time.Now()
+	report := &ReconciliationReport{
+		StartedAt:     now,
+		WindowStart:   now.Add(-r.config.Lookback),
+		WindowEnd:     now.Add(-r.config.Grace),
+		Repair:        repair,
+		Counts:        make(map[string]int),
+		Discrepancies: []Discrepancy{},
+	}
+
+	err := r.reconcileOrders(ctx, report, repair)
+	if err == nil && r.verifier != nil && r.config.VerifyLimit > 0 {
+		err = r.verifyDeliveries(ctx, report, repair)
+	}
+
+	report.FinishedAt = time.Now()
+	if err != nil {
+		report.Error = err.Error()
+	} else if r.metrics != nil {
+		for _, discrepancyType := range DiscrepancyTypes {
+			r.metrics.SetDiscrepancies(discrepancyType, report.Counts[discrepancyType])
+		}
+	}
+
+	r.reportMu.Lock()
+	r.last = report
+	r.reportMu.Unlock()
+
+	if report.Total() > 0 {
+		r.logger.WarnContext(ctx, "Reconciliation found discrepancies", "counts", report.Counts, "repaired", report.Repaired)
+	}
+
+	return report, err
+}
+
+// reconcileOrders finds the orders of the window without OrderCreated event
+func (r *OrderReconciler) reconcileOrders(ctx context.Context, report *ReconciliationReport, repair bool) error {
+	missing, err := r.orderRepo.CountWithoutEvent(ctx, "OrderCreated", report.WindowStart, report.WindowEnd)
+	if err != nil {
+		return fmt.Errorf("failed to count orders without event: %w", err)
+	}
+	report.Counts[DiscrepancyMissingEvent] = int(missing)
+	if missing == 0 {
+		return nil
+	}
+
+	orders, err := r.orderRepo.ListWithoutEvent(ctx, "OrderCreated", report.WindowStart, report.WindowEnd, r.config.ReportLimit)
+	if err != nil {
+		return fmt.Errorf("failed to list orders without event: %w", err)
+	}
+
+	for i := range orders {
+		discrepancy := Discrepancy{
+			Type:        DiscrepancyMissingEvent,
+			AggregateID: orders[i].ID.String(),
+			Detail:      fmt.Sprintf("order %s has no OrderCreated event", orders[i].OrderNumber),
+		}
+
+		if repair {
+			event := newOrderEvent(&orders[i], "OrderCreated", orderEventData(&orders[i]), "txstream-reconciler")
+			event.ID = uuid.NewSHA1(reconcileNamespace, []byte("OrderCreated@"+orders[i].ID.String()))
+			applyOutboxPolicy(r.workerConfig, event)
+
+			if err := r.enqueue(ctx, report, &discrepancy, event); err != nil {
+				return err
+			}
+		}
+
+		report.Discrepancies = append(report.Discrepancies, discrepancy)
+	}
+
+	return nil
+}
+
+// verifyDeliveries checks the delivery receipts of the most recent events published in the window
+func (r *OrderReconciler) verifyDeliveries(ctx context.Context, report *ReconciliationReport, repair bool) error {
+	events, err := r.outboxRepo.GetDeliveredEvents(ctx, report.WindowStart, report.WindowEnd, r.config.VerifyLimit)
+	if err != nil {
+		return fmt.Errorf("failed to get delivered events: %w", err)
+	}
+
+	for i := range events {
+		event := &events[i]
+		receipt := kafka.DeliveryReceipt{
+			Topic:     event.KafkaTopic,
+			Partition: *event.KafkaPartition,
+			Offset:    *event.KafkaOffset,
+		}
+
+		status, err := r.verifier.Verify(ctx, event.MessageID(), receipt)
+		if err != nil {
+			return fmt.Errorf("failed to verify event %s: %w", event.ID, err)
+		}
+
+		var discrepancyType string
+		switch status {
+		case kafka.DeliveryFound:
+			report.EventsVerified++
+			continue
+		case kafka.DeliveryExpired:
+			report.EventsExpired++
+			continue
+		case kafka.DeliveryMismatch:
+			discrepancyType = DiscrepancyOffsetMismatch
+		default:
+			discrepancyType = DiscrepancyMissingInKafka
+		}
+
+		report.Counts[discrepancyType]++
+		eventID := event.ID
+		discrepancy := Discrepancy{
+			Type:        discrepancyType,
+			AggregateID: event.AggregateID,
+			EventID:     &eventID,
+			Detail:      fmt.Sprintf("%s event not found at %s/%d offset %d", event.EventType, receipt.Topic, receipt.Partition, receipt.Offset),
+		}
+
+		if repair {
+			if err := r.enqueue(ctx, report, &discrepancy, redeliveryEvent(event, r.workerConfig)); err != nil {
+				return err
+			}
+		}
+
+		if len(report.Discrepancies) < r.config.ReportLimit {
+			report.Discrepancies = append(report.Discrepancies, discrepancy)
+		}
+	}
+
+	return nil
+}
+
+// enqueue writes a compensating event, doing nothing when a previous run already wrote it
+func (r *OrderReconciler) enqueue(ctx context.Context, report *ReconciliationReport, discrepancy *Discrepancy, event *models.OutboxEvent) error {
+	created, err := r.outboxRepo.CreateIfNotExists(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue compensating event for %s: %w", discrepancy.AggregateID, err)
+	}
+
+	discrepancy.RepairEventID = &event.ID
+	if created {
+		report.Repaired++
+		if r.metrics != nil {
+			r.metrics.RecordEventReconciled(discrepancy.Type)
+		}
+	}
+
+	return nil
+}
+
+// redeliveryEvent creates a pending copy of an event missing from Kafka. The copy keeps the
+// aggregate version and the correlation of the original, so consumers see the missing event,
+// and is published with the ID of the original, so consumers that got it deduplicate it.
+// Its own ID only keys the outbox row.
+func redeliveryEvent(event *models.OutboxEvent, workerConfig *config.WorkerConfig) *models.OutboxEvent {
+	originalID := event.MessageID()
+
+	metadata := models.JSON{}
+	for key, value := range event.EventMetadata {
+		metadata[key] = value
+	}
+	metadata["source"] = "txstream-reconciler"
+	metadata["compensates_event_id"] = event.ID.String()
+
+	redelivery := &models.OutboxEvent{
+		ID:            uuid.NewSHA1(reconcileNamespace, []byte("redelivery@"+event.ID.String())),
+		AggregateID:   event.AggregateID,
+		AggregateType: event.AggregateType,
+		EventType:     event.EventType,
+		EventData:     event.EventData,
+		EventMetadata: metadata,
+		// the original version fills the gap the missing event left in the aggregate sequence
+		AggregateVersion: event.AggregateVersion,
+		OriginalEventID:  &originalID,
+		CorrelationID:    event.CorrelationID,
+		CausationID:      event.CausationID,
+		Status:           models.OutboxStatusPending,
+		CreatedAt:        time.Now(),
+	}
+	applyOutboxPolicy(workerConfig, redelivery)
+
+	return redelivery
+}
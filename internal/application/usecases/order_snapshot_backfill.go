@@ -0,0 +1,162 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/ratelimit"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+const (
+	// OrderSnapshotBackfillName is the checkpoint name of the order snapshot backfill
+	OrderSnapshotBackfillName = "order-snapshots"
+
+	// OrderSnapshotEventType is the event type of the order snapshots
+	OrderSnapshotEventType = "OrderSnapshot"
+)
+
+// orderSnapshotNamespace derives the snapshot event IDs from the order version
+var orderSnapshotNamespace = uuid.MustParse("6f1c3a52-8d0e-4b7a-9c55-2e4f1b7d9a10")
+
+// OrderSnapshotBackfill writes an OrderSnapshot outbox event for every existing order,
+// so consumers that started after the orders were created can build their state.
+//
+// The orders are walked oldest first in pages and the creation date and ID of the last order
+// of each page are checkpointed, so an interrupted run resumes where it stopped. The cursor
+// is a position, not an offset: orders created or deleted during a run never make it skip or
+// repeat an order. Every order version snapshotted, identified by the order ID and its
+// UpdatedAt, is recorded with its event, so running the backfill again only writes snapshots
+// for orders that changed since their last snapshot.
+type OrderSnapshotBackfill struct {
+	cfg          config.BackfillConfig
+	orderRepo    repositories.OrderRepository
+	backfillRepo repositories.BackfillRepository
+	workerConfig *config.WorkerConfig
+}
+
+// NewOrderSnapshotBackfill creates a new OrderSnapshotBackfill. The worker configuration
+// defines the priority lane and TTL of the snapshots and may be nil to use the defaults.
+func NewOrderSnapshotBackfill(
+	cfg config.BackfillConfig,
+	orderRepo repositories.OrderRepository,
+	backfillRepo repositories.BackfillRepository,
+	workerConfig *config.WorkerConfig,
+) *OrderSnapshotBackfill {
+	return &OrderSnapshotBackfill{
+		cfg:          cfg,
+		orderRepo:    orderRepo,
+		backfillRepo: backfillRepo,
+		workerConfig: workerConfig,
+	}
+}
+
+// Run writes the missing snapshots, resuming the previous run unless it completed or
+// restart is set. It returns the checkpoint of the run, also when it stops on an error.
+func (b *OrderSnapshotBackfill) Run(ctx context.Context, restart bool) (*models.BackfillCheckpoint, error) {
+	checkpoint, err := b.backfillRepo.GetCheckpoint(ctx, OrderSnapshotBackfillName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load backfill checkpoint: %w", err)
+	}
+
+	if restart || checkpoint.CompletedAt != nil {
+		checkpoint.CursorCreatedAt = nil
+		checkpoint.CursorID = nil
+		checkpoint.Processed = 0
+		checkpoint.Created = 0
+		checkpoint.StartedAt = time.Now()
+		checkpoint.CompletedAt = nil
+	}
+
+	pacer := ratelimit.NewPacer(b.cfg.RateLimit)
+
+	for {
+		orders, err := b.orderRepo.ListAfter(ctx, checkpointCursor(checkpoint), b.cfg.PageSize)
+		if err != nil {
+			return checkpoint, fmt.Errorf("failed to list orders: %w", err)
+		}
+
+		for i := range orders {
+			if err := pacer.Wait(ctx); err != nil {
+				return checkpoint, err
+			}
+
+			event := b.snapshotEvent(&orders[i])
+			snapshot := &models.BackfillOrderSnapshot{
+				OrderID:        orders[i].ID,
+				OrderUpdatedAt: orders[i].UpdatedAt,
+				EventID:        event.ID,
+			}
+			created, err := b.backfillRepo.CreateOrderSnapshot(ctx, snapshot, event)
+			if err != nil {
+				return checkpoint, fmt.Errorf("failed to create snapshot of order %s: %w", orders[i].ID, err)
+			}
+
+			checkpoint.Processed++
+			if created {
+				checkpoint.Created++
+			}
+		}
+
+		if len(orders) > 0 {
+			last := orders[len(orders)-1]
+			checkpoint.CursorCreatedAt = &last.CreatedAt
+			checkpoint.CursorID = &last.ID
+		}
+		if len(orders) < b.cfg.PageSize {
+			now := time.Now()
+			checkpoint.CompletedAt = &now
+		}
+
+		if err := b.backfillRepo.SaveCheckpoint(ctx, checkpoint); err != nil {
+			return checkpoint, fmt.Errorf("failed to save backfill checkpoint: %w", err)
+		}
+
+		if checkpoint.CompletedAt != nil {
+			return checkpoint, nil
+		}
+	}
+}
+
+// checkpointCursor returns the position of the last order processed, nil before the first page
+func checkpointCursor(checkpoint *models.BackfillCheckpoint) *repositories.OrderCursor {
+	if checkpoint.CursorCreatedAt == nil || checkpoint.CursorID == nil {
+		return nil
+	}
+	return &repositories.OrderCursor{CreatedAt: *checkpoint.CursorCreatedAt, ID: *checkpoint.CursorID}
+}
+
+// snapshotEvent creates the OrderSnapshot event of the current version of an order
+func (b *OrderSnapshotBackfill) snapshotEvent(order *models.Order) *models.OutboxEvent {
+	items := make([]map[string]interface{}, len(order.Items))
+	for i, item := range order.Items {
+		items[i] = map[string]interface{}{
+			"product_id":   item.ProductID,
+			"product_name": item.ProductName,
+			"quantity":     item.Quantity,
+			"unit_price":   item.UnitPrice,
+			"total_price":  item.TotalPrice,
+		}
+	}
+
+	eventData := orderEventData(order)
+	eventData["items"] = items
+	eventData["updated_at"] = order.UpdatedAt
+
+	event := newOrderEvent(order, OrderSnapshotEventType, eventData, "txstream-backfill")
+	event.ID = OrderSnapshotID(order)
+	applyOutboxPolicy(b.workerConfig, event)
+
+	return event
+}
+
+// OrderSnapshotID returns the ID of the snapshot of the current version of an order
+func OrderSnapshotID(order *models.Order) uuid.UUID {
+	version := order.ID.String() + "@" + order.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	return uuid.NewSHA1(orderSnapshotNamespace, []byte(version))
+}
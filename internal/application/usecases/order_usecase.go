@@ -5,11 +5,16 @@ import (
 	"fmt"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 
 	"github.com/lorenaziviani/txstream/internal/application/dto"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/tracing"
 )
 
 // OrderUseCase defines the operations of the order use case
@@ -21,25 +26,48 @@ type OrderUseCase interface {
 }
 
 type orderUseCase struct {
-	orderRepo  repositories.OrderRepository
-	outboxRepo repositories.OutboxRepository
-	db         *gorm.DB
+	orderRepo    repositories.OrderRepository
+	outboxRepo   repositories.OutboxRepository
+	db           *gorm.DB
+	workerConfig *config.WorkerConfig
 }
 
+// NewOrderUseCase creates a new OrderUseCase. The worker configuration defines the
+// priority lane and TTL of the outbox events and may be nil to use the defaults.
 func NewOrderUseCase(
 	orderRepo repositories.OrderRepository,
 	outboxRepo repositories.OutboxRepository,
 	db *gorm.DB,
+	workerConfig *config.WorkerConfig,
 ) OrderUseCase {
 	return &orderUseCase{
-		orderRepo:  orderRepo,
-		outboxRepo: outboxRepo,
-		db:         db,
+		orderRepo:    orderRepo,
+		outboxRepo:   outboxRepo,
+		db:           db,
+		workerConfig: workerConfig,
 	}
 }
 
 // CreateOrder creates a new order with ACID transaction
 func (uc *orderUseCase) CreateOrder(ctx context.Context, request *dto.CreateOrderRequest) (*dto.OrderResponse, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "orderUseCase.CreateOrder",
+		trace.WithAttributes(attribute.String("txstream.order_number", request.OrderNumber)),
+	)
+	defer span.End()
+
+	orderResponse, err := uc.createOrder(ctx, request)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.String("txstream.order_id", orderResponse.ID.String()))
+	return orderResponse, nil
+}
+
+// createOrder validates the request and stores the order with its OrderCreated event
+func (uc *orderUseCase) createOrder(ctx context.Context, request *dto.CreateOrderRequest) (*dto.OrderResponse, error) {
 	if err := uc.validateCreateOrderRequest(request); err != nil {
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
@@ -52,7 +80,7 @@ func (uc *orderUseCase) CreateOrder(ctx context.Context, request *dto.CreateOrde
 			return fmt.Errorf("failed to create order: %w", err)
 		}
 
-		outboxEvent := uc.createOrderCreatedEvent(order)
+		outboxEvent := uc.createOrderCreatedEvent(ctx, order)
 		if err := tx.Create(outboxEvent).Error; err != nil {
 			return fmt.Errorf("failed to create outbox event: %w", err)
 		}
@@ -203,9 +231,19 @@ func (uc *orderUseCase) createOrderFromRequest(request *dto.CreateOrderRequest)
 	return models.NewOrder(request.CustomerID, request.OrderNumber, items, shippingAddress, billingAddress)
 }
 
-// createOrderCreatedEvent creates the OrderCreated event
-func (uc *orderUseCase) createOrderCreatedEvent(order *models.Order) *models.OutboxEvent {
-	eventData := map[string]interface{}{
+// createOrderCreatedEvent creates the OrderCreated event, carrying the trace context of
+// the request in its metadata
+func (uc *orderUseCase) createOrderCreatedEvent(ctx context.Context, order *models.Order) *models.OutboxEvent {
+	event := newOrderEvent(order, "OrderCreated", orderEventData(order), "txstream-api")
+	applyOutboxPolicy(uc.workerConfig, event)
+	tracing.InjectMetadata(ctx, event.EventMetadata)
+
+	return event
+}
+
+// orderEventData returns the order fields shared by the order events
+func orderEventData(order *models.Order) map[string]interface{} {
+	return map[string]interface{}{
 		"order_id":     order.ID.String(),
 		"customer_id":  order.CustomerID,
 		"order_number": order.OrderNumber,
@@ -215,20 +253,33 @@ func (uc *orderUseCase) createOrderCreatedEvent(order *models.Order) *models.Out
 		"items_count":  len(order.Items),
 		"created_at":   order.CreatedAt,
 	}
+}
 
+// newOrderEvent creates a pending outbox event of an order
+func newOrderEvent(order *models.Order, eventType string, eventData map[string]interface{}, source string) *models.OutboxEvent {
 	eventMetadata := map[string]interface{}{
-		"source":         "txstream-api",
-		"version":        "1.0",
-		"correlation_id": order.ID.String(),
+		"source":  source,
+		"version": "1.0",
 	}
 
 	return &models.OutboxEvent{
 		AggregateID:   order.ID.String(),
 		AggregateType: "Order",
-		EventType:     "OrderCreated",
+		EventType:     eventType,
 		EventData:     models.JSON(eventData),
 		EventMetadata: models.JSON(eventMetadata),
 		Status:        models.OutboxStatusPending,
 		CreatedAt:     time.Now(),
 	}
 }
+
+// applyOutboxPolicy sets the priority lane and expiry configured for the event type
+func applyOutboxPolicy(workerConfig *config.WorkerConfig, event *models.OutboxEvent) {
+	if workerConfig == nil {
+		return
+	}
+
+	// The lane names are validated with the configuration, an unknown name falls back to normal
+	event.Priority, _ = models.ParseOutboxPriority(workerConfig.GetEventPriority(event.EventType))
+	event.SetTTL(workerConfig.GetEventTTL(event.EventType))
+}
@@ -0,0 +1,322 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/lorenaziviani/txstream/internal/application/dto"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+const (
+	defaultEventsPageSize = 50
+	maxEventsPageSize     = 500
+
+	// maxCorrelatedEvents bounds the events returned for a correlation
+	maxCorrelatedEvents = 10000
+
+	defaultBulkActionLimit = 1000
+	maxBulkActionLimit     = 10000
+)
+
+// Outbox event actions
+const (
+	OutboxActionRetry      = "retry"
+	OutboxActionResetRetry = "reset-retry-count"
+	OutboxActionDeadLetter = "dead-letter"
+	OutboxActionDelete     = "delete"
+)
+
+var (
+	// ErrInvalidOutboxRequest is returned when a filter, cursor or action request is invalid
+	ErrInvalidOutboxRequest = errors.New("invalid request")
+	// ErrUnknownOutboxAction is returned for an action that does not exist
+	ErrUnknownOutboxAction = errors.New("unknown action")
+	// ErrOutboxActionNotAllowed is returned when an event is not in a status the action applies to
+	ErrOutboxActionNotAllowed = errors.New("action not allowed for the event status")
+)
+
+// outboxActionStatuses lists the statuses each action applies to, nil means any status.
+// They match the conditions enforced by the repository updates.
+var outboxActionStatuses = map[string][]models.OutboxStatus{
+	OutboxActionRetry:      {models.OutboxStatusFailed, models.OutboxStatusDeadLetter},
+	OutboxActionResetRetry: {models.OutboxStatusPending, models.OutboxStatusFailed, models.OutboxStatusDeadLetter},
+	OutboxActionDeadLetter: {models.OutboxStatusPending, models.OutboxStatusFailed},
+	OutboxActionDelete:     {models.OutboxStatusPublished, models.OutboxStatusFailed, models.OutboxStatusExpired, models.OutboxStatusDeadLetter},
+}
+
+// destructiveOutboxActions lists the actions that take events out of delivery, which must
+// select events by IDs or by at least one filter criterion
+var destructiveOutboxActions = map[string]bool{
+	OutboxActionDeadLetter: true,
+	OutboxActionDelete:     true,
+}
+
+// OutboxUseCase defines the administration operations on outbox events
+type OutboxUseCase interface {
+	GetEvent(ctx context.Context, id string) (*dto.OutboxEventResponse, error)
+	ListEvents(ctx context.Context, request *dto.ListOutboxEventsRequest) (*dto.OutboxEventListResponse, error)
+	ListCorrelatedEvents(ctx context.Context, correlationID string) (*dto.CorrelatedEventsResponse, error)
+	ApplyAction(ctx context.Context, action string, request *dto.OutboxBulkActionRequest) (*dto.OutboxBulkActionResponse, error)
+	ApplyEventAction(ctx context.Context, action, id, reason string) (*dto.OutboxBulkActionResponse, error)
+}
+
+type outboxUseCase struct {
+	outboxRepo repositories.OutboxRepository
+}
+
+// NewOutboxUseCase creates a new OutboxUseCase
+func NewOutboxUseCase(outboxRepo repositories.OutboxRepository) OutboxUseCase {
+	return &outboxUseCase{
+		outboxRepo: outboxRepo,
+	}
+}
+
+// GetEvent gets an outbox event by ID
+func (uc *outboxUseCase) GetEvent(ctx context.Context, id string) (*dto.OutboxEventResponse, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("%w: invalid event id: %s", ErrInvalidOutboxRequest, id)
+	}
+
+	event, err := uc.outboxRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event: %w", err)
+	}
+
+	return dto.FromOutboxModel(event), nil
+}
+
+// ListEvents lists the events matching the filter, newest first, with cursor pagination
+func (uc *outboxUseCase) ListEvents(ctx context.Context, request *dto.ListOutboxEventsRequest) (*dto.OutboxEventListResponse, error) {
+	filter, err := request.Filter.ToRepositoryFilter()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOutboxRequest, err)
+	}
+
+	if request.Cursor != "" {
+		cursor, err := dto.DecodeOutboxCursor(request.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidOutboxRequest, err)
+		}
+		filter.After = cursor
+	}
+
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultEventsPageSize
+	}
+	if limit > maxEventsPageSize {
+		limit = maxEventsPageSize
+	}
+
+	// Fetch one extra event to know whether there is a next page
+	events, err := uc.outboxRepo.ListEvents(ctx, filter, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	response := &dto.OutboxEventListResponse{
+		Events: make([]dto.OutboxEventResponse, 0, len(events)),
+	}
+	if len(events) > limit {
+		events = events[:limit]
+		response.NextCursor = dto.EncodeOutboxCursor(&events[limit-1])
+	}
+	for i := range events {
+		response.Events = append(response.Events, *dto.FromOutboxModel(&events[i]))
+	}
+
+	return response, nil
+}
+
+// ListCorrelatedEvents lists the events sharing a correlation ID, oldest first, up to
+// maxCorrelatedEvents events
+func (uc *outboxUseCase) ListCorrelatedEvents(ctx context.Context, correlationID string) (*dto.CorrelatedEventsResponse, error) {
+	if correlationID == "" {
+		return nil, fmt.Errorf("%w: correlation id is required", ErrInvalidOutboxRequest)
+	}
+
+	response := &dto.CorrelatedEventsResponse{
+		CorrelationID: correlationID,
+		Events:        []dto.OutboxEventResponse{},
+	}
+	filter := repositories.OutboxEventFilter{CorrelationID: correlationID, Ascending: true}
+
+	for {
+		events, err := uc.outboxRepo.ListEvents(ctx, filter, maxEventsPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list correlated events: %w", err)
+		}
+
+		for i := range events {
+			if len(response.Events) == maxCorrelatedEvents {
+				response.Truncated = true
+				break
+			}
+			response.Events = append(response.Events, *dto.FromOutboxModel(&events[i]))
+		}
+
+		if response.Truncated || len(events) < maxEventsPageSize {
+			break
+		}
+		last := events[len(events)-1]
+		filter.After = &repositories.OutboxCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	response.Count = len(response.Events)
+
+	return response, nil
+}
+
+// ApplyAction applies an action to the events selected by IDs or by filter. Only the events
+// in a status the action applies to are selected, and a dry run only reports them.
+func (uc *outboxUseCase) ApplyAction(ctx context.Context, action string, request *dto.OutboxBulkActionRequest) (*dto.OutboxBulkActionResponse, error) {
+	statuses, ok := outboxActionStatuses[action]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownOutboxAction, action)
+	}
+
+	filter, err := uc.actionFilter(action, request)
+	if err != nil {
+		return nil, err
+	}
+	filter.Statuses = restrictStatuses(filter.Statuses, statuses)
+
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultBulkActionLimit
+	}
+	if limit > maxBulkActionLimit {
+		limit = maxBulkActionLimit
+	}
+
+	response := &dto.OutboxBulkActionResponse{
+		Action: action,
+		DryRun: request.DryRun,
+		IDs:    []uuid.UUID{},
+	}
+
+	// A filter restricted to statuses the action does not apply to selects nothing
+	if statuses != nil && len(filter.Statuses) == 0 {
+		return response, nil
+	}
+
+	events, err := uc.outboxRepo.ListEvents(ctx, filter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select events: %w", err)
+	}
+
+	for _, event := range events {
+		response.IDs = append(response.IDs, event.ID)
+	}
+	response.Matched = len(response.IDs)
+
+	if request.DryRun || len(response.IDs) == 0 {
+		return response, nil
+	}
+
+	affected, err := uc.runAction(ctx, action, response.IDs, request.Reason)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s events: %w", action, err)
+	}
+	response.Affected = affected
+
+	return response, nil
+}
+
+// ApplyEventAction applies an action to a single event
+func (uc *outboxUseCase) ApplyEventAction(ctx context.Context, action, id, reason string) (*dto.OutboxBulkActionResponse, error) {
+	event, err := uc.GetEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := uc.ApplyAction(ctx, action, &dto.OutboxBulkActionRequest{IDs: []string{id}, Reason: reason})
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Affected == 0 {
+		return nil, fmt.Errorf("%w: cannot %s event %s in status %s", ErrOutboxActionNotAllowed, action, id, event.Status)
+	}
+
+	return response, nil
+}
+
+// actionFilter builds the repository filter of an action request
+func (uc *outboxUseCase) actionFilter(action string, request *dto.OutboxBulkActionRequest) (repositories.OutboxEventFilter, error) {
+	if len(request.IDs) == 0 && request.Filter == nil {
+		return repositories.OutboxEventFilter{}, fmt.Errorf("%w: ids or filter is required", ErrInvalidOutboxRequest)
+	}
+
+	var filter repositories.OutboxEventFilter
+	if request.Filter != nil {
+		var err error
+		if filter, err = request.Filter.ToRepositoryFilter(); err != nil {
+			return filter, fmt.Errorf("%w: %v", ErrInvalidOutboxRequest, err)
+		}
+		if destructiveOutboxActions[action] && len(request.IDs) == 0 && isEmptyFilter(filter) {
+			return filter, fmt.Errorf("%w: %s requires ids or a non-empty filter", ErrInvalidOutboxRequest, action)
+		}
+	}
+
+	for _, value := range request.IDs {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return filter, fmt.Errorf("%w: invalid event id: %s", ErrInvalidOutboxRequest, value)
+		}
+		filter.IDs = append(filter.IDs, id)
+	}
+
+	return filter, nil
+}
+
+// runAction applies an action to the selected events and returns how many were changed
+func (uc *outboxUseCase) runAction(ctx context.Context, action string, ids []uuid.UUID, reason string) (int64, error) {
+	switch action {
+	case OutboxActionRetry:
+		return uc.outboxRepo.RetryEvents(ctx, ids)
+	case OutboxActionResetRetry:
+		return uc.outboxRepo.ResetRetryCount(ctx, ids)
+	case OutboxActionDeadLetter:
+		if reason == "" {
+			reason = "Moved to dead letter by an operator"
+		}
+		return uc.outboxRepo.DeadLetterEvents(ctx, ids, reason)
+	case OutboxActionDelete:
+		return uc.outboxRepo.DeleteEvents(ctx, ids)
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownOutboxAction, action)
+	}
+}
+
+// isEmptyFilter reports whether a filter selects every event
+func isEmptyFilter(filter repositories.OutboxEventFilter) bool {
+	return len(filter.Statuses) == 0 && filter.EventType == "" && filter.AggregateID == "" &&
+		filter.AggregateType == "" && filter.CorrelationID == "" &&
+		filter.CreatedAfter == nil && filter.CreatedBefore == nil
+}
+
+// restrictStatuses intersects the requested statuses with the statuses an action applies to
+func restrictStatuses(requested, allowed []models.OutboxStatus) []models.OutboxStatus {
+	if allowed == nil {
+		return requested
+	}
+	if len(requested) == 0 {
+		return allowed
+	}
+
+	var statuses []models.OutboxStatus
+	for _, status := range requested {
+		for _, candidate := range allowed {
+			if status == candidate {
+				statuses = append(statuses, status)
+				break
+			}
+		}
+	}
+	return statuses
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -9,12 +10,23 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Kafka    KafkaConfig    `mapstructure:"kafka"`
-	Worker   WorkerConfig   `mapstructure:"worker"`
-	Metrics  MetricsConfig  `mapstructure:"metrics"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
+	Server       ServerConfig       `mapstructure:"server"`
+	Database     DatabaseConfig     `mapstructure:"database"`
+	Kafka        KafkaConfig        `mapstructure:"kafka"`
+	Worker       WorkerConfig       `mapstructure:"worker"`
+	Metrics      MetricsConfig      `mapstructure:"metrics"`
+	Logging      LoggingConfig      `mapstructure:"logging"`
+	Retention    RetentionConfig    `mapstructure:"retention"`
+	Partitioning PartitioningConfig `mapstructure:"partitioning"`
+	Watchdog     WatchdogConfig     `mapstructure:"watchdog"`
+	Admin        AdminConfig        `mapstructure:"admin"`
+	Replay       ReplayConfig       `mapstructure:"replay"`
+	Backfill     BackfillConfig     `mapstructure:"backfill"`
+	Reconcile    ReconcileConfig    `mapstructure:"reconcile"`
+	SLO          SLOConfig          `mapstructure:"slo"`
+	Tracing      TracingConfig      `mapstructure:"tracing"`
+	Consumer     ConsumerConfig     `mapstructure:"consumer"`
+	Inbox        InboxConfig        `mapstructure:"inbox"`
 }
 
 type ServerConfig struct {
@@ -34,8 +46,9 @@ type DatabaseConfig struct {
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
 
-	LogLevel      string `mapstructure:"log_level"`
-	SlowQueryTime int    `mapstructure:"slow_query_time"`
+	LogLevel string `mapstructure:"log_level"`
+	// SlowQueryTime is the threshold in milliseconds above which a query is logged as slow, 0 disables the log
+	SlowQueryTime int `mapstructure:"slow_query_time"`
 }
 
 type KafkaConfig struct {
@@ -70,18 +83,218 @@ type WorkerConfig struct {
 	Interval   time.Duration `mapstructure:"interval"`
 	MaxRetries int           `mapstructure:"max_retries"`
 	RetryDelay time.Duration `mapstructure:"retry_delay"`
+
+	LeaseDuration   time.Duration `mapstructure:"lease_duration"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+
+	DefaultEventTTL time.Duration `mapstructure:"default_event_ttl"`
+	EventTTLs       string        `mapstructure:"event_ttls"`
+
+	EventPriorities string `mapstructure:"event_priorities"`
+	PriorityWeights string `mapstructure:"priority_weights"`
+
+	TopicRateLimits     string        `mapstructure:"topic_rate_limits"`
+	EventTypeRateLimits string        `mapstructure:"event_type_rate_limits"`
+	RateLimitMaxWait    time.Duration `mapstructure:"rate_limit_max_wait"`
+
+	AdaptiveEnabled     bool          `mapstructure:"adaptive_enabled"`
+	MinInterval         time.Duration `mapstructure:"min_interval"`
+	MaxInterval         time.Duration `mapstructure:"max_interval"`
+	MinBatchSize        int           `mapstructure:"min_batch_size"`
+	MaxBatchSize        int           `mapstructure:"max_batch_size"`
+	TargetBatchDuration time.Duration `mapstructure:"target_batch_duration"`
 }
 
+// MetricsConfig configures the dedicated HTTP server exposing the Prometheus metrics.
+// With PprofEnabled the server also serves the net/http/pprof endpoints under /debug/pprof/.
 type MetricsConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	Host         string `mapstructure:"host"`
+	Port         int    `mapstructure:"port"`
+	Path         string `mapstructure:"path"`
+	PprofEnabled bool   `mapstructure:"pprof_enabled"`
+}
+
+// AdminConfig configures the control-plane HTTP API of the outbox worker.
+// Every request must carry the token as a bearer token.
+type AdminConfig struct {
 	Enabled bool   `mapstructure:"enabled"`
+	Host    string `mapstructure:"host"`
 	Port    int    `mapstructure:"port"`
-	Path    string `mapstructure:"path"`
+	Token   string `mapstructure:"token"`
+}
+
+type RetentionConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	Period           time.Duration `mapstructure:"period"`
+	Interval         time.Duration `mapstructure:"interval"`
+	BatchSize        int           `mapstructure:"batch_size"`
+	MaxBatchesPerRun int           `mapstructure:"max_batches_per_run"`
+
+	ArchiveEnabled bool   `mapstructure:"archive_enabled"`
+	ArchiveDir     string `mapstructure:"archive_dir"`
+}
+
+// PartitioningConfig configures the range partitions of the outbox table on created_at.
+// Dropping old partitions replaces the DELETE based retention for partitioned tables.
+type PartitioningConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Granularity string        `mapstructure:"granularity"`
+	Premake     int           `mapstructure:"premake"`
+	Retention   time.Duration `mapstructure:"retention"`
+	DetachOnly  bool          `mapstructure:"detach_only"`
+	Interval    time.Duration `mapstructure:"interval"`
+}
+
+// WatchdogConfig configures the detection of stuck and stalled outbox events.
+// Pending events older than EscalateStuckAfter are logged as errors and reported apart, so
+// they can page; they stay pending. Zero disables the escalation.
+type WatchdogConfig struct {
+	Enabled            bool          `mapstructure:"enabled"`
+	Interval           time.Duration `mapstructure:"interval"`
+	StuckThreshold     time.Duration `mapstructure:"stuck_threshold"`
+	EscalateStuckAfter time.Duration `mapstructure:"escalate_stuck_after"`
+	AlertLimit         int           `mapstructure:"alert_limit"`
+}
+
+// ReplayConfig configures the replay jobs. RateLimit is the default publish rate in
+// events per second, zero disables throttling. A job saves its progress every
+// CheckpointEvery events and at least every CheckpointInterval.
+type ReplayConfig struct {
+	RateLimit          float64       `mapstructure:"rate_limit"`
+	BatchSize          int           `mapstructure:"batch_size"`
+	CheckpointEvery    int           `mapstructure:"checkpoint_every"`
+	CheckpointInterval time.Duration `mapstructure:"checkpoint_interval"`
+}
+
+// BackfillConfig configures the snapshot backfill. RateLimit is in orders per second,
+// zero disables throttling.
+type BackfillConfig struct {
+	RateLimit float64 `mapstructure:"rate_limit"`
+	PageSize  int     `mapstructure:"page_size"`
+}
+
+// ReconcileConfig configures the reconciliation of orders, outbox events and Kafka.
+// Orders created between Lookback and Grace ago are checked for their OrderCreated event,
+// and the delivery receipts of up to VerifyLimit events published in the same window are
+// checked against Kafka. Repair enqueues compensating events for the discrepancies.
+type ReconcileConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Interval    time.Duration `mapstructure:"interval"`
+	Lookback    time.Duration `mapstructure:"lookback"`
+	Grace       time.Duration `mapstructure:"grace"`
+	Repair      bool          `mapstructure:"repair"`
+	VerifyKafka bool          `mapstructure:"verify_kafka"`
+	VerifyLimit int           `mapstructure:"verify_limit"`
+	ReportLimit int           `mapstructure:"report_limit"`
+}
+
+// SLOConfig configures the delivery latency SLO: Objective is the ratio of events that must
+// be published within Threshold of their creation over Window. The burn rate is also
+// evaluated over each of the BurnRateWindows, in the format "1h,6h".
+type SLOConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Objective       float64       `mapstructure:"objective"`
+	Threshold       time.Duration `mapstructure:"threshold"`
+	Window          time.Duration `mapstructure:"window"`
+	BurnRateWindows string        `mapstructure:"burn_rate_windows"`
+	Interval        time.Duration `mapstructure:"interval"`
 }
 
+// TracingConfig configures the OpenTelemetry tracing. Exporter is "otlp", which sends the
+// spans over OTLP/HTTP to Endpoint, or "stdout". SampleRatio is the ratio of new traces
+// recorded, traces started upstream follow the sampling decision of their parent.
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Exporter    string  `mapstructure:"exporter"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+const (
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+)
+
+// ConsumerConfig configures the event consumer runtime, which joins KafkaConfig.GroupID on
+// KafkaConfig.TopicEvents. A failed handler is retried MaxRetries times with an exponential
+// backoff from RetryBackoff to MaxRetryBackoff, then the message is published to DLQTopic, or
+// discarded when the DLQ is disabled. Offsets are committed every CommitInterval.
+//
+// SequencePolicy checks the aggregate version of the events: "flag" handles every event and
+// reports gaps, duplicates and late events, "buffer" also skips duplicates and holds the events
+// following a gap until the missing versions arrive, at most SequenceBufferTimeout and
+// SequenceBufferSize events per partition. SequenceMaxAggregates bounds the aggregates tracked
+// per partition.
+type ConsumerConfig struct {
+	MaxRetries        int           `mapstructure:"max_retries"`
+	RetryBackoff      time.Duration `mapstructure:"retry_backoff"`
+	MaxRetryBackoff   time.Duration `mapstructure:"max_retry_backoff"`
+	HandlerTimeout    time.Duration `mapstructure:"handler_timeout"`
+	DLQEnabled        bool          `mapstructure:"dlq_enabled"`
+	DLQTopic          string        `mapstructure:"dlq_topic"`
+	RebalanceStrategy string        `mapstructure:"rebalance_strategy"`
+	CommitInterval    time.Duration `mapstructure:"commit_interval"`
+
+	SequencePolicy        string        `mapstructure:"sequence_policy"`
+	SequenceBufferSize    int           `mapstructure:"sequence_buffer_size"`
+	SequenceBufferTimeout time.Duration `mapstructure:"sequence_buffer_timeout"`
+	SequenceMaxAggregates int           `mapstructure:"sequence_max_aggregates"`
+}
+
+// InboxConfig configures the inbox of the event consumer, which records the processed event IDs
+// to skip redelivered events. Entries older than Retention are deleted every Interval, in
+// batches of BatchSize; a redelivery arriving after the retention is processed again.
+// Transaction groups still incomplete GroupTimeout after their first event are handed to
+// the group handler with the events received so far.
+type InboxConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Retention    time.Duration `mapstructure:"retention"`
+	Interval     time.Duration `mapstructure:"interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	GroupTimeout time.Duration `mapstructure:"group_timeout"`
+}
+
+const (
+	RebalanceStrategyRange      = "range"
+	RebalanceStrategyRoundRobin = "roundrobin"
+	RebalanceStrategySticky     = "sticky"
+)
+
+const (
+	SequencePolicyOff    = "off"
+	SequencePolicyFlag   = "flag"
+	SequencePolicyBuffer = "buffer"
+)
+
+// Priority lane names, matching the lanes of the outbox events
+const (
+	PriorityLaneHigh   = "high"
+	PriorityLaneNormal = "normal"
+	PriorityLaneLow    = "low"
+
+	defaultPriorityLane = PriorityLaneNormal
+)
+
+var priorityLanes = []string{PriorityLaneHigh, PriorityLaneNormal, PriorityLaneLow}
+
+const (
+	PartitionGranularityDay  = "day"
+	PartitionGranularityWeek = "week"
+)
+
+// LoggingConfig configures the structured logger. OutputPath is a file path, rotated when
+// it reaches MaxSizeMB, or empty, "stdout" or "stderr". MaxBackups and MaxAgeDays bound the
+// rotated files kept, zero keeps them all.
 type LoggingConfig struct {
 	Level      string `mapstructure:"level"`
 	Format     string `mapstructure:"format"`
 	OutputPath string `mapstructure:"output_path"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxBackups int    `mapstructure:"max_backups"`
+	MaxAgeDays int    `mapstructure:"max_age_days"`
+	Compress   bool   `mapstructure:"compress"`
 }
 
 // Load loads configuration from environment variables
@@ -155,14 +368,110 @@ func setDefaults() {
 	viper.SetDefault("worker.interval", "5s")
 	viper.SetDefault("worker.max_retries", 3)
 	viper.SetDefault("worker.retry_delay", "1s")
+	viper.SetDefault("worker.lease_duration", "1m")
+	viper.SetDefault("worker.shutdown_timeout", "30s")
+	viper.SetDefault("worker.default_event_ttl", "0s")
+	viper.SetDefault("worker.event_ttls", "")
+	viper.SetDefault("worker.event_priorities", "")
+	viper.SetDefault("worker.priority_weights", "high=6,normal=3,low=1")
+	viper.SetDefault("worker.topic_rate_limits", "")
+	viper.SetDefault("worker.event_type_rate_limits", "")
+	viper.SetDefault("worker.rate_limit_max_wait", "1s")
+	viper.SetDefault("worker.adaptive_enabled", false)
+	viper.SetDefault("worker.min_interval", "100ms")
+	viper.SetDefault("worker.max_interval", "30s")
+	viper.SetDefault("worker.min_batch_size", 10)
+	viper.SetDefault("worker.max_batch_size", 500)
+	viper.SetDefault("worker.target_batch_duration", "2s")
 
 	viper.SetDefault("metrics.enabled", true)
+	viper.SetDefault("metrics.host", "0.0.0.0")
 	viper.SetDefault("metrics.port", 9091)
 	viper.SetDefault("metrics.path", "/metrics")
+	viper.SetDefault("metrics.pprof_enabled", false)
+
+	viper.SetDefault("retention.enabled", false)
+	viper.SetDefault("retention.period", "168h")
+	viper.SetDefault("retention.interval", "1h")
+	viper.SetDefault("retention.batch_size", 1000)
+	viper.SetDefault("retention.max_batches_per_run", 100)
+	viper.SetDefault("retention.archive_enabled", false)
+	viper.SetDefault("retention.archive_dir", "./archive/outbox")
+
+	viper.SetDefault("partitioning.enabled", false)
+	viper.SetDefault("partitioning.granularity", PartitionGranularityDay)
+	viper.SetDefault("partitioning.premake", 7)
+	viper.SetDefault("partitioning.retention", "0s")
+	viper.SetDefault("partitioning.detach_only", false)
+	viper.SetDefault("partitioning.interval", "1h")
+
+	viper.SetDefault("admin.enabled", false)
+	viper.SetDefault("admin.host", "127.0.0.1")
+	viper.SetDefault("admin.port", 9094)
+	viper.SetDefault("admin.token", "")
+
+	viper.SetDefault("watchdog.enabled", true)
+	viper.SetDefault("watchdog.interval", "30s")
+	viper.SetDefault("watchdog.stuck_threshold", "5m")
+	viper.SetDefault("watchdog.escalate_stuck_after", "0s")
+	viper.SetDefault("watchdog.alert_limit", 10)
+
+	viper.SetDefault("replay.rate_limit", 100)
+	viper.SetDefault("replay.batch_size", 500)
+	viper.SetDefault("replay.checkpoint_every", 100)
+	viper.SetDefault("replay.checkpoint_interval", "5s")
+
+	viper.SetDefault("backfill.rate_limit", 50)
+	viper.SetDefault("backfill.page_size", 100)
+
+	viper.SetDefault("reconcile.enabled", false)
+	viper.SetDefault("reconcile.interval", "15m")
+	viper.SetDefault("reconcile.lookback", "24h")
+	viper.SetDefault("reconcile.grace", "5m")
+	viper.SetDefault("reconcile.repair", false)
+	viper.SetDefault("reconcile.verify_kafka", true)
+	viper.SetDefault("reconcile.verify_limit", 100)
+	viper.SetDefault("reconcile.report_limit", 100)
+
+	viper.SetDefault("slo.enabled", true)
+	viper.SetDefault("slo.objective", 0.99)
+	viper.SetDefault("slo.threshold", "2s")
+	viper.SetDefault("slo.window", "720h")
+	viper.SetDefault("slo.burn_rate_windows", "1h,6h")
+	viper.SetDefault("slo.interval", "1m")
+
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.exporter", TracingExporterOTLP)
+	viper.SetDefault("tracing.endpoint", "localhost:4318")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+
+	viper.SetDefault("consumer.max_retries", 3)
+	viper.SetDefault("consumer.retry_backoff", "500ms")
+	viper.SetDefault("consumer.max_retry_backoff", "10s")
+	viper.SetDefault("consumer.handler_timeout", "30s")
+	viper.SetDefault("consumer.dlq_enabled", true)
+	viper.SetDefault("consumer.dlq_topic", "txstream.events.dlq")
+	viper.SetDefault("consumer.rebalance_strategy", RebalanceStrategySticky)
+	viper.SetDefault("consumer.commit_interval", "1s")
+	viper.SetDefault("consumer.sequence_policy", SequencePolicyFlag)
+	viper.SetDefault("consumer.sequence_buffer_size", 1000)
+	viper.SetDefault("consumer.sequence_buffer_timeout", "30s")
+	viper.SetDefault("consumer.sequence_max_aggregates", 100000)
+
+	viper.SetDefault("inbox.enabled", true)
+	viper.SetDefault("inbox.retention", "168h")
+	viper.SetDefault("inbox.interval", "1h")
+	viper.SetDefault("inbox.batch_size", 1000)
+	viper.SetDefault("inbox.group_timeout", "5m")
 
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "json")
 	viper.SetDefault("logging.output_path", "")
+	viper.SetDefault("logging.max_size_mb", 100)
+	viper.SetDefault("logging.max_backups", 5)
+	viper.SetDefault("logging.max_age_days", 28)
+	viper.SetDefault("logging.compress", false)
 }
 
 // Validate validates the configuration
@@ -187,6 +496,54 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("logging config: %w", err)
 	}
 
+	if err := c.Retention.Validate(); err != nil {
+		return fmt.Errorf("retention config: %w", err)
+	}
+
+	if err := c.Partitioning.Validate(); err != nil {
+		return fmt.Errorf("partitioning config: %w", err)
+	}
+
+	if err := c.Watchdog.Validate(); err != nil {
+		return fmt.Errorf("watchdog config: %w", err)
+	}
+
+	if err := c.Metrics.Validate(); err != nil {
+		return fmt.Errorf("metrics config: %w", err)
+	}
+
+	if err := c.Admin.Validate(); err != nil {
+		return fmt.Errorf("admin config: %w", err)
+	}
+
+	if err := c.Replay.Validate(); err != nil {
+		return fmt.Errorf("replay config: %w", err)
+	}
+
+	if err := c.Backfill.Validate(); err != nil {
+		return fmt.Errorf("backfill config: %w", err)
+	}
+
+	if err := c.Reconcile.Validate(); err != nil {
+		return fmt.Errorf("reconcile config: %w", err)
+	}
+
+	if err := c.SLO.Validate(); err != nil {
+		return fmt.Errorf("slo config: %w", err)
+	}
+
+	if err := c.Tracing.Validate(); err != nil {
+		return fmt.Errorf("tracing config: %w", err)
+	}
+
+	if err := c.Consumer.Validate(); err != nil {
+		return fmt.Errorf("consumer config: %w", err)
+	}
+
+	if err := c.Inbox.Validate(); err != nil {
+		return fmt.Errorf("inbox config: %w", err)
+	}
+
 	return nil
 }
 
@@ -212,6 +569,9 @@ func (c *DatabaseConfig) Validate() error {
 	if c.Name == "" {
 		return fmt.Errorf("database name is required")
 	}
+	if c.SlowQueryTime < 0 {
+		return fmt.Errorf("database slow query time cannot be negative")
+	}
 	return nil
 }
 
@@ -243,6 +603,281 @@ func (c *WorkerConfig) Validate() error {
 	if c.MaxRetries < 0 {
 		return fmt.Errorf("max retries cannot be negative")
 	}
+	if c.LeaseDuration <= 0 {
+		return fmt.Errorf("lease duration must be positive")
+	}
+	if c.ShutdownTimeout < 0 {
+		return fmt.Errorf("shutdown timeout cannot be negative")
+	}
+	if c.DefaultEventTTL < 0 {
+		return fmt.Errorf("default event ttl cannot be negative")
+	}
+	if _, err := parseDurationMap(c.EventTTLs); err != nil {
+		return fmt.Errorf("invalid event ttls: %w", err)
+	}
+	if _, err := parsePriorityMap(c.EventPriorities); err != nil {
+		return fmt.Errorf("invalid event priorities: %w", err)
+	}
+	if _, err := parsePriorityWeights(c.PriorityWeights); err != nil {
+		return fmt.Errorf("invalid priority weights: %w", err)
+	}
+	if _, err := parseRateMap(c.TopicRateLimits); err != nil {
+		return fmt.Errorf("invalid topic rate limits: %w", err)
+	}
+	if _, err := parseRateMap(c.EventTypeRateLimits); err != nil {
+		return fmt.Errorf("invalid event type rate limits: %w", err)
+	}
+	if c.RateLimitMaxWait < 0 {
+		return fmt.Errorf("rate limit max wait cannot be negative")
+	}
+	if c.AdaptiveEnabled {
+		if c.MinInterval <= 0 || c.MaxInterval < c.MinInterval {
+			return fmt.Errorf("adaptive polling requires 0 < min interval <= max interval")
+		}
+		if c.MinBatchSize <= 0 || c.MaxBatchSize < c.MinBatchSize {
+			return fmt.Errorf("adaptive polling requires 0 < min batch size <= max batch size")
+		}
+		if c.TargetBatchDuration <= 0 {
+			return fmt.Errorf("adaptive polling requires a positive target batch duration")
+		}
+	}
+	return nil
+}
+
+// Validate validates retention configuration
+func (c *RetentionConfig) Validate() error {
+	if c.Period <= 0 {
+		return fmt.Errorf("retention period must be positive")
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("retention interval must be positive")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("retention batch size must be positive")
+	}
+	if c.MaxBatchesPerRun <= 0 {
+		return fmt.Errorf("retention max batches per run must be positive")
+	}
+	if c.ArchiveEnabled && c.ArchiveDir == "" {
+		return fmt.Errorf("archive dir is required when archiving is enabled")
+	}
+	return nil
+}
+
+// Validate validates partitioning configuration
+func (c *PartitioningConfig) Validate() error {
+	if c.Granularity != PartitionGranularityDay && c.Granularity != PartitionGranularityWeek {
+		return fmt.Errorf("invalid partition granularity: %s", c.Granularity)
+	}
+	if c.Premake <= 0 {
+		return fmt.Errorf("partition premake must be positive")
+	}
+	if c.Retention < 0 {
+		return fmt.Errorf("partition retention cannot be negative")
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("partition maintenance interval must be positive")
+	}
+	return nil
+}
+
+// Validate validates metrics configuration
+func (c *MetricsConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid metrics port: %d", c.Port)
+	}
+	if !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("metrics path must start with /: %q", c.Path)
+	}
+	return nil
+}
+
+// Validate validates admin configuration
+func (c *AdminConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid admin port: %d", c.Port)
+	}
+	if c.Token == "" {
+		return fmt.Errorf("admin token is required when the admin API is enabled")
+	}
+	return nil
+}
+
+// Validate validates watchdog configuration
+func (c *WatchdogConfig) Validate() error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("watchdog interval must be positive")
+	}
+	if c.StuckThreshold <= 0 {
+		return fmt.Errorf("stuck threshold must be positive")
+	}
+	if c.EscalateStuckAfter != 0 && c.EscalateStuckAfter < c.StuckThreshold {
+		return fmt.Errorf("escalate stuck after must not be shorter than the stuck threshold")
+	}
+	if c.AlertLimit < 0 {
+		return fmt.Errorf("alert limit cannot be negative")
+	}
+	return nil
+}
+
+// Validate validates replay configuration
+func (c *ReplayConfig) Validate() error {
+	if c.RateLimit < 0 {
+		return fmt.Errorf("replay rate limit cannot be negative")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("replay batch size must be positive")
+	}
+	if c.CheckpointEvery <= 0 {
+		return fmt.Errorf("checkpoint every must be positive")
+	}
+	if c.CheckpointInterval <= 0 {
+		return fmt.Errorf("checkpoint interval must be positive")
+	}
+	return nil
+}
+
+// Validate validates backfill configuration
+func (c *BackfillConfig) Validate() error {
+	if c.RateLimit < 0 {
+		return fmt.Errorf("backfill rate limit cannot be negative")
+	}
+	if c.PageSize <= 0 {
+		return fmt.Errorf("backfill page size must be positive")
+	}
+	return nil
+}
+
+// Validate validates reconcile configuration
+func (c *ReconcileConfig) Validate() error {
+	if c.Interval <= 0 {
+		return fmt.Errorf("reconcile interval must be positive")
+	}
+	if c.Lookback <= 0 {
+		return fmt.Errorf("reconcile lookback must be positive")
+	}
+	if c.Grace < 0 || c.Grace >= c.Lookback {
+		return fmt.Errorf("reconcile grace must be between zero and the lookback")
+	}
+	if c.VerifyLimit < 0 {
+		return fmt.Errorf("verify limit cannot be negative")
+	}
+	if c.ReportLimit <= 0 {
+		return fmt.Errorf("report limit must be positive")
+	}
+	return nil
+}
+
+// Validate validates SLO configuration
+func (c *SLOConfig) Validate() error {
+	if c.Objective <= 0 || c.Objective >= 1 {
+		return fmt.Errorf("slo objective must be between 0 and 1 exclusive")
+	}
+	if c.Threshold <= 0 {
+		return fmt.Errorf("slo threshold must be positive")
+	}
+	if c.Window <= 0 {
+		return fmt.Errorf("slo window must be positive")
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("slo interval must be positive")
+	}
+
+	windows, err := parseDurationList(c.BurnRateWindows)
+	if err != nil {
+		return fmt.Errorf("invalid burn rate windows: %w", err)
+	}
+	for _, window := range windows {
+		if window <= 0 || window > c.Window {
+			return fmt.Errorf("burn rate window %v must be positive and not longer than the slo window", window)
+		}
+	}
+	return nil
+}
+
+// GetBurnRateWindows returns the burn rate windows, ignoring an invalid list
+func (c *SLOConfig) GetBurnRateWindows() []time.Duration {
+	windows, err := parseDurationList(c.BurnRateWindows)
+	if err != nil {
+		return nil
+	}
+	return windows
+}
+
+// Validate validates tracing configuration
+func (c *TracingConfig) Validate() error {
+	if c.Exporter != TracingExporterOTLP && c.Exporter != TracingExporterStdout {
+		return fmt.Errorf("invalid tracing exporter: %s", c.Exporter)
+	}
+	if c.Exporter == TracingExporterOTLP && c.Endpoint == "" {
+		return fmt.Errorf("tracing endpoint is required for the otlp exporter")
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample ratio must be between 0 and 1")
+	}
+	return nil
+}
+
+// Validate validates consumer configuration
+func (c *ConsumerConfig) Validate() error {
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("consumer max retries cannot be negative")
+	}
+	if c.RetryBackoff <= 0 || c.MaxRetryBackoff < c.RetryBackoff {
+		return fmt.Errorf("consumer retry backoff must be positive and not above the max retry backoff")
+	}
+	if c.HandlerTimeout <= 0 {
+		return fmt.Errorf("consumer handler timeout must be positive")
+	}
+	if c.DLQEnabled && c.DLQTopic == "" {
+		return fmt.Errorf("consumer DLQ topic is required when the DLQ is enabled")
+	}
+	switch c.RebalanceStrategy {
+	case RebalanceStrategyRange, RebalanceStrategyRoundRobin, RebalanceStrategySticky:
+	default:
+		return fmt.Errorf("invalid consumer rebalance strategy: %s", c.RebalanceStrategy)
+	}
+	if c.CommitInterval <= 0 {
+		return fmt.Errorf("consumer commit interval must be positive")
+	}
+	switch c.SequencePolicy {
+	case SequencePolicyOff:
+	case SequencePolicyFlag, SequencePolicyBuffer:
+		if c.SequenceMaxAggregates <= 0 {
+			return fmt.Errorf("consumer sequence max aggregates must be positive")
+		}
+	default:
+		return fmt.Errorf("invalid consumer sequence policy: %s", c.SequencePolicy)
+	}
+	if c.SequencePolicy == SequencePolicyBuffer && (c.SequenceBufferSize <= 0 || c.SequenceBufferTimeout <= 0) {
+		return fmt.Errorf("consumer sequence buffer size and timeout must be positive")
+	}
+	return nil
+}
+
+// Validate validates inbox configuration
+func (c *InboxConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Retention <= 0 {
+		return fmt.Errorf("inbox retention must be positive")
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("inbox cleanup interval must be positive")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("inbox cleanup batch size must be positive")
+	}
+	if c.GroupTimeout <= 0 || c.GroupTimeout >= c.Retention {
+		return fmt.Errorf("inbox group timeout must be positive and shorter than the retention")
+	}
 	return nil
 }
 
@@ -262,6 +897,13 @@ func (c *LoggingConfig) Validate() error {
 		return fmt.Errorf("invalid log format: %s", c.Format)
 	}
 
+	if c.MaxSizeMB <= 0 {
+		return fmt.Errorf("log max size must be positive")
+	}
+	if c.MaxBackups < 0 || c.MaxAgeDays < 0 {
+		return fmt.Errorf("log max backups and max age must not be negative")
+	}
+
 	return nil
 }
 
@@ -271,6 +913,11 @@ func (c *DatabaseConfig) GetDSN() string {
 		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode)
 }
 
+// GetSlowQueryThreshold returns the duration above which a query is logged as slow
+func (c *DatabaseConfig) GetSlowQueryThreshold() time.Duration {
+	return time.Duration(c.SlowQueryTime) * time.Millisecond
+}
+
 // GetKafkaBrokers returns Kafka brokers as string slice
 func (c *KafkaConfig) GetKafkaBrokers() []string {
 	return c.Brokers
@@ -280,3 +927,193 @@ func (c *KafkaConfig) GetKafkaBrokers() []string {
 func (c *KafkaConfig) IsKafkaEnabled() bool {
 	return len(c.Brokers) > 0 && c.Brokers[0] != ""
 }
+
+// GetEventTTLs returns the TTL configured for each event type
+func (c *WorkerConfig) GetEventTTLs() map[string]time.Duration {
+	ttls, err := parseDurationMap(c.EventTTLs)
+	if err != nil {
+		return map[string]time.Duration{}
+	}
+	return ttls
+}
+
+// GetEventTTL returns the TTL for an event type, falling back to the default TTL
+func (c *WorkerConfig) GetEventTTL(eventType string) time.Duration {
+	if ttl, ok := c.GetEventTTLs()[eventType]; ok {
+		return ttl
+	}
+	return c.DefaultEventTTL
+}
+
+// GetEventPriority returns the priority lane name of an event type, defaulting to the normal lane
+func (c *WorkerConfig) GetEventPriority(eventType string) string {
+	priorities, err := parsePriorityMap(c.EventPriorities)
+	if err != nil {
+		return defaultPriorityLane
+	}
+	if priority, ok := priorities[eventType]; ok {
+		return priority
+	}
+	return defaultPriorityLane
+}
+
+// GetPriorityWeights returns the scheduling weight of every priority lane by lane name
+func (c *WorkerConfig) GetPriorityWeights() map[string]int {
+	weights, err := parsePriorityWeights(c.PriorityWeights)
+	if err != nil {
+		weights = make(map[string]int)
+	}
+	for _, lane := range priorityLanes {
+		if _, ok := weights[lane]; !ok {
+			weights[lane] = 1
+		}
+	}
+	return weights
+}
+
+// GetTopicRateLimits returns the publish rate limit of each topic in events per second
+func (c *WorkerConfig) GetTopicRateLimits() map[string]float64 {
+	limits, err := parseRateMap(c.TopicRateLimits)
+	if err != nil {
+		return map[string]float64{}
+	}
+	return limits
+}
+
+// GetEventTypeRateLimits returns the publish rate limit of each event type in events per second
+func (c *WorkerConfig) GetEventTypeRateLimits() map[string]float64 {
+	limits, err := parseRateMap(c.EventTypeRateLimits)
+	if err != nil {
+		return map[string]float64{}
+	}
+	return limits
+}
+
+// parsePriorityMap parses a list in the format "EventType=high,OtherEventType=low"
+func parsePriorityMap(raw string) (map[string]string, error) {
+	priorities, err := parseKeyValueList(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range priorities {
+		if !isPriorityLane(value) {
+			return nil, fmt.Errorf("invalid priority for %s: invalid priority lane: %s", key, value)
+		}
+	}
+	return priorities, nil
+}
+
+// parsePriorityWeights parses a list in the format "high=6,normal=3,low=1"
+func parsePriorityWeights(raw string) (map[string]int, error) {
+	values, err := parseKeyValueList(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make(map[string]int, len(values))
+	for key, value := range values {
+		if !isPriorityLane(key) {
+			return nil, fmt.Errorf("invalid priority lane: %s", key)
+		}
+		weight, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight for %s: %w", key, err)
+		}
+		if weight <= 0 {
+			return nil, fmt.Errorf("weight for %s must be positive", key)
+		}
+		weights[key] = weight
+	}
+	return weights, nil
+}
+
+// isPriorityLane checks if a name is one of the priority lanes
+func isPriorityLane(name string) bool {
+	for _, lane := range priorityLanes {
+		if lane == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRateMap parses a list in the format "key1=100,key2=0.5"
+func parseRateMap(raw string) (map[string]float64, error) {
+	values, err := parseKeyValueList(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(map[string]float64, len(values))
+	for key, value := range values {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate for %s: %w", key, err)
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("rate for %s must be positive", key)
+		}
+		rates[key] = rate
+	}
+	return rates, nil
+}
+
+// parseKeyValueList parses a list in the format "key1=value1,key2=value2"
+func parseKeyValueList(raw string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if !found || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected key=value", pair)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// parseDurationMap parses a list in the format "key1=1m,key2=30s"
+func parseDurationMap(raw string) (map[string]time.Duration, error) {
+	values, err := parseKeyValueList(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	durations := make(map[string]time.Duration, len(values))
+	for key, value := range values {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration for %s: %w", key, err)
+		}
+		if duration < 0 {
+			return nil, fmt.Errorf("duration for %s cannot be negative", key)
+		}
+		durations[key] = duration
+	}
+	return durations, nil
+}
+
+// parseDurationList parses a list in the format "1h,6h"
+func parseDurationList(raw string) ([]time.Duration, error) {
+	var durations []time.Duration
+	for _, value := range strings.Split(raw, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q: %w", value, err)
+		}
+		durations = append(durations, duration)
+	}
+	return durations, nil
+}
@@ -0,0 +1,579 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/correlation"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/replay"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/tracing"
+)
+
+// Outcomes of a consumed message
+const (
+	OutcomeHandled      = "handled"
+	OutcomeSkipped      = "skipped"
+	OutcomeDeadLettered = "dead_lettered"
+	OutcomeDiscarded    = "discarded"
+	// OutcomeDuplicate is an event skipped by the buffer sequence policy, its aggregate
+	// version was already consumed
+	OutcomeDuplicate = "duplicate"
+)
+
+// Headers added to the messages published to the DLQ, next to the original headers
+const (
+	DLQErrorHeader             = "dlq_error"
+	DLQAttemptsHeader          = "dlq_attempts"
+	DLQOriginalTopicHeader     = "dlq_original_topic"
+	DLQOriginalPartitionHeader = "dlq_original_partition"
+	DLQOriginalOffsetHeader    = "dlq_original_offset"
+	DLQConsumerGroupHeader     = "dlq_consumer_group"
+	DLQFailedAtHeader          = "dlq_failed_at"
+)
+
+// errInterrupted is returned when the session ends while an event waits for a retry, the
+// event is left unmarked so the next owner of the partition redelivers it
+var errInterrupted = errors.New("retry interrupted by the end of the session")
+
+// Consumer runs the handlers of a Registry on the messages of the events topic, as a member
+// of the configured consumer group. A message is marked, and its offset committed, only once
+// it was handled, skipped because no handler is registered, or sent to the DLQ, so delivery
+// is at least once and handlers must be idempotent.
+//
+// On a rebalance the message being handled is finished before the partition is released,
+// and the marked offsets are committed in Cleanup.
+//
+// With a sequence policy the aggregate version of each event is checked against the versions
+// consumed from the partition, see config.ConsumerConfig. The committed offset never moves
+// past an event held by the buffer policy, so a held event is redelivered if the partition is
+// released before the event is handled.
+type Consumer struct {
+	kafkaConfig *config.KafkaConfig
+	config      config.ConsumerConfig
+	registry    *Registry
+	group       sarama.ConsumerGroup
+	dlqProducer sarama.SyncProducer
+	metrics     *metrics.Metrics
+	logger      *slog.Logger
+}
+
+// NewConsumer connects the consumer group and, when the DLQ is enabled, its producer. A nil
+// metrics records nothing.
+func NewConsumer(kafkaCfg *config.KafkaConfig, cfg config.ConsumerConfig, registry *Registry, metrics *metrics.Metrics, logger *slog.Logger) (*Consumer, error) {
+	saramaConfig, err := newSaramaConfig(kafkaCfg, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := sarama.NewConsumerGroup(kafkaCfg.GetKafkaBrokers(), kafkaCfg.GroupID, saramaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	var dlqProducer sarama.SyncProducer
+	if cfg.DLQEnabled {
+		dlqConfig := sarama.NewConfig()
+		dlqConfig.Producer.RequiredAcks = sarama.WaitForAll
+		dlqConfig.Producer.Return.Successes = true
+		dlqConfig.Producer.Retry.Max = kafkaCfg.MaxRetries
+		dlqConfig.Producer.Retry.Backoff = kafkaCfg.RetryDelay
+
+		if dlqProducer, err = sarama.NewSyncProducer(kafkaCfg.GetKafkaBrokers(), dlqConfig); err != nil {
+			group.Close()
+			return nil, fmt.Errorf("failed to create DLQ producer: %w", err)
+		}
+	}
+
+	return NewConsumerWithClients(kafkaCfg, cfg, registry, group, dlqProducer, metrics, logger), nil
+}
+
+// NewConsumerWithClients creates a consumer on the given consumer group and DLQ producer,
+// used by tests with sarama mocks. A nil dlqProducer discards the failed events.
+func NewConsumerWithClients(kafkaCfg *config.KafkaConfig, cfg config.ConsumerConfig, registry *Registry, group sarama.ConsumerGroup, dlqProducer sarama.SyncProducer, metrics *metrics.Metrics, logger *slog.Logger) *Consumer {
+	return &Consumer{
+		kafkaConfig: kafkaCfg,
+		config:      cfg,
+		registry:    registry,
+		group:       group,
+		dlqProducer: dlqProducer,
+		metrics:     metrics,
+		logger:      logging.OrDefault(logger),
+	}
+}
+
+// newSaramaConfig creates the consumer group configuration
+func newSaramaConfig(kafkaCfg *config.KafkaConfig, cfg config.ConsumerConfig) (*sarama.Config, error) {
+	saramaConfig := sarama.NewConfig()
+
+	switch kafkaCfg.AutoOffsetReset {
+	case "earliest", "":
+		saramaConfig.Consumer.Offsets.Initial = sarama.OffsetOldest
+	case "latest":
+		saramaConfig.Consumer.Offsets.Initial = sarama.OffsetNewest
+	default:
+		return nil, fmt.Errorf("invalid auto offset reset: %s", kafkaCfg.AutoOffsetReset)
+	}
+
+	switch cfg.RebalanceStrategy {
+	case config.RebalanceStrategyRange:
+		saramaConfig.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategyRange()}
+	case config.RebalanceStrategyRoundRobin:
+		saramaConfig.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategyRoundRobin()}
+	default:
+		saramaConfig.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.NewBalanceStrategySticky()}
+	}
+
+	if kafkaCfg.SessionTimeout > 0 {
+		saramaConfig.Consumer.Group.Session.Timeout = kafkaCfg.SessionTimeout
+		saramaConfig.Consumer.Group.Heartbeat.Interval = kafkaCfg.SessionTimeout / 10
+	}
+	// a revoked partition is released once the message being handled is finished
+	if rebalanceTimeout := cfg.HandlerTimeout + 10*time.Second; rebalanceTimeout > saramaConfig.Consumer.Group.Rebalance.Timeout {
+		saramaConfig.Consumer.Group.Rebalance.Timeout = rebalanceTimeout
+	}
+
+	saramaConfig.Consumer.Offsets.AutoCommit.Enable = true
+	saramaConfig.Consumer.Offsets.AutoCommit.Interval = cfg.CommitInterval
+	saramaConfig.Consumer.Return.Errors = true
+
+	return saramaConfig, nil
+}
+
+// Run consumes the events topic until ctx is cancelled, joining the group again after each
+// rebalance or session failure
+func (c *Consumer) Run(ctx context.Context) error {
+	go c.logErrors()
+
+	topics := []string{c.kafkaConfig.TopicEvents}
+	c.logger.InfoContext(ctx, "Starting event consumer",
+		"group_id", c.kafkaConfig.GroupID,
+		"topics", topics,
+		"event_types", c.registry.EventTypes(),
+		"dlq_enabled", c.config.DLQEnabled)
+
+	for {
+		if err := c.group.Consume(ctx, topics, c); err != nil {
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				return nil
+			}
+			c.logger.ErrorContext(ctx, "Consumer group session failed", "error", err)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(c.config.RetryBackoff):
+			}
+		}
+
+		if ctx.Err() != nil {
+			c.logger.InfoContext(ctx, "Event consumer stopped")
+			return nil
+		}
+	}
+}
+
+// logErrors logs the errors of the consumer group until it is closed
+func (c *Consumer) logErrors() {
+	for err := range c.group.Errors() {
+		c.logger.Error("Consumer group error", "error", err)
+	}
+}
+
+// Close leaves the consumer group, committing the marked offsets, and closes the DLQ producer
+func (c *Consumer) Close() error {
+	var errs []error
+	if err := c.group.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close consumer group: %w", err))
+	}
+	if c.dlqProducer != nil {
+		if err := c.dlqProducer.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close DLQ producer: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Setup is called when the partitions of a new session are assigned
+func (c *Consumer) Setup(session sarama.ConsumerGroupSession) error {
+	c.logger.Info("Partitions assigned",
+		"member_id", session.MemberID(),
+		"generation_id", session.GenerationID(),
+		"claims", session.Claims())
+	return nil
+}
+
+// Cleanup is called when the session ends, after every ConsumeClaim returned
+func (c *Consumer) Cleanup(session sarama.ConsumerGroupSession) error {
+	session.Commit()
+	c.logger.Info("Partitions released",
+		"member_id", session.MemberID(),
+		"generation_id", session.GenerationID())
+	return nil
+}
+
+// claimState is the sequence state of a claimed partition, the tracker and buffer are nil
+// when the sequence policy does not use them
+type claimState struct {
+	topic       string
+	partition   int32
+	tracker     *SequenceTracker
+	buffer      *sequenceBuffer
+	lastMessage *sarama.ConsumerMessage
+}
+
+// newClaimState creates the sequence state of a claimed partition
+func (c *Consumer) newClaimState(claim sarama.ConsumerGroupClaim) *claimState {
+	state := &claimState{topic: claim.Topic(), partition: claim.Partition()}
+	switch c.config.SequencePolicy {
+	case config.SequencePolicyFlag:
+		state.tracker = NewSequenceTracker(c.config.SequenceMaxAggregates)
+	case config.SequencePolicyBuffer:
+		state.tracker = NewSequenceTracker(c.config.SequenceMaxAggregates)
+		state.buffer = newSequenceBuffer()
+	}
+	return state
+}
+
+// ConsumeClaim handles the messages of a partition until the session ends
+func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	state := c.newClaimState(claim)
+
+	var expired <-chan time.Time
+	if state.buffer != nil {
+		ticker := time.NewTicker(c.config.SequenceBufferTimeout / 2)
+		defer ticker.Stop()
+		expired = ticker.C
+	}
+
+	for {
+		select {
+		case <-session.Context().Done():
+			return nil
+		case <-expired:
+			if err := c.releaseExpired(session.Context(), state); err != nil {
+				return sessionError(err)
+			}
+			c.mark(session, claim, state)
+		case message, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+
+			if err := c.consume(session.Context(), message, state); err != nil {
+				return sessionError(err)
+			}
+			state.lastMessage = message
+			c.mark(session, claim, state)
+		}
+	}
+}
+
+// sessionError returns the error ending ConsumeClaim, an interrupted retry ends it without error
+func sessionError(err error) error {
+	if errors.Is(err, errInterrupted) {
+		return nil
+	}
+	return err
+}
+
+// mark marks the last consumed message, or the lowest held offset when the buffer holds
+// events consumed before it
+func (c *Consumer) mark(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim, state *claimState) {
+	if state.lastMessage == nil {
+		return
+	}
+
+	buffered := 0
+	if state.buffer != nil {
+		buffered = state.buffer.size
+	}
+	if lowest, ok := c.lowestBufferedOffset(state); ok && lowest <= state.lastMessage.Offset {
+		session.MarkOffset(state.topic, state.partition, lowest, "")
+	} else {
+		session.MarkMessage(state.lastMessage, "")
+	}
+
+	if c.metrics != nil {
+		c.metrics.SetConsumerLag(claim.Topic(), claim.Partition(), claim.HighWaterMarkOffset()-state.lastMessage.Offset-1)
+		if state.buffer != nil {
+			c.metrics.SetConsumerSequenceBuffered(state.topic, state.partition, buffered)
+		}
+	}
+}
+
+func (c *Consumer) lowestBufferedOffset(state *claimState) (int64, bool) {
+	if state.buffer == nil {
+		return 0, false
+	}
+	return state.buffer.lowestOffset()
+}
+
+// consume decodes a message and handles its event, checking its aggregate version when a
+// sequence policy is set. Replayed events are republished on purpose and are not checked.
+// An error means the message must not be marked.
+func (c *Consumer) consume(sessionCtx context.Context, message *sarama.ConsumerMessage, state *claimState) error {
+	start := time.Now()
+
+	event, err := DecodeMessage(message)
+	if err != nil {
+		return c.deadLetter(sessionCtx, message, MessageHeaders(message)[EventTypeHeader], 0, err, start)
+	}
+
+	if state.tracker == nil || event.Headers[replay.HeaderReplay] == "true" {
+		return c.process(sessionCtx, message, event, start)
+	}
+
+	event.Sequence = state.tracker.Check(event.AggregateID, event.AggregateVersion)
+	if state.buffer != nil {
+		switch event.Sequence {
+		case SequenceDuplicate:
+			c.logger.InfoContext(sessionCtx, "Duplicate event skipped",
+				logging.EventIDKey, event.ID, logging.AggregateIDKey, event.AggregateID,
+				"aggregate_version", event.AggregateVersion, "offset", message.Offset)
+			c.recordSequence(event)
+			c.record(message.Topic, event.EventType, OutcomeDuplicate, start)
+			return nil
+		case SequenceGap:
+			c.logger.InfoContext(sessionCtx, "Event held until the missing versions arrive",
+				logging.EventIDKey, event.ID, logging.AggregateIDKey, event.AggregateID,
+				"aggregate_version", event.AggregateVersion, "last_version", state.tracker.Last(event.AggregateID),
+				"offset", message.Offset)
+			state.buffer.add(&bufferedEvent{message: message, event: event, start: start, bufferedAt: time.Now()})
+			if state.buffer.size > c.config.SequenceBufferSize {
+				aggregateID, _, _ := state.buffer.oldest()
+				return c.releaseGap(sessionCtx, state, aggregateID)
+			}
+			return nil
+		}
+	}
+
+	return c.processInSequence(sessionCtx, message, event, start, state)
+}
+
+// processInSequence handles an event and records its version, then handles the events
+// held for its aggregate that are now in order
+func (c *Consumer) processInSequence(sessionCtx context.Context, message *sarama.ConsumerMessage, event *Event, start time.Time, state *claimState) error {
+	if event.Sequence != SequenceInOrder {
+		c.logger.WarnContext(sessionCtx, "Event out of sequence",
+			logging.EventIDKey, event.ID, logging.AggregateIDKey, event.AggregateID,
+			"sequence", event.Sequence, "aggregate_version", event.AggregateVersion,
+			"last_version", state.tracker.Last(event.AggregateID), "offset", message.Offset)
+		c.recordSequence(event)
+	}
+
+	if err := c.process(sessionCtx, message, event, start); err != nil {
+		return err
+	}
+	state.tracker.Record(event.AggregateID, event.AggregateVersion)
+
+	if state.buffer == nil {
+		return nil
+	}
+	for {
+		buffered, ok := state.buffer.next(event.AggregateID)
+		if !ok {
+			return nil
+		}
+
+		sequence := state.tracker.Check(event.AggregateID, buffered.event.AggregateVersion)
+		if sequence == SequenceGap {
+			return nil
+		}
+		state.buffer.pop(event.AggregateID)
+
+		buffered.event.Sequence = sequence
+		if sequence == SequenceDuplicate {
+			c.recordSequence(buffered.event)
+			c.record(buffered.message.Topic, buffered.event.EventType, OutcomeDuplicate, buffered.start)
+			continue
+		}
+		if err := c.process(sessionCtx, buffered.message, buffered.event, buffered.start); err != nil {
+			return err
+		}
+		state.tracker.Record(event.AggregateID, buffered.event.AggregateVersion)
+	}
+}
+
+// releaseExpired handles the events held longer than the buffer timeout, accepting the gap
+// before them
+func (c *Consumer) releaseExpired(sessionCtx context.Context, state *claimState) error {
+	for {
+		aggregateID, bufferedAt, ok := state.buffer.oldest()
+		if !ok || time.Since(bufferedAt) < c.config.SequenceBufferTimeout {
+			return nil
+		}
+		if err := c.releaseGap(sessionCtx, state, aggregateID); err != nil {
+			return err
+		}
+	}
+}
+
+// releaseGap handles the lowest version held for the aggregate despite the missing versions
+// before it, then the held versions following it
+func (c *Consumer) releaseGap(sessionCtx context.Context, state *claimState, aggregateID string) error {
+	buffered, ok := state.buffer.next(aggregateID)
+	if !ok {
+		return nil
+	}
+	state.buffer.pop(aggregateID)
+
+	buffered.event.Sequence = SequenceGap
+	return c.processInSequence(sessionCtx, buffered.message, buffered.event, buffered.start, state)
+}
+
+// eventCorrelation returns the correlation of the events written while handling event: they
+// share its correlation ID, or its ID when it has none, and are caused by it
+func eventCorrelation(event *Event) correlation.IDs {
+	ids := correlation.IDs{CorrelationID: event.CorrelationID, CausationID: event.ID}
+	if ids.CorrelationID == "" {
+		ids.CorrelationID = event.ID
+	}
+	return ids
+}
+
+// process handles an event, retrying the handler and sending the event to the DLQ when it
+// keeps failing. An error means the message must not be marked.
+func (c *Consumer) process(sessionCtx context.Context, message *sarama.ConsumerMessage, event *Event, start time.Time) error {
+	// the handler keeps running when the session ends, so a rebalance never interrupts it midway
+	ids := eventCorrelation(event)
+	ctx := logging.WithAttrs(correlation.WithIDs(context.WithoutCancel(sessionCtx), ids),
+		slog.String(logging.EventIDKey, event.ID),
+		slog.String(logging.EventTypeKey, event.EventType),
+		slog.String(logging.AggregateIDKey, event.AggregateID),
+		slog.String(logging.CorrelationIDKey, ids.CorrelationID),
+	)
+	ctx, span := tracing.StartConsumeSpan(ctx, message.Topic, event.Headers, event.ID, event.EventType)
+	defer span.End()
+
+	handler, ok := c.registry.Handler(event.EventType)
+	if !ok {
+		c.logger.DebugContext(ctx, "No handler registered for event type, skipping event")
+		c.record(message.Topic, event.EventType, OutcomeSkipped, start)
+		return nil
+	}
+
+	var handleErr error
+	for event.Attempt = 1; ; event.Attempt++ {
+		handleErr = c.handle(ctx, handler, event)
+		if handleErr == nil {
+			c.logger.DebugContext(ctx, "Event handled", "attempt", event.Attempt, "offset", message.Offset)
+			c.record(message.Topic, event.EventType, OutcomeHandled, start)
+			return nil
+		}
+		if IsPermanent(handleErr) || event.Attempt > c.config.MaxRetries {
+			break
+		}
+
+		delay := c.retryDelay(event.Attempt)
+		c.logger.WarnContext(ctx, "Event handler failed, retrying",
+			"attempt", event.Attempt, "max_attempts", c.config.MaxRetries+1, "delay", delay, "error", handleErr)
+		if c.metrics != nil {
+			c.metrics.RecordConsumerRetry(message.Topic, event.EventType)
+		}
+
+		select {
+		case <-sessionCtx.Done():
+			c.logger.InfoContext(ctx, "Session ended before the retry, the event will be redelivered")
+			return errInterrupted
+		case <-time.After(delay):
+		}
+	}
+
+	span.RecordError(handleErr)
+	span.SetStatus(codes.Error, handleErr.Error())
+	return c.deadLetter(ctx, message, event.EventType, event.Attempt, handleErr, start)
+}
+
+// handle runs a single handler attempt, bounded by the handler timeout. A panic of the
+// handler is returned as an error.
+func (c *Consumer) handle(ctx context.Context, handler Handler, event *Event) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.HandlerTimeout)
+	defer cancel()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("handler panicked: %v", recovered)
+		}
+	}()
+
+	return handler.Handle(ctx, event)
+}
+
+// deadLetter publishes a message that could not be handled to the DLQ, or discards it when
+// the DLQ is disabled. An error means the DLQ is unavailable and the message must be redelivered.
+func (c *Consumer) deadLetter(ctx context.Context, message *sarama.ConsumerMessage, eventType string, attempts int, cause error, start time.Time) error {
+	if c.dlqProducer == nil {
+		c.logger.ErrorContext(ctx, "Event discarded after failed handling",
+			"topic", message.Topic, "partition", message.Partition, "offset", message.Offset,
+			"attempts", attempts, "error", cause)
+		c.record(message.Topic, eventType, OutcomeDiscarded, start)
+		return nil
+	}
+
+	headers := make([]sarama.RecordHeader, 0, len(message.Headers)+7)
+	for _, header := range message.Headers {
+		if header != nil {
+			headers = append(headers, *header)
+		}
+	}
+	for key, value := range map[string]string{
+		DLQErrorHeader:             cause.Error(),
+		DLQAttemptsHeader:          strconv.Itoa(attempts),
+		DLQOriginalTopicHeader:     message.Topic,
+		DLQOriginalPartitionHeader: strconv.Itoa(int(message.Partition)),
+		DLQOriginalOffsetHeader:    strconv.FormatInt(message.Offset, 10),
+		DLQConsumerGroupHeader:     c.kafkaConfig.GroupID,
+		DLQFailedAtHeader:          time.Now().UTC().Format(time.RFC3339),
+	} {
+		headers = append(headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+
+	_, _, err := c.dlqProducer.SendMessage(&sarama.ProducerMessage{
+		Topic:   c.config.DLQTopic,
+		Key:     sarama.ByteEncoder(message.Key),
+		Value:   sarama.ByteEncoder(message.Value),
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish event to the DLQ %s: %w", c.config.DLQTopic, err)
+	}
+
+	c.logger.ErrorContext(ctx, "Event sent to the DLQ",
+		"dlq_topic", c.config.DLQTopic, "partition", message.Partition, "offset", message.Offset,
+		"attempts", attempts, "error", cause)
+	c.record(message.Topic, eventType, OutcomeDeadLettered, start)
+	return nil
+}
+
+// retryDelay returns the exponential backoff before the retry following attempt
+func (c *Consumer) retryDelay(attempt int) time.Duration {
+	delay := c.config.RetryBackoff
+	for i := 1; i < attempt && delay < c.config.MaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > c.config.MaxRetryBackoff {
+		delay = c.config.MaxRetryBackoff
+	}
+	return delay
+}
+
+func (c *Consumer) record(topic, eventType, outcome string, start time.Time) {
+	if c.metrics != nil {
+		c.metrics.RecordConsumerMessage(topic, eventType, outcome, time.Since(start))
+	}
+}
+
+func (c *Consumer) recordSequence(event *Event) {
+	if c.metrics != nil {
+		c.metrics.RecordConsumerSequence(event.Topic, event.EventType, event.Sequence)
+	}
+}
@@ -0,0 +1,155 @@
+package consumer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// Headers set by the producer on every message
+const (
+	EventTypeHeader        = "event_type"
+	AggregateTypeHeader    = "aggregate_type"
+	EventIDHeader          = "event_id"
+	AggregateVersionHeader = "aggregate_version"
+	CorrelationIDHeader    = "correlation_id"
+	CausationIDHeader      = "causation_id"
+	TransactionIDHeader    = "transaction_id"
+	// TransactionEventCountHeader is the number of events written by the transaction
+	TransactionEventCountHeader = "transaction_event_count"
+)
+
+// ErrPayloadTruncated is returned for events whose payload was truncated by the producer
+// because it exceeded the maximum message size
+var ErrPayloadTruncated = errors.New("event payload was truncated by the producer")
+
+// Event is an outbox event decoded from a consumed message
+type Event struct {
+	ID            string
+	AggregateID   string
+	AggregateType string
+	// AggregateVersion is the position of the event in the sequence of its aggregate, zero
+	// for unversioned events
+	AggregateVersion int64
+	// CorrelationID and CausationID link the event to the original request and to the
+	// request or event that caused it, empty for the events written before correlation
+	CorrelationID string
+	CausationID   string
+	// TransactionID identifies the database transaction that wrote the event, and
+	// TransactionEventCount is the number of events it wrote; zero for ungrouped events
+	TransactionID         string
+	TransactionEventCount int
+	EventType             string
+	Data                  json.RawMessage
+	Metadata              map[string]interface{}
+	CreatedAt             time.Time
+
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       string
+	Headers   map[string]string
+
+	// Attempt is the handler attempt delivering the event, starting at 1
+	Attempt int
+	// Sequence is the sequence status of the event when the consumer checks the aggregate versions
+	Sequence string
+}
+
+// payload is the message value written by the producer
+type payload struct {
+	EventID               string                 `json:"event_id"`
+	AggregateID           string                 `json:"aggregate_id"`
+	AggregateType         string                 `json:"aggregate_type"`
+	AggregateVersion      int64                  `json:"aggregate_version"`
+	CorrelationID         string                 `json:"correlation_id"`
+	CausationID           string                 `json:"causation_id"`
+	TransactionID         string                 `json:"transaction_id"`
+	TransactionEventCount int                    `json:"transaction_event_count"`
+	EventType             string                 `json:"event_type"`
+	EventData             json.RawMessage        `json:"event_data"`
+	EventMetadata         map[string]interface{} `json:"event_metadata"`
+	CreatedAt             time.Time              `json:"created_at"`
+	Error                 string                 `json:"error"`
+}
+
+// DecodeMessage decodes the producer payload of message. The headers take precedence
+// over the payload for the event type, ID, aggregate version, correlation and transaction.
+func DecodeMessage(message *sarama.ConsumerMessage) (*Event, error) {
+	headers := MessageHeaders(message)
+
+	var value payload
+	if err := json.Unmarshal(message.Value, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode event payload: %w", err)
+	}
+	if value.Error != "" {
+		return nil, fmt.Errorf("%w: %s", ErrPayloadTruncated, value.Error)
+	}
+
+	event := &Event{
+		ID:                    value.EventID,
+		AggregateID:           value.AggregateID,
+		AggregateType:         value.AggregateType,
+		AggregateVersion:      value.AggregateVersion,
+		CorrelationID:         value.CorrelationID,
+		CausationID:           value.CausationID,
+		TransactionID:         value.TransactionID,
+		TransactionEventCount: value.TransactionEventCount,
+		EventType:             value.EventType,
+		Data:                  value.EventData,
+		Metadata:              value.EventMetadata,
+		CreatedAt:             value.CreatedAt,
+		Topic:                 message.Topic,
+		Partition:             message.Partition,
+		Offset:                message.Offset,
+		Key:                   string(message.Key),
+		Headers:               headers,
+	}
+	if eventType := headers[EventTypeHeader]; eventType != "" {
+		event.EventType = eventType
+	}
+	if eventID := headers[EventIDHeader]; eventID != "" {
+		event.ID = eventID
+	}
+	if correlationID := headers[CorrelationIDHeader]; correlationID != "" {
+		event.CorrelationID = correlationID
+	}
+	if causationID := headers[CausationIDHeader]; causationID != "" {
+		event.CausationID = causationID
+	}
+	if transactionID := headers[TransactionIDHeader]; transactionID != "" {
+		event.TransactionID = transactionID
+	}
+	if count, err := strconv.Atoi(headers[TransactionEventCountHeader]); err == nil {
+		event.TransactionEventCount = count
+	}
+	if version, err := strconv.ParseInt(headers[AggregateVersionHeader], 10, 64); err == nil {
+		event.AggregateVersion = version
+	}
+
+	return event, nil
+}
+
+// MessageHeaders returns the headers of message as a map
+func MessageHeaders(message *sarama.ConsumerMessage) map[string]string {
+	headers := make(map[string]string, len(message.Headers))
+	for _, header := range message.Headers {
+		if header != nil {
+			headers[string(header.Key)] = string(header.Value)
+		}
+	}
+	return headers
+}
+
+// Decode decodes the event data of event into a T
+func Decode[T any](event *Event) (T, error) {
+	var data T
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return data, fmt.Errorf("failed to decode %s event data: %w", event.EventType, err)
+	}
+	return data, nil
+}
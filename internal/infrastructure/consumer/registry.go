@@ -0,0 +1,112 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrHandlerAlreadyRegistered is returned when an event type already has a handler
+var ErrHandlerAlreadyRegistered = errors.New("handler already registered for event type")
+
+// Handler handles the events of a type. Returning an error retries the event, unless the
+// error is wrapped with Permanent.
+type Handler interface {
+	Handle(ctx context.Context, event *Event) error
+}
+
+// HandlerFunc adapts a function to a Handler
+type HandlerFunc func(ctx context.Context, event *Event) error
+
+// Handle calls f
+func (f HandlerFunc) Handle(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
+
+// Typed creates a Handler decoding the event data into a T before calling handle. Event data
+// that cannot be decoded is a permanent error.
+func Typed[T any](handle func(ctx context.Context, event *Event, data T) error) Handler {
+	return HandlerFunc(func(ctx context.Context, event *Event) error {
+		data, err := Decode[T](event)
+		if err != nil {
+			return Permanent(err)
+		}
+		return handle(ctx, event, data)
+	})
+}
+
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps err so the event is sent to the DLQ without being retried
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent returns true if err was wrapped with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Registry holds the handler of each event type
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Register sets the handler of the events of eventType
+func (r *Registry) Register(eventType string, handler Handler) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.handlers[eventType]; exists {
+		return fmt.Errorf("%w: %s", ErrHandlerAlreadyRegistered, eventType)
+	}
+	r.handlers[eventType] = handler
+	return nil
+}
+
+// Handler returns the handler of the events of eventType
+func (r *Registry) Handler(eventType string) (Handler, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	handler, ok := r.handlers[eventType]
+	return handler, ok
+}
+
+// EventTypes returns the event types with a handler, sorted
+func (r *Registry) EventTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	eventTypes := make([]string, 0, len(r.handlers))
+	for eventType := range r.handlers {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+	return eventTypes
+}
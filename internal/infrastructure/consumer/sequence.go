@@ -0,0 +1,215 @@
+package consumer
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// Sequence statuses of an event, from its aggregate version and the versions already seen
+// for its aggregate
+const (
+	SequenceInOrder     = "in_order"
+	SequenceGap         = "gap"
+	SequenceLate        = "late"
+	SequenceDuplicate   = "duplicate"
+	SequenceUnversioned = "unversioned"
+)
+
+// maxMissingVersions bounds the versions remembered as missing for an aggregate, the later
+// arrival of a version that was not remembered is reported as a duplicate
+const maxMissingVersions = 1000
+
+// SequenceTracker tracks the last version seen for each aggregate and the versions skipped
+// by a gap, to classify the next events. The least recently seen aggregates are forgotten
+// beyond maxAggregates, their next event is then in order whatever its version.
+type SequenceTracker struct {
+	mu            sync.Mutex
+	maxAggregates int
+	aggregates    map[string]*list.Element
+	recent        *list.List
+}
+
+// aggregateSequence is the tracked sequence of an aggregate
+type aggregateSequence struct {
+	aggregateID string
+	last        int64
+	missing     map[int64]struct{}
+}
+
+// NewSequenceTracker creates a SequenceTracker of at most maxAggregates aggregates
+func NewSequenceTracker(maxAggregates int) *SequenceTracker {
+	return &SequenceTracker{
+		maxAggregates: maxAggregates,
+		aggregates:    make(map[string]*list.Element),
+		recent:        list.New(),
+	}
+}
+
+// Check classifies the version of an event without recording it. The first event seen for
+// an aggregate is in order, since the earlier versions may have been consumed before.
+func (t *SequenceTracker) Check(aggregateID string, version int64) string {
+	if version <= 0 {
+		return SequenceUnversioned
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	element, ok := t.aggregates[aggregateID]
+	if !ok {
+		return SequenceInOrder
+	}
+
+	sequence := element.Value.(*aggregateSequence)
+	switch {
+	case version == sequence.last+1:
+		return SequenceInOrder
+	case version > sequence.last+1:
+		return SequenceGap
+	default:
+		if _, missing := sequence.missing[version]; missing {
+			return SequenceLate
+		}
+		return SequenceDuplicate
+	}
+}
+
+// Record records that an event was consumed, remembering the versions skipped when it
+// follows a gap
+func (t *SequenceTracker) Record(aggregateID string, version int64) {
+	if version <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	element, ok := t.aggregates[aggregateID]
+	if !ok {
+		element = t.recent.PushFront(&aggregateSequence{aggregateID: aggregateID, last: version})
+		t.aggregates[aggregateID] = element
+		t.evict()
+		return
+	}
+	t.recent.MoveToFront(element)
+
+	sequence := element.Value.(*aggregateSequence)
+	if version <= sequence.last {
+		delete(sequence.missing, version)
+		return
+	}
+
+	for missing := sequence.last + 1; missing < version && len(sequence.missing) < maxMissingVersions; missing++ {
+		if sequence.missing == nil {
+			sequence.missing = make(map[int64]struct{})
+		}
+		sequence.missing[missing] = struct{}{}
+	}
+	sequence.last = version
+}
+
+// Last returns the last version seen for the aggregate, zero when it is not tracked
+func (t *SequenceTracker) Last(aggregateID string) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if element, ok := t.aggregates[aggregateID]; ok {
+		return element.Value.(*aggregateSequence).last
+	}
+	return 0
+}
+
+// evict forgets the least recently seen aggregates beyond the limit
+func (t *SequenceTracker) evict() {
+	for t.recent.Len() > t.maxAggregates {
+		oldest := t.recent.Back()
+		t.recent.Remove(oldest)
+		delete(t.aggregates, oldest.Value.(*aggregateSequence).aggregateID)
+	}
+}
+
+// bufferedEvent is an event held after a gap until the missing versions arrive
+type bufferedEvent struct {
+	message    *sarama.ConsumerMessage
+	event      *Event
+	start      time.Time
+	bufferedAt time.Time
+}
+
+// sequenceBuffer holds the events following a gap, per aggregate in version order
+type sequenceBuffer struct {
+	events map[string][]*bufferedEvent
+	size   int
+}
+
+func newSequenceBuffer() *sequenceBuffer {
+	return &sequenceBuffer{events: make(map[string][]*bufferedEvent)}
+}
+
+// add holds an event, keeping the events of its aggregate sorted by version
+func (b *sequenceBuffer) add(buffered *bufferedEvent) {
+	aggregateID := buffered.event.AggregateID
+	events := append(b.events[aggregateID], buffered)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].event.AggregateVersion < events[j].event.AggregateVersion
+	})
+	b.events[aggregateID] = events
+	b.size++
+}
+
+// next returns the lowest version held for the aggregate
+func (b *sequenceBuffer) next(aggregateID string) (*bufferedEvent, bool) {
+	events := b.events[aggregateID]
+	if len(events) == 0 {
+		return nil, false
+	}
+	return events[0], true
+}
+
+// pop removes the lowest version held for the aggregate
+func (b *sequenceBuffer) pop(aggregateID string) {
+	events := b.events[aggregateID]
+	if len(events) <= 1 {
+		delete(b.events, aggregateID)
+	} else {
+		b.events[aggregateID] = events[1:]
+	}
+	b.size--
+}
+
+// oldest returns the aggregate holding the event buffered the longest ago
+func (b *sequenceBuffer) oldest() (string, time.Time, bool) {
+	var (
+		aggregateID string
+		bufferedAt  time.Time
+		found       bool
+	)
+	for id, events := range b.events {
+		for _, buffered := range events {
+			if !found || buffered.bufferedAt.Before(bufferedAt) {
+				aggregateID, bufferedAt, found = id, buffered.bufferedAt, true
+			}
+		}
+	}
+	return aggregateID, bufferedAt, found
+}
+
+// lowestOffset returns the lowest offset held, the committed offset must not move past it
+func (b *sequenceBuffer) lowestOffset() (int64, bool) {
+	var (
+		lowest int64
+		found  bool
+	)
+	for _, events := range b.events {
+		for _, buffered := range events {
+			if !found || buffered.message.Offset < lowest {
+				lowest, found = buffered.message.Offset, true
+			}
+		}
+	}
+	return lowest, found
+}
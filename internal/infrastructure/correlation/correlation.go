@@ -0,0 +1,27 @@
+package correlation
+
+import "context"
+
+// IDs links the events written by a unit of work to the request or event that caused it.
+// CorrelationID is shared by every event descending from the same original request, and
+// CausationID is the ID of the request or event that directly caused the write.
+type IDs struct {
+	CorrelationID string
+	CausationID   string
+}
+
+type idsKey struct{}
+
+// WithIDs returns a copy of ctx carrying the correlation and causation IDs
+func WithIDs(ctx context.Context, ids IDs) context.Context {
+	return context.WithValue(ctx, idsKey{}, ids)
+}
+
+// FromContext returns the correlation and causation IDs carried by ctx, empty when none
+func FromContext(ctx context.Context) IDs {
+	if ctx == nil {
+		return IDs{}
+	}
+	ids, _ := ctx.Value(idsKey{}).(IDs)
+	return ids
+}
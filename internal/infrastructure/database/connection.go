@@ -3,13 +3,18 @@ package database
 import (
 	"fmt"
 	"log"
+	"log/slog"
+	"os"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	gormlogger "gorm.io/gorm/logger"
 
 	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/tracing"
 )
 
 var (
@@ -17,8 +22,9 @@ var (
 	cfg *config.Config
 )
 
-// InitializeDatabase initializes the database connection using configuration
-func InitializeDatabase() error {
+// InitializeDatabase initializes the database connection using configuration. A nil
+// metrics records no query metrics.
+func InitializeDatabase(metrics *metrics.Metrics, logger *slog.Logger) error {
 	var err error
 
 	cfg, err = config.Load()
@@ -26,7 +32,7 @@ func InitializeDatabase() error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	db, err = Connect(cfg.Database)
+	db, err = Connect(cfg.Database, metrics, logger)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -38,18 +44,22 @@ func InitializeDatabase() error {
 	return nil
 }
 
-// Connect establishes a database connection using the provided configuration
-func Connect(dbConfig config.DatabaseConfig) (*gorm.DB, error) {
+// Connect establishes a database connection using the provided configuration. Every statement
+// is traced and timed, statements slower than SlowQueryTime are logged with their fingerprint.
+// A nil metrics records no query metrics.
+func Connect(dbConfig config.DatabaseConfig, metrics *metrics.Metrics, logger *slog.Logger) (*gorm.DB, error) {
 	dsn := dbConfig.GetDSN()
 
-	gormLogger := logger.Default
+	// the slow queries are logged by the query plugin, so the GORM logger has no slow threshold
+	gormLogger := gormlogger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), gormlogger.Config{
+		LogLevel: gormlogger.Warn,
+		Colorful: true,
+	})
 	switch dbConfig.LogLevel {
 	case "debug":
-		gormLogger = logger.Default.LogMode(logger.Info)
+		gormLogger = gormLogger.LogMode(gormlogger.Info)
 	case "error":
-		gormLogger = logger.Default.LogMode(logger.Error)
-	case "warn":
-		gormLogger = logger.Default.LogMode(logger.Warn)
+		gormLogger = gormLogger.LogMode(gormlogger.Error)
 	}
 
 	gormConfig := &gorm.Config{
@@ -61,6 +71,14 @@ func Connect(dbConfig config.DatabaseConfig) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	if err := database.Use(tracing.NewGormPlugin()); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %w", err)
+	}
+
+	if err := database.Use(NewQueryPlugin(dbConfig.GetSlowQueryThreshold(), metrics, logger)); err != nil {
+		return nil, fmt.Errorf("failed to register query instrumentation plugin: %w", err)
+	}
+
 	sqlDB, err := database.DB()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
@@ -74,7 +92,7 @@ func Connect(dbConfig config.DatabaseConfig) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Println("Database connection established successfully")
+	logging.OrDefault(logger).Info("Database connection established", "host", dbConfig.Host, "database", dbConfig.Name)
 	return database, nil
 }
 
@@ -100,30 +118,37 @@ func CloseDatabase() error {
 			return fmt.Errorf("failed to close database connection: %w", err)
 		}
 
-		log.Println("Database connection closed")
+		slog.Info("Database connection closed")
 	}
 	return nil
 }
 
 // AutoMigrate runs database migrations
 func AutoMigrate(database *gorm.DB) error {
-	log.Println("Running database migrations...")
+	slog.Info("Running database migrations")
 
 	models := []interface{}{
 		&models.Order{},
 		&models.OrderItem{},
 		&models.OutboxEvent{},
+		&models.AggregateVersion{},
+		&models.OutboxTransaction{},
 		&models.Event{},
+		&models.ReplayJob{},
+		&models.BackfillCheckpoint{},
+		&models.BackfillOrderSnapshot{},
+		&models.InboxEntry{},
+		&models.InboxGroupEvent{},
 	}
 
 	for _, model := range models {
 		if err := database.AutoMigrate(model); err != nil {
 			return fmt.Errorf("failed to migrate model %T: %w", model, err)
 		}
-		log.Printf("Migrated model: %T", model)
+		slog.Debug("Migrated model", "model", fmt.Sprintf("%T", model))
 	}
 
-	log.Println("Database migrations completed successfully")
+	slog.Info("Database migrations completed")
 	return nil
 }
 
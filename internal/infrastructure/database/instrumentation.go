@@ -0,0 +1,165 @@
+package database
+
+import (
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+)
+
+const queryStartKey = "txstream:query_start"
+
+// UnknownTable is the table label of statements whose table cannot be determined
+const UnknownTable = "unknown"
+
+var (
+	stringLiteralPattern = regexp.MustCompile(`'(?:[^']|'')*'`)
+	placeholderPattern   = regexp.MustCompile(`\$\d+`)
+	numberPattern        = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	valueRowsPattern     = regexp.MustCompile(`(\([?, ]+\))(?:\s*,\s*\([?, ]+\))+`)
+	valueListPattern     = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+	whitespacePattern    = regexp.MustCompile(`\s+`)
+	tablePattern         = regexp.MustCompile(`(?i)\b(?:from|into|update)\s+"?([\w.]+)"?`)
+	forUpdatePattern     = regexp.MustCompile(`(?i)\bfor\s+(?:no\s+key\s+)?update\b`)
+	// partitionPattern matches the date suffix of the outbox partitions, which would otherwise
+	// add a table label and a fingerprint per partition
+	partitionPattern = regexp.MustCompile(`_p\d{8}\b`)
+)
+
+// QueryPlugin records the latency and errors of every GORM statement by operation and table,
+// and logs the statements slower than the threshold with their SQL fingerprint
+type QueryPlugin struct {
+	slowThreshold time.Duration
+	metrics       *metrics.Metrics
+	logger        *slog.Logger
+}
+
+// NewQueryPlugin creates a new QueryPlugin. A zero threshold disables the slow query log and a
+// nil metrics records nothing.
+func NewQueryPlugin(slowThreshold time.Duration, metrics *metrics.Metrics, logger *slog.Logger) *QueryPlugin {
+	return &QueryPlugin{
+		slowThreshold: slowThreshold,
+		metrics:       metrics,
+		logger:        logging.OrDefault(logger),
+	}
+}
+
+// Name returns the plugin name
+func (p *QueryPlugin) Name() string {
+	return "txstream:instrumentation"
+}
+
+// Initialize registers the callbacks around each GORM operation
+func (p *QueryPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("txstream:start_create", startQuery),
+		callbacks.Create().After("gorm:create").Register("txstream:finish_create", p.finishQuery),
+		callbacks.Query().Before("gorm:query").Register("txstream:start_query", startQuery),
+		callbacks.Query().After("gorm:query").Register("txstream:finish_query", p.finishQuery),
+		callbacks.Update().Before("gorm:update").Register("txstream:start_update", startQuery),
+		callbacks.Update().After("gorm:update").Register("txstream:finish_update", p.finishQuery),
+		callbacks.Delete().Before("gorm:delete").Register("txstream:start_delete", startQuery),
+		callbacks.Delete().After("gorm:delete").Register("txstream:finish_delete", p.finishQuery),
+		callbacks.Row().Before("gorm:row").Register("txstream:start_row", startQuery),
+		callbacks.Row().After("gorm:row").Register("txstream:finish_row", p.finishQuery),
+		callbacks.Raw().Before("gorm:raw").Register("txstream:start_raw", startQuery),
+		callbacks.Raw().After("gorm:raw").Register("txstream:finish_raw", p.finishQuery),
+	)
+}
+
+func startQuery(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func (p *QueryPlugin) finishQuery(db *gorm.DB) {
+	value, ok := db.InstanceGet(queryStartKey)
+	if !ok {
+		return
+	}
+	duration := time.Since(value.(time.Time))
+
+	sql := db.Statement.SQL.String()
+	if sql == "" {
+		return
+	}
+	operation := QueryOperation(sql)
+	table := db.Statement.Table
+	if table == "" {
+		table = QueryTable(sql)
+	}
+
+	failed := db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound)
+	if p.metrics != nil {
+		p.metrics.RecordDBQuery(operation, table, duration)
+		if failed {
+			p.metrics.RecordDBQueryError(operation, table)
+		}
+	}
+
+	if p.slowThreshold > 0 && duration >= p.slowThreshold {
+		args := []any{
+			"operation", operation,
+			"table", table,
+			"duration", duration,
+			"threshold", p.slowThreshold,
+			"rows", db.Statement.RowsAffected,
+			"fingerprint", FingerprintSQL(sql),
+		}
+		if failed {
+			args = append(args, "error", db.Error)
+		}
+		p.logger.WarnContext(db.Statement.Context, "Slow query", args...)
+	}
+}
+
+// FingerprintSQL normalizes sql so statements differing only by their values share the same
+// fingerprint: literals and placeholders become ?, value lists are collapsed, partitions are
+// replaced by their parent table and whitespace is squeezed
+func FingerprintSQL(sql string) string {
+	fingerprint := stringLiteralPattern.ReplaceAllString(sql, "?")
+	fingerprint = partitionPattern.ReplaceAllString(fingerprint, "")
+	fingerprint = placeholderPattern.ReplaceAllString(fingerprint, "?")
+	fingerprint = numberPattern.ReplaceAllString(fingerprint, "?")
+	fingerprint = whitespacePattern.ReplaceAllString(fingerprint, " ")
+	fingerprint = valueRowsPattern.ReplaceAllString(fingerprint, "$1, ...")
+	fingerprint = valueListPattern.ReplaceAllString(fingerprint, "(?+)")
+	return strings.TrimSpace(fingerprint)
+}
+
+// QueryOperation returns the operation label of sql, with row locking reads reported as
+// select_for_update so their cost can be told apart from plain reads
+func QueryOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "other"
+	}
+
+	switch operation := strings.ToLower(fields[0]); operation {
+	case "select":
+		if forUpdatePattern.MatchString(sql) {
+			return "select_for_update"
+		}
+		return operation
+	case "insert", "update", "delete", "with":
+		return operation
+	default:
+		return "other"
+	}
+}
+
+// QueryTable returns the first table read or written by sql, used for raw statements.
+// Partitions are reported as their parent table.
+func QueryTable(sql string) string {
+	match := tablePattern.FindStringSubmatch(sql)
+	if match == nil {
+		return UnknownTable
+	}
+	return partitionPattern.ReplaceAllString(match[1], "")
+}
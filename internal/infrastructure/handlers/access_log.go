@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+)
+
+// UnmatchedRoute is the route label of requests that matched no route template
+const UnmatchedRoute = "unmatched"
+
+// AccessLogMiddleware records the count, latency and response size of each request, labeled by
+// its mux route template, and writes a structured access log line. It must run after the
+// RequestIDMiddleware so the log line carries the request ID. A nil metrics only logs.
+func AccessLogMiddleware(apiMetrics *metrics.Metrics, logger *slog.Logger) mux.MiddlewareFunc {
+	logger = logging.OrDefault(logger)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(recorder, r)
+
+			duration := time.Since(start)
+			route := routeTemplate(r)
+			if apiMetrics != nil {
+				apiMetrics.RecordHTTPRequest(r.Method, route, recorder.status, duration, recorder.bytes)
+			}
+
+			logger.InfoContext(r.Context(), "Request handled",
+				"method", r.Method,
+				"route", route,
+				"path", r.URL.Path,
+				"status", recorder.status,
+				"bytes", recorder.bytes,
+				"duration", duration,
+				"remote_addr", r.RemoteAddr,
+				"user_agent", r.UserAgent())
+		})
+	}
+}
+
+// routeTemplate returns the path template of the route matched by r, which keeps the
+// cardinality of the route label bounded by the number of routes
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return UnmatchedRoute
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return UnmatchedRoute
+	}
+	return template
+}
+
+// responseRecorder captures the status code and body size written by a handler
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(body []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(body)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap exposes the wrapped writer to http.ResponseController
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,15 +11,31 @@ import (
 
 	"github.com/lorenaziviani/txstream/internal/application/dto"
 	"github.com/lorenaziviani/txstream/internal/application/usecases"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+)
+
+// Reasons of the orders failed metric
+const (
+	OrderFailureInvalidPayload = "invalid_payload"
+	OrderFailureValidation     = "validation"
+	OrderFailureDuplicate      = "duplicate"
+	OrderFailureInternal       = "internal"
 )
 
 type OrderHandler struct {
 	orderUseCase usecases.OrderUseCase
+	metrics      *metrics.Metrics
+	logger       *slog.Logger
 }
 
-func NewOrderHandler(orderUseCase usecases.OrderUseCase) *OrderHandler {
+// NewOrderHandler creates a new OrderHandler. A nil metrics records nothing and a nil
+// logger uses the default logger.
+func NewOrderHandler(orderUseCase usecases.OrderUseCase, metrics *metrics.Metrics, logger *slog.Logger) *OrderHandler {
 	return &OrderHandler{
 		orderUseCase: orderUseCase,
+		metrics:      metrics,
+		logger:       logging.OrDefault(logger),
 	}
 }
 
@@ -28,6 +45,7 @@ func (h *OrderHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request
 
 	var request dto.CreateOrderRequest
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		h.recordOrderFailed(OrderFailureInvalidPayload)
 		http.Error(w, `{"error": "Invalid JSON payload"}`, http.StatusBadRequest)
 		return
 	}
@@ -35,6 +53,7 @@ func (h *OrderHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request
 	response, err := h.orderUseCase.CreateOrder(r.Context(), &request)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
+		reason := OrderFailureInternal
 		errorMessage := err.Error()
 
 		switch {
@@ -42,11 +61,15 @@ func (h *OrderHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request
 			err.Error() == "validation error: order_number is required" ||
 			err.Error() == "validation error: at least one item is required":
 			statusCode = http.StatusBadRequest
+			reason = OrderFailureValidation
 		case strings.Contains(err.Error(), "duplicate key value violates unique constraint") ||
 			strings.Contains(err.Error(), "UNIQUE constraint failed") ||
 			strings.Contains(err.Error(), "order with number") && strings.Contains(err.Error(), "already exists"):
 			statusCode = http.StatusConflict
+			reason = OrderFailureDuplicate
 		}
+		h.recordOrderFailed(reason)
+		h.logFailure(r, statusCode, "Failed to create order", err, "reason", reason)
 
 		errorResponse := map[string]string{
 			"error": errorMessage,
@@ -57,6 +80,11 @@ func (h *OrderHandler) CreateOrderHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if h.metrics != nil {
+		h.metrics.RecordOrderCreated()
+	}
+	h.logger.InfoContext(r.Context(), "Order created", "order_id", response.ID, "order_number", response.OrderNumber)
+
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(response)
 }
@@ -74,6 +102,7 @@ func (h *OrderHandler) GetOrderByIDHandler(w http.ResponseWriter, r *http.Reques
 		if err.Error() == "failed to get order: order not found with id: "+id {
 			statusCode = http.StatusNotFound
 		}
+		h.logFailure(r, statusCode, "Failed to get order", err)
 
 		errorResponse := map[string]string{
 			"error": err.Error(),
@@ -101,6 +130,7 @@ func (h *OrderHandler) GetOrderByNumberHandler(w http.ResponseWriter, r *http.Re
 		if err.Error() == "failed to get order: order not found with order number: "+orderNumber {
 			statusCode = http.StatusNotFound
 		}
+		h.logFailure(r, statusCode, "Failed to get order", err)
 
 		errorResponse := map[string]string{
 			"error": err.Error(),
@@ -143,6 +173,7 @@ func (h *OrderHandler) ListOrdersHandler(w http.ResponseWriter, r *http.Request)
 
 	response, err := h.orderUseCase.ListOrders(r.Context(), limit, offset)
 	if err != nil {
+		h.logFailure(r, http.StatusInternalServerError, "Failed to list orders", err)
 		errorResponse := map[string]string{
 			"error": err.Error(),
 		}
@@ -155,3 +186,18 @@ func (h *OrderHandler) ListOrdersHandler(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+// logFailure logs a failed request, as an error when it is a server error
+func (h *OrderHandler) logFailure(r *http.Request, statusCode int, msg string, err error, args ...any) {
+	level := slog.LevelInfo
+	if statusCode >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	h.logger.Log(r.Context(), level, msg, append([]any{"status", statusCode, "error", err}, args...)...)
+}
+
+func (h *OrderHandler) recordOrderFailed(reason string) {
+	if h.metrics != nil {
+		h.metrics.RecordOrderFailed(reason)
+	}
+}
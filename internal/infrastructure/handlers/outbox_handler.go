@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lorenaziviani/txstream/internal/application/dto"
+	"github.com/lorenaziviani/txstream/internal/application/usecases"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+type OutboxHandler struct {
+	outboxUseCase usecases.OutboxUseCase
+	logger        *slog.Logger
+}
+
+// NewOutboxHandler creates a new OutboxHandler
+func NewOutboxHandler(outboxUseCase usecases.OutboxUseCase, logger *slog.Logger) *OutboxHandler {
+	return &OutboxHandler{
+		outboxUseCase: outboxUseCase,
+		logger:        logging.OrDefault(logger),
+	}
+}
+
+// RegisterRoutes registers the outbox administration routes
+func (h *OutboxHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/events", h.ListEventsHandler).Methods("GET")
+	router.HandleFunc("/events/bulk/{action}", h.BulkActionHandler).Methods("POST")
+	router.HandleFunc("/events/correlations/{correlation_id}", h.CorrelatedEventsHandler).Methods("GET")
+	router.HandleFunc("/events/{id}", h.GetEventHandler).Methods("GET")
+	router.HandleFunc("/events/{id}", h.DeleteEventHandler).Methods("DELETE")
+	router.HandleFunc("/events/{id}/{action}", h.EventActionHandler).Methods("POST")
+}
+
+// ListEventsHandler processes the GET /events request
+func (h *OutboxHandler) ListEventsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	request := &dto.ListOutboxEventsRequest{
+		Filter: dto.OutboxEventFilterDTO{
+			EventType:     query.Get("event_type"),
+			AggregateID:   query.Get("aggregate_id"),
+			AggregateType: query.Get("aggregate_type"),
+			CorrelationID: query.Get("correlation_id"),
+		},
+		Cursor: query.Get("cursor"),
+	}
+
+	for _, value := range query["status"] {
+		request.Filter.Statuses = append(request.Filter.Statuses, strings.Split(value, ",")...)
+	}
+
+	var err error
+	if request.Filter.CreatedAfter, err = parseTimeParam(query.Get("created_after")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid created_after: "+err.Error())
+		return
+	}
+	if request.Filter.CreatedBefore, err = parseTimeParam(query.Get("created_before")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid created_before: "+err.Error())
+		return
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if request.Limit, err = strconv.Atoi(limitStr); err != nil || request.Limit <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit: "+limitStr)
+			return
+		}
+	}
+
+	response, err := h.outboxUseCase.ListEvents(r.Context(), request)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// GetEventHandler processes the GET /events/{id} request
+func (h *OutboxHandler) GetEventHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := h.outboxUseCase.GetEvent(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// CorrelatedEventsHandler processes the GET /events/correlations/{correlation_id} request
+func (h *OutboxHandler) CorrelatedEventsHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := h.outboxUseCase.ListCorrelatedEvents(r.Context(), mux.Vars(r)["correlation_id"])
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteEventHandler processes the DELETE /events/{id} request
+func (h *OutboxHandler) DeleteEventHandler(w http.ResponseWriter, r *http.Request) {
+	response, err := h.outboxUseCase.ApplyEventAction(r.Context(), usecases.OutboxActionDelete, mux.Vars(r)["id"], "")
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "Outbox action applied", "action", usecases.OutboxActionDelete, logging.EventIDKey, mux.Vars(r)["id"])
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// EventActionHandler processes the POST /events/{id}/{action} request.
+// The optional body sets the reason of a dead-letter action.
+func (h *OutboxHandler) EventActionHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var request struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	response, err := h.outboxUseCase.ApplyEventAction(r.Context(), vars["action"], vars["id"], request.Reason)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "Outbox action applied", "action", vars["action"], logging.EventIDKey, vars["id"])
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// BulkActionHandler processes the POST /events/bulk/{action} request
+func (h *OutboxHandler) BulkActionHandler(w http.ResponseWriter, r *http.Request) {
+	var request dto.OutboxBulkActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	response, err := h.outboxUseCase.ApplyAction(r.Context(), mux.Vars(r)["action"], &request)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "Outbox bulk action applied", "action", mux.Vars(r)["action"], "dry_run", request.DryRun)
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// writeError writes the error of the outbox use case, logging the server errors
+func (h *OutboxHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	statusCode := outboxErrorStatus(err)
+	if statusCode >= http.StatusInternalServerError {
+		h.logger.ErrorContext(r.Context(), "Outbox request failed", "path", r.URL.Path, "error", err)
+	}
+	writeError(w, statusCode, err.Error())
+}
+
+// outboxErrorStatus maps the outbox use case errors to HTTP status codes
+func outboxErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repositories.ErrEventNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrInvalidOutboxRequest), errors.Is(err, usecases.ErrUnknownOutboxAction):
+		return http.StatusBadRequest
+	case errors.Is(err, usecases.ErrOutboxActionNotAllowed):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func parseTimeParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
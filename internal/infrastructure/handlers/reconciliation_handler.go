@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lorenaziviani/txstream/internal/application/usecases"
+)
+
+// ReconcileController runs the reconciliation and keeps its last report
+type ReconcileController interface {
+	Reconcile(ctx context.Context, repair bool) (*usecases.ReconciliationReport, error)
+	LastReport() *usecases.ReconciliationReport
+}
+
+// reconcileRequest is the optional body of POST /reconciliation
+type reconcileRequest struct {
+	Repair bool `json:"repair"`
+}
+
+type ReconciliationHandler struct {
+	reconciler ReconcileController
+}
+
+func NewReconciliationHandler(reconciler ReconcileController) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciler: reconciler,
+	}
+}
+
+// RegisterRoutes registers the reconciliation routes
+func (h *ReconciliationHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/reconciliation", h.GetReportHandler).Methods("GET")
+	router.HandleFunc("/reconciliation", h.ReconcileHandler).Methods("POST")
+}
+
+// GetReportHandler processes the GET /reconciliation request, returning the last report
+func (h *ReconciliationHandler) GetReportHandler(w http.ResponseWriter, r *http.Request) {
+	report := h.reconciler.LastReport()
+	if report == nil {
+		writeError(w, http.StatusNotFound, "no reconciliation has run yet")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// ReconcileHandler processes the POST /reconciliation request, running a reconciliation
+// and returning its report
+func (h *ReconciliationHandler) ReconcileHandler(w http.ResponseWriter, r *http.Request) {
+	var request reconcileRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	report, err := h.reconciler.Reconcile(r.Context(), request.Repair)
+	if err != nil {
+		if errors.Is(err, usecases.ErrReconcileRunning) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if report == nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, report)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/replay"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+// ReplayController runs and tracks replay jobs
+type ReplayController interface {
+	Start(ctx context.Context, req *replay.Request) (*models.ReplayJob, error)
+	Count(ctx context.Context, req *replay.Request) (int64, error)
+	ResumeAsync(ctx context.Context, id string) (*models.ReplayJob, error)
+	Cancel(id string) error
+	Get(ctx context.Context, id string) (*models.ReplayJob, error)
+	List(ctx context.Context, limit int) ([]models.ReplayJob, error)
+}
+
+// replayRequest is the body of POST /replays, times use RFC3339
+type replayRequest struct {
+	Source        string     `json:"source,omitempty"`
+	AggregateID   string     `json:"aggregate_id,omitempty"`
+	AggregateType string     `json:"aggregate_type,omitempty"`
+	EventType     string     `json:"event_type,omitempty"`
+	CreatedAfter  *time.Time `json:"created_after,omitempty"`
+	CreatedBefore *time.Time `json:"created_before,omitempty"`
+	Topic         string     `json:"topic,omitempty"`
+	RateLimit     *float64   `json:"rate_limit,omitempty"`
+	DryRun        bool       `json:"dry_run"`
+}
+
+type ReplayHandler struct {
+	replayer ReplayController
+}
+
+func NewReplayHandler(replayer ReplayController) *ReplayHandler {
+	return &ReplayHandler{
+		replayer: replayer,
+	}
+}
+
+// RegisterRoutes registers the replay routes
+func (h *ReplayHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/replays", h.StartReplayHandler).Methods("POST")
+	router.HandleFunc("/replays", h.ListReplaysHandler).Methods("GET")
+	router.HandleFunc("/replays/{id}", h.GetReplayHandler).Methods("GET")
+	router.HandleFunc("/replays/{id}/resume", h.ResumeReplayHandler).Methods("POST")
+	router.HandleFunc("/replays/{id}/cancel", h.CancelReplayHandler).Methods("POST")
+}
+
+// StartReplayHandler processes the POST /replays request. A dry run only counts the
+// selected events, otherwise the job runs in the background.
+func (h *ReplayHandler) StartReplayHandler(w http.ResponseWriter, r *http.Request) {
+	var request replayRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	req := &replay.Request{
+		Source:        request.Source,
+		AggregateID:   request.AggregateID,
+		AggregateType: request.AggregateType,
+		EventType:     request.EventType,
+		CreatedAfter:  request.CreatedAfter,
+		CreatedBefore: request.CreatedBefore,
+		Topic:         request.Topic,
+		RateLimit:     request.RateLimit,
+	}
+
+	if request.DryRun {
+		count, err := h.replayer.Count(r.Context(), req)
+		if err != nil {
+			writeError(w, replayErrorStatus(err), err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"dry_run": true,
+			"matched": count,
+		})
+		return
+	}
+
+	job, err := h.replayer.Start(r.Context(), req)
+	if err != nil {
+		writeError(w, replayErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// ListReplaysHandler processes the GET /replays request
+func (h *ReplayHandler) ListReplaysHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit: "+limitStr)
+			return
+		}
+		limit = parsed
+	}
+
+	jobs, err := h.replayer.List(r.Context(), limit)
+	if err != nil {
+		writeError(w, replayErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"replays": jobs,
+	})
+}
+
+// GetReplayHandler processes the GET /replays/{id} request
+func (h *ReplayHandler) GetReplayHandler(w http.ResponseWriter, r *http.Request) {
+	job, err := h.replayer.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, replayErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, job)
+}
+
+// ResumeReplayHandler processes the POST /replays/{id}/resume request
+func (h *ReplayHandler) ResumeReplayHandler(w http.ResponseWriter, r *http.Request) {
+	job, err := h.replayer.ResumeAsync(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, replayErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+// CancelReplayHandler processes the POST /replays/{id}/cancel request
+func (h *ReplayHandler) CancelReplayHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.replayer.Cancel(mux.Vars(r)["id"]); err != nil {
+		writeError(w, replayErrorStatus(err), err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"status": "cancelling",
+	})
+}
+
+// replayErrorStatus maps the replay errors to HTTP status codes
+func replayErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repositories.ErrReplayJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, replay.ErrInvalidRequest), errors.Is(err, replay.ErrArchiveDisabled):
+		return http.StatusBadRequest
+	case errors.Is(err, replay.ErrNotResumable), errors.Is(err, replay.ErrNotRunning):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
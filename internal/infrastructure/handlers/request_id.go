@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/correlation"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+)
+
+// Headers carrying the ID of a request and the correlation ID of the flow it belongs to
+const (
+	RequestIDHeader     = "X-Request-ID"
+	CorrelationIDHeader = "X-Correlation-ID"
+)
+
+// maxRequestIDLength bounds the IDs accepted from the request headers
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestIDMiddleware propagates the X-Request-ID and X-Correlation-ID headers of the
+// request, generating a request ID when it is missing and using it as correlation ID when
+// none is given. Both are added to the response and to the log fields of the request
+// context, and the events written by the request are correlated with them, caused by the
+// request.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := headerID(r, RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+		correlationID := headerID(r, CorrelationIDHeader)
+		if correlationID == "" {
+			correlationID = requestID
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		w.Header().Set(CorrelationIDHeader, correlationID)
+
+		ctx := context.WithValue(r.Context(), requestIDKey{}, requestID)
+		ctx = correlation.WithIDs(ctx, correlation.IDs{CorrelationID: correlationID, CausationID: requestID})
+		ctx = logging.WithAttrs(ctx,
+			slog.String(logging.RequestIDKey, requestID),
+			slog.String(logging.CorrelationIDKey, correlationID),
+		)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFromContext returns the ID of the request handled with ctx
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// headerID returns the ID in the request header, empty when it is missing or too long
+func headerID(r *http.Request, header string) string {
+	id := r.Header.Get(header)
+	if len(id) > maxRequestIDLength {
+		return ""
+	}
+	return id
+}
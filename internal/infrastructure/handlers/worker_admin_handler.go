@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/kafka"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/worker"
+)
+
+// defaultDrainTimeout bounds a drain request that does not set a timeout
+const defaultDrainTimeout = 30 * time.Second
+
+// WorkerController is the runtime control surface of the outbox worker
+type WorkerController interface {
+	Pause()
+	Resume()
+	IsPaused() bool
+	Drain(ctx context.Context) error
+	InFlight() int
+	Settings() worker.RuntimeSettings
+	UpdateSettings(update worker.SettingsUpdate) (worker.RuntimeSettings, error)
+}
+
+// settingsRequest is the body of PATCH /worker/settings, durations use Go syntax (ex: 500ms)
+type settingsRequest struct {
+	BatchSize *int    `json:"batch_size,omitempty"`
+	Interval  *string `json:"interval,omitempty"`
+	PoolSize  *int    `json:"pool_size,omitempty"`
+}
+
+type WorkerAdminHandler struct {
+	worker       WorkerController
+	producer     kafka.EventProducer
+	workerConfig config.WorkerConfig
+}
+
+func NewWorkerAdminHandler(worker WorkerController, producer kafka.EventProducer, workerConfig config.WorkerConfig) *WorkerAdminHandler {
+	return &WorkerAdminHandler{
+		worker:       worker,
+		producer:     producer,
+		workerConfig: workerConfig,
+	}
+}
+
+// RegisterRoutes registers the control-plane routes
+func (h *WorkerAdminHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/worker/status", h.StatusHandler).Methods("GET")
+	router.HandleFunc("/worker/config", h.ConfigHandler).Methods("GET")
+	router.HandleFunc("/worker/pause", h.PauseHandler).Methods("POST")
+	router.HandleFunc("/worker/resume", h.ResumeHandler).Methods("POST")
+	router.HandleFunc("/worker/drain", h.DrainHandler).Methods("POST")
+	router.HandleFunc("/worker/settings", h.UpdateSettingsHandler).Methods("PATCH")
+	router.HandleFunc("/circuit-breaker", h.CircuitBreakerHandler).Methods("GET")
+	router.HandleFunc("/circuit-breaker/open", h.ForceCircuitBreakerOpenHandler).Methods("POST")
+	router.HandleFunc("/circuit-breaker/close", h.ForceCircuitBreakerCloseHandler).Methods("POST")
+}
+
+// StatusHandler processes the GET /worker/status request
+func (h *WorkerAdminHandler) StatusHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.status())
+}
+
+// ConfigHandler processes the GET /worker/config request
+func (h *WorkerAdminHandler) ConfigHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"worker": map[string]interface{}{
+			"settings":               settingsResponse(h.worker.Settings()),
+			"max_retries":            h.workerConfig.MaxRetries,
+			"retry_delay":            h.workerConfig.RetryDelay.String(),
+			"lease_duration":         h.workerConfig.LeaseDuration.String(),
+			"shutdown_timeout":       h.workerConfig.ShutdownTimeout.String(),
+			"default_event_ttl":      h.workerConfig.DefaultEventTTL.String(),
+			"event_ttls":             h.workerConfig.EventTTLs,
+			"event_priorities":       h.workerConfig.EventPriorities,
+			"priority_weights":       h.workerConfig.PriorityWeights,
+			"topic_rate_limits":      h.workerConfig.TopicRateLimits,
+			"event_type_rate_limits": h.workerConfig.EventTypeRateLimits,
+			"rate_limit_max_wait":    h.workerConfig.RateLimitMaxWait.String(),
+			"adaptive_enabled":       h.workerConfig.AdaptiveEnabled,
+		},
+		"retry": h.producer.GetRetryConfig(),
+	})
+}
+
+// PauseHandler processes the POST /worker/pause request
+func (h *WorkerAdminHandler) PauseHandler(w http.ResponseWriter, r *http.Request) {
+	h.worker.Pause()
+	writeJSON(w, http.StatusOK, h.status())
+}
+
+// ResumeHandler processes the POST /worker/resume request
+func (h *WorkerAdminHandler) ResumeHandler(w http.ResponseWriter, r *http.Request) {
+	h.worker.Resume()
+	writeJSON(w, http.StatusOK, h.status())
+}
+
+// DrainHandler processes the POST /worker/drain request. The optional timeout
+// query parameter bounds how long to wait for the in-flight events.
+func (h *WorkerAdminHandler) DrainHandler(w http.ResponseWriter, r *http.Request) {
+	timeout := defaultDrainTimeout
+	if value := r.URL.Query().Get("timeout"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid timeout: "+value)
+			return
+		}
+		timeout = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	if err := h.worker.Drain(ctx); err != nil {
+		writeError(w, http.StatusGatewayTimeout, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, h.status())
+}
+
+// UpdateSettingsHandler processes the PATCH /worker/settings request
+func (h *WorkerAdminHandler) UpdateSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	var request settingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON payload")
+		return
+	}
+
+	update := worker.SettingsUpdate{
+		BatchSize: request.BatchSize,
+		PoolSize:  request.PoolSize,
+	}
+	if request.Interval != nil {
+		interval, err := time.ParseDuration(*request.Interval)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid interval: "+*request.Interval)
+			return
+		}
+		update.Interval = &interval
+	}
+
+	settings, err := h.worker.UpdateSettings(update)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, worker.ErrInvalidSettings) {
+			statusCode = http.StatusBadRequest
+		}
+		writeError(w, statusCode, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, settingsResponse(settings))
+}
+
+// CircuitBreakerHandler processes the GET /circuit-breaker request
+func (h *WorkerAdminHandler) CircuitBreakerHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.producer.GetCircuitBreakerStats())
+}
+
+// ForceCircuitBreakerOpenHandler processes the POST /circuit-breaker/open request
+func (h *WorkerAdminHandler) ForceCircuitBreakerOpenHandler(w http.ResponseWriter, r *http.Request) {
+	h.producer.ForceCircuitBreakerOpen()
+	writeJSON(w, http.StatusOK, h.producer.GetCircuitBreakerStats())
+}
+
+// ForceCircuitBreakerCloseHandler processes the POST /circuit-breaker/close request
+func (h *WorkerAdminHandler) ForceCircuitBreakerCloseHandler(w http.ResponseWriter, r *http.Request) {
+	h.producer.ForceCircuitBreakerClose()
+	writeJSON(w, http.StatusOK, h.producer.GetCircuitBreakerStats())
+}
+
+func (h *WorkerAdminHandler) status() map[string]interface{} {
+	return map[string]interface{}{
+		"paused":          h.worker.IsPaused(),
+		"in_flight":       h.worker.InFlight(),
+		"settings":        settingsResponse(h.worker.Settings()),
+		"circuit_breaker": h.producer.GetCircuitBreakerStats(),
+	}
+}
+
+func settingsResponse(settings worker.RuntimeSettings) map[string]interface{} {
+	return map[string]interface{}{
+		"batch_size": settings.BatchSize,
+		"interval":   settings.Interval.String(),
+		"pool_size":  settings.PoolSize,
+	}
+}
+
+// BearerTokenMiddleware rejects the requests that do not carry the token as a bearer token
+func BearerTokenMiddleware(token string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="txstream-admin"`)
+				writeError(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeJSON(w, statusCode, map[string]string{
+		"error": message,
+	})
+}
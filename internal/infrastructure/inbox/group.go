@@ -0,0 +1,246 @@
+package inbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/consumer"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+// GroupHandlerFunc handles the events written by one database transaction inside the inbox
+// transaction. The events are ordered by creation time, then by aggregate and version. A group
+// handed over by the group timeout holds fewer events than their TransactionEventCount.
+type GroupHandlerFunc func(ctx context.Context, tx *gorm.DB, events []*consumer.Event) error
+
+// timedOutGroupEventType is the event type of the inbox entry marking a transaction whose
+// group was handed over incomplete
+const timedOutGroupEventType = "TransactionGroupTimedOut"
+
+// Group creates a consumer.Handler collecting the events written by the same transaction and
+// running handle once with all of them. It must be registered for every event type the
+// transactions write, and is safe across partitions and consumer instances since the events
+// are collected in the database.
+func (i *Inbox) Group(handle GroupHandlerFunc) consumer.Handler {
+	return consumer.HandlerFunc(func(ctx context.Context, event *consumer.Event) error {
+		_, err := i.Collect(ctx, event, handle)
+		return err
+	})
+}
+
+// Collect records the event and holds it until every event written by its transaction is
+// received, then runs handle with all of them and releases them in the same transaction.
+// Returns true when handle ran. An event written alone, or before transactions were
+// recorded, is handled right away as a group of one, and so is an event arriving after its
+// group was handed over by the group timeout.
+func (i *Inbox) Collect(ctx context.Context, event *consumer.Event, handle GroupHandlerFunc) (bool, error) {
+	if event.TransactionID == "" || event.TransactionEventCount <= 1 {
+		return i.Process(ctx, event, func(ctx context.Context, tx *gorm.DB, event *consumer.Event) error {
+			return handle(ctx, tx, []*consumer.Event{event})
+		})
+	}
+	if event.ID == "" {
+		return false, consumer.Permanent(ErrMissingEventID)
+	}
+
+	encoded, err := json.Marshal(event)
+	if err != nil {
+		return false, consumer.Permanent(fmt.Errorf("failed to encode event: %w", err))
+	}
+
+	var (
+		duplicate bool
+		late      bool
+		received  int
+	)
+	err = i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inboxRepo := repositories.NewInboxRepository(tx)
+
+		if err := inboxRepo.LockGroup(ctx, i.consumerGroup, event.TransactionID); err != nil {
+			return fmt.Errorf("failed to lock transaction group: %w", err)
+		}
+
+		recorded, err := inboxRepo.Record(ctx, i.entry(event))
+		if err != nil {
+			return fmt.Errorf("failed to record event in the inbox: %w", err)
+		}
+		if !recorded {
+			duplicate = true
+			return nil
+		}
+
+		timedOut, err := inboxRepo.Exists(ctx, i.consumerGroup, timedOutGroupID(event.TransactionID))
+		if err != nil {
+			return fmt.Errorf("failed to check transaction group: %w", err)
+		}
+		if timedOut {
+			late = true
+			return handle(ctx, tx, []*consumer.Event{event})
+		}
+
+		if err := inboxRepo.AddGroupEvent(ctx, &models.InboxGroupEvent{
+			ConsumerGroup: i.consumerGroup,
+			TransactionID: event.TransactionID,
+			EventID:       event.ID,
+			EventCount:    event.TransactionEventCount,
+			Event:         string(encoded),
+		}); err != nil {
+			return fmt.Errorf("failed to hold event: %w", err)
+		}
+
+		held, err := inboxRepo.ListGroupEvents(ctx, i.consumerGroup, event.TransactionID)
+		if err != nil {
+			return fmt.Errorf("failed to list transaction group: %w", err)
+		}
+		received = len(held)
+		if received < event.TransactionEventCount {
+			return nil
+		}
+
+		events, err := decodeGroup(held)
+		if err != nil {
+			return err
+		}
+		if err := handle(ctx, tx, events); err != nil {
+			return err
+		}
+
+		return inboxRepo.DeleteGroup(ctx, i.consumerGroup, event.TransactionID)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case duplicate:
+		i.recordDuplicate(ctx, event)
+		return false, nil
+	case late:
+		i.logger.WarnContext(ctx, "Event applied alone after its transaction group timed out",
+			"transaction_id", event.TransactionID)
+		return true, nil
+	case received < event.TransactionEventCount:
+		i.logger.DebugContext(ctx, "Event held until its transaction is complete",
+			"transaction_id", event.TransactionID, "received", received, "expected", event.TransactionEventCount)
+		return false, nil
+	}
+
+	if i.metrics != nil {
+		i.metrics.RecordInboxGroupApplied(i.consumerGroup)
+	}
+	i.logger.DebugContext(ctx, "Transaction group applied",
+		"transaction_id", event.TransactionID, "events", received)
+	return true, nil
+}
+
+// CompleteExpiredGroups hands at most limit transaction groups whose first event was received
+// before receivedBefore to handle, with the events received so far, and returns the number of
+// groups handed over. The transaction is marked in the inbox, so its events arriving later
+// are handled alone instead of waiting for the timeout again.
+func (i *Inbox) CompleteExpiredGroups(ctx context.Context, receivedBefore time.Time, limit int, handle GroupHandlerFunc) (int, error) {
+	transactionIDs, err := repositories.NewInboxRepository(i.db).ListExpiredGroups(ctx, i.consumerGroup, receivedBefore, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired transaction groups: %w", err)
+	}
+
+	completed := 0
+	for _, transactionID := range transactionIDs {
+		received, expected, err := i.completeGroup(ctx, transactionID, handle)
+		if err != nil {
+			return completed, err
+		}
+		if received == 0 {
+			continue
+		}
+
+		completed++
+		if i.metrics != nil {
+			i.metrics.RecordInboxGroupTimedOut(i.consumerGroup)
+		}
+		i.logger.WarnContext(ctx, "Transaction group applied without all of its events",
+			"transaction_id", transactionID, "received", received, "expected", expected)
+	}
+
+	return completed, nil
+}
+
+// completeGroup runs handle with the events held for the transaction and releases them,
+// returning the number of events handled and expected; none when the group completed meanwhile
+func (i *Inbox) completeGroup(ctx context.Context, transactionID string, handle GroupHandlerFunc) (received, expected int, err error) {
+	err = i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inboxRepo := repositories.NewInboxRepository(tx)
+
+		if err := inboxRepo.LockGroup(ctx, i.consumerGroup, transactionID); err != nil {
+			return fmt.Errorf("failed to lock transaction group: %w", err)
+		}
+
+		held, err := inboxRepo.ListGroupEvents(ctx, i.consumerGroup, transactionID)
+		if err != nil {
+			return fmt.Errorf("failed to list transaction group: %w", err)
+		}
+		if len(held) == 0 {
+			return nil
+		}
+
+		events, err := decodeGroup(held)
+		if err != nil {
+			return err
+		}
+		if err := handle(ctx, tx, events); err != nil {
+			return err
+		}
+
+		if err := inboxRepo.DeleteGroup(ctx, i.consumerGroup, transactionID); err != nil {
+			return fmt.Errorf("failed to release transaction group: %w", err)
+		}
+		if _, err := inboxRepo.Record(ctx, &models.InboxEntry{
+			ConsumerGroup: i.consumerGroup,
+			EventID:       timedOutGroupID(transactionID),
+			EventType:     timedOutGroupEventType,
+		}); err != nil {
+			return fmt.Errorf("failed to mark transaction group: %w", err)
+		}
+
+		received, expected = len(held), held[0].EventCount
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return received, expected, nil
+}
+
+// timedOutGroupID returns the inbox event ID marking the transaction as handed over
+func timedOutGroupID(transactionID string) string {
+	return "transaction:" + transactionID
+}
+
+// decodeGroup decodes the held events of a transaction in the order of GroupHandlerFunc
+func decodeGroup(held []models.InboxGroupEvent) ([]*consumer.Event, error) {
+	events := make([]*consumer.Event, 0, len(held))
+	for _, row := range held {
+		var event consumer.Event
+		if err := json.Unmarshal([]byte(row.Event), &event); err != nil {
+			return nil, fmt.Errorf("failed to decode held event %s: %w", row.EventID, err)
+		}
+		events = append(events, &event)
+	}
+
+	sort.SliceStable(events, func(a, b int) bool {
+		if !events[a].CreatedAt.Equal(events[b].CreatedAt) {
+			return events[a].CreatedAt.Before(events[b].CreatedAt)
+		}
+		if events[a].AggregateID != events[b].AggregateID {
+			return events[a].AggregateID < events[b].AggregateID
+		}
+		return events[a].AggregateVersion < events[b].AggregateVersion
+	})
+	return events, nil
+}
@@ -0,0 +1,91 @@
+package inbox
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+)
+
+// GroupTimeoutJob hands the transaction groups still incomplete after the group timeout to
+// the group handler, so a transaction with an event that is never delivered (for example one
+// sent to the DLQ) is applied with the events received instead of being held until the
+// retention deletes it.
+type GroupTimeoutJob struct {
+	config   config.InboxConfig
+	inbox    *Inbox
+	handle   GroupHandlerFunc
+	logger   *slog.Logger
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewGroupTimeoutJob creates a GroupTimeoutJob for the groups collected by eventInbox with handle
+func NewGroupTimeoutJob(cfg config.InboxConfig, eventInbox *Inbox, handle GroupHandlerFunc, logger *slog.Logger) *GroupTimeoutJob {
+	return &GroupTimeoutJob{
+		config:   cfg,
+		inbox:    eventInbox,
+		handle:   handle,
+		logger:   logging.OrDefault(logger),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start checks the groups every half of the group timeout until the context is cancelled or
+// Stop is called
+func (j *GroupTimeoutJob) Start(ctx context.Context) {
+	j.logger.InfoContext(ctx, "Starting inbox group timeout job", "group_timeout", j.config.GroupTimeout)
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(j.config.GroupTimeout / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-j.stopChan:
+				return
+			case <-ticker.C:
+				if _, err := j.Run(ctx); err != nil {
+					j.logger.ErrorContext(ctx, "Inbox group timeout run failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic group timeout job
+func (j *GroupTimeoutJob) Stop() {
+	close(j.stopChan)
+	j.wg.Wait()
+}
+
+// Run hands the expired groups over in batches, and returns the number of groups handed over
+func (j *GroupTimeoutJob) Run(ctx context.Context) (int, error) {
+	receivedBefore := time.Now().Add(-j.config.GroupTimeout)
+	total := 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		default:
+		}
+
+		completed, err := j.inbox.CompleteExpiredGroups(ctx, receivedBefore, j.config.BatchSize, j.handle)
+		total += completed
+		if err != nil {
+			return total, err
+		}
+		if completed < j.config.BatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"gorm.io/gorm"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/consumer"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+// ErrMissingEventID is returned for events without an ID, which cannot be deduplicated
+var ErrMissingEventID = errors.New("event has no event_id to deduplicate on")
+
+// TxHandlerFunc handles an event inside the inbox transaction. The writes made through tx
+// are committed together with the inbox entry, writes made on another connection are not
+// covered by the deduplication.
+type TxHandlerFunc func(ctx context.Context, tx *gorm.DB, event *consumer.Event) error
+
+// Inbox makes the handlers of a consumer group idempotent. The event ID is recorded in the
+// inbox table in the transaction of the handler writes, and a redelivered event whose ID is
+// already recorded is skipped. A handler error rolls back both, so the retry handles the
+// event again.
+type Inbox struct {
+	db            *gorm.DB
+	consumerGroup string
+	metrics       *metrics.Metrics
+	logger        *slog.Logger
+}
+
+// NewInbox creates an Inbox recording the events processed by consumerGroup. A nil metrics
+// records nothing.
+func NewInbox(db *gorm.DB, consumerGroup string, metrics *metrics.Metrics, logger *slog.Logger) *Inbox {
+	return &Inbox{
+		db:            db,
+		consumerGroup: consumerGroup,
+		metrics:       metrics,
+		logger:        logging.OrDefault(logger),
+	}
+}
+
+// Handler creates a consumer.Handler running handle at most once per event
+func (i *Inbox) Handler(handle TxHandlerFunc) consumer.Handler {
+	return consumer.HandlerFunc(func(ctx context.Context, event *consumer.Event) error {
+		_, err := i.Process(ctx, event, handle)
+		return err
+	})
+}
+
+// Typed creates a consumer.Handler decoding the event data into a T, then running handle at
+// most once per event. Event data that cannot be decoded is a permanent error.
+func Typed[T any](i *Inbox, handle func(ctx context.Context, tx *gorm.DB, event *consumer.Event, data T) error) consumer.Handler {
+	return consumer.HandlerFunc(func(ctx context.Context, event *consumer.Event) error {
+		data, err := consumer.Decode[T](event)
+		if err != nil {
+			return consumer.Permanent(err)
+		}
+
+		_, err = i.Process(ctx, event, func(ctx context.Context, tx *gorm.DB, event *consumer.Event) error {
+			return handle(ctx, tx, event, data)
+		})
+		return err
+	})
+}
+
+// Process records the event and runs handle in one transaction, and returns false without
+// running handle when the event was already processed
+func (i *Inbox) Process(ctx context.Context, event *consumer.Event, handle TxHandlerFunc) (bool, error) {
+	if event.ID == "" {
+		return false, consumer.Permanent(ErrMissingEventID)
+	}
+
+	duplicate := false
+	err := i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		recorded, err := repositories.NewInboxRepository(tx).Record(ctx, i.entry(event))
+		if err != nil {
+			return fmt.Errorf("failed to record event in the inbox: %w", err)
+		}
+		if !recorded {
+			duplicate = true
+			return nil
+		}
+
+		return handle(ctx, tx, event)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if duplicate {
+		i.recordDuplicate(ctx, event)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// entry returns the inbox entry recording the event
+func (i *Inbox) entry(event *consumer.Event) *models.InboxEntry {
+	return &models.InboxEntry{
+		ConsumerGroup: i.consumerGroup,
+		EventID:       event.ID,
+		EventType:     event.EventType,
+		AggregateID:   event.AggregateID,
+		Topic:         event.Topic,
+		Partition:     event.Partition,
+		Offset:        event.Offset,
+	}
+}
+
+// recordDuplicate reports a redelivered event skipped by the inbox
+func (i *Inbox) recordDuplicate(ctx context.Context, event *consumer.Event) {
+	if i.metrics != nil {
+		i.metrics.RecordInboxDuplicate(i.consumerGroup, event.EventType)
+	}
+	i.logger.InfoContext(ctx, "Duplicate event skipped", "consumer_group", i.consumerGroup,
+		"topic", event.Topic, "partition", event.Partition, "offset", event.Offset)
+}
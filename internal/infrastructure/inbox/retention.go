@@ -0,0 +1,139 @@
+package inbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+// RetentionJob deletes the inbox entries older than the inbox retention, and the events held
+// longer than the retention for a transaction that never completed and that no GroupTimeoutJob
+// handed over. The retention must
+// exceed the longest redelivery delay, since an event redelivered after its entry was
+// deleted is processed again.
+type RetentionJob struct {
+	config    config.InboxConfig
+	inboxRepo repositories.InboxRepository
+	metrics   *metrics.Metrics
+	logger    *slog.Logger
+	stopChan  chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewRetentionJob creates a RetentionJob. A nil metrics records nothing.
+func NewRetentionJob(cfg config.InboxConfig, inboxRepo repositories.InboxRepository, metrics *metrics.Metrics, logger *slog.Logger) *RetentionJob {
+	return &RetentionJob{
+		config:    cfg,
+		inboxRepo: inboxRepo,
+		metrics:   metrics,
+		logger:    logging.OrDefault(logger),
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start runs the retention job periodically until the context is cancelled or Stop is called
+func (j *RetentionJob) Start(ctx context.Context) {
+	j.logger.InfoContext(ctx, "Starting inbox retention job",
+		"retention", j.config.Retention,
+		"interval", j.config.Interval,
+		"batch_size", j.config.BatchSize)
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(j.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-j.stopChan:
+				return
+			case <-ticker.C:
+				if _, err := j.Run(ctx); err != nil {
+					j.logger.ErrorContext(ctx, "Inbox retention run failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic retention job
+func (j *RetentionJob) Stop() {
+	close(j.stopChan)
+	j.wg.Wait()
+}
+
+// Run deletes the entries older than the retention in batches, and returns the number of
+// deleted entries
+func (j *RetentionJob) Run(ctx context.Context) (int64, error) {
+	cutoff := time.Now().Add(-j.config.Retention)
+	var purged int64
+
+	for {
+		select {
+		case <-ctx.Done():
+			return purged, ctx.Err()
+		default:
+		}
+
+		deleted, err := j.inboxRepo.DeleteProcessedBefore(ctx, cutoff, j.config.BatchSize)
+		if err != nil {
+			return purged, fmt.Errorf("failed to delete inbox entries: %w", err)
+		}
+		purged += deleted
+		if j.metrics != nil && deleted > 0 {
+			j.metrics.RecordInboxRowsPurged(int(deleted))
+		}
+
+		if deleted < int64(j.config.BatchSize) {
+			break
+		}
+	}
+
+	if purged > 0 {
+		j.logger.InfoContext(ctx, "Inbox retention run purged entries", "purged", purged, "cutoff", cutoff)
+	}
+
+	abandoned, err := j.purgeGroupEvents(ctx, cutoff)
+	if abandoned > 0 {
+		j.logger.WarnContext(ctx, "Inbox retention run abandoned events of incomplete transactions",
+			"abandoned", abandoned, "cutoff", cutoff)
+	}
+	return purged, err
+}
+
+// purgeGroupEvents deletes the events held before cutoff in batches, and returns the number
+// of deleted events
+func (j *RetentionJob) purgeGroupEvents(ctx context.Context, cutoff time.Time) (int64, error) {
+	var abandoned int64
+	for {
+		select {
+		case <-ctx.Done():
+			return abandoned, ctx.Err()
+		default:
+		}
+
+		deleted, err := j.inboxRepo.DeleteGroupEventsReceivedBefore(ctx, cutoff, j.config.BatchSize)
+		if err != nil {
+			return abandoned, fmt.Errorf("failed to delete held group events: %w", err)
+		}
+		abandoned += deleted
+		if j.metrics != nil && deleted > 0 {
+			j.metrics.RecordInboxGroupEventsAbandoned(int(deleted))
+		}
+
+		if deleted < int64(j.config.BatchSize) {
+			return abandoned, nil
+		}
+	}
+}
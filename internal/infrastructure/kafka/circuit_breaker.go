@@ -3,10 +3,11 @@ package kafka
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
 	"time"
 
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
 )
 
@@ -50,10 +51,11 @@ type CircuitBreaker struct {
 
 	onStateChange func(from, to CircuitBreakerState)
 	metrics       *metrics.Metrics
+	logger        *slog.Logger
 }
 
 // NewCircuitBreaker creates a new CircuitBreaker instance
-func NewCircuitBreaker(failureThreshold, successThreshold int, timeoutDuration, resetTimeout time.Duration, metrics *metrics.Metrics) *CircuitBreaker {
+func NewCircuitBreaker(failureThreshold, successThreshold int, timeoutDuration, resetTimeout time.Duration, metrics *metrics.Metrics, logger *slog.Logger) *CircuitBreaker {
 	cb := &CircuitBreaker{
 		state:            StateClosed,
 		failureThreshold: failureThreshold,
@@ -66,6 +68,7 @@ func NewCircuitBreaker(failureThreshold, successThreshold int, timeoutDuration,
 		lastStateChange:  time.Now(),
 		mu:               sync.RWMutex{},
 		metrics:          metrics,
+		logger:           logging.OrDefault(logger),
 	}
 
 	if metrics != nil {
@@ -73,7 +76,7 @@ func NewCircuitBreaker(failureThreshold, successThreshold int, timeoutDuration,
 	}
 
 	cb.onStateChange = func(from, to CircuitBreakerState) {
-		log.Printf("Circuit Breaker state changed from %s to %s", from.String(), to.String())
+		cb.logger.Warn("Circuit Breaker state changed", "from", from.String(), "to", to.String())
 	}
 
 	return cb
@@ -4,14 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"math/rand"
+	"strconv"
 	"time"
 
 	"github.com/IBM/sarama"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/tracing"
 )
 
 type Producer struct {
@@ -19,17 +22,32 @@ type Producer struct {
 	config         *config.KafkaConfig
 	circuitBreaker *CircuitBreaker
 	metrics        *metrics.Metrics
+	logger         *slog.Logger
 }
 
 // NewProducerForTesting creates a producer for testing purposes
 func NewProducerForTesting(cfg *config.KafkaConfig) *Producer {
 	return &Producer{
 		config: cfg,
+		logger: slog.Default(),
+	}
+}
+
+// NewProducerWithSyncProducer creates a producer publishing through the given sarama
+// producer, used by tests with sarama mocks
+func NewProducerWithSyncProducer(cfg *config.KafkaConfig, producer sarama.SyncProducer, metrics *metrics.Metrics, logger *slog.Logger) *Producer {
+	return &Producer{
+		producer: producer,
+		config:   cfg,
+		metrics:  metrics,
+		logger:   logging.OrDefault(logger),
 	}
 }
 
 // NewProducer creates a new Kafka producer
-func NewProducer(cfg *config.KafkaConfig, metrics *metrics.Metrics) (*Producer, error) {
+func NewProducer(cfg *config.KafkaConfig, metrics *metrics.Metrics, logger *slog.Logger) (*Producer, error) {
+	logger = logging.OrDefault(logger)
+
 	var circuitBreaker *CircuitBreaker
 	if cfg.CircuitBreakerEnabled {
 		circuitBreaker = NewCircuitBreaker(
@@ -38,17 +56,22 @@ func NewProducer(cfg *config.KafkaConfig, metrics *metrics.Metrics) (*Producer,
 			cfg.TimeoutDuration,
 			cfg.ResetTimeout,
 			metrics,
+			logger,
 		)
-		log.Printf("Circuit Breaker enabled with failure_threshold=%d, success_threshold=%d, timeout=%s, reset_timeout=%s",
-			cfg.FailureThreshold, cfg.SuccessThreshold, cfg.TimeoutDuration, cfg.ResetTimeout)
+		logger.Info("Circuit Breaker enabled",
+			"failure_threshold", cfg.FailureThreshold,
+			"success_threshold", cfg.SuccessThreshold,
+			"timeout", cfg.TimeoutDuration,
+			"reset_timeout", cfg.ResetTimeout)
 	}
 
 	if !cfg.IsKafkaEnabled() {
-		log.Println("Kafka not enabled, creating producer in simulation mode")
+		logger.Warn("Kafka not enabled, creating producer in simulation mode")
 		return &Producer{
 			config:         cfg,
 			circuitBreaker: circuitBreaker,
 			metrics:        metrics,
+			logger:         logger,
 		}, nil
 	}
 
@@ -68,58 +91,81 @@ func NewProducer(cfg *config.KafkaConfig, metrics *metrics.Metrics) (*Producer,
 
 	producer, err := sarama.NewSyncProducer(cfg.GetKafkaBrokers(), config)
 	if err != nil {
-		log.Printf("Failed to create Kafka producer: %v", err)
-		log.Println("Creating producer in simulation mode")
+		logger.Error("Failed to create Kafka producer, creating producer in simulation mode", "error", err)
 		return &Producer{
 			config:         cfg,
 			circuitBreaker: circuitBreaker,
 			metrics:        metrics,
+			logger:         logger,
 		}, nil
 	}
 
-	log.Printf("Kafka producer created successfully for brokers: %v", cfg.GetKafkaBrokers())
+	logger.Info("Kafka producer created", "brokers", cfg.GetKafkaBrokers())
 
 	return &Producer{
 		producer:       producer,
 		config:         cfg,
 		circuitBreaker: circuitBreaker,
 		metrics:        metrics,
+		logger:         logger,
 	}, nil
 }
 
 // PublishEvent publishes an outbox event to Kafka with circuit breaker protection
 func (p *Producer) PublishEvent(ctx context.Context, event *models.OutboxEvent) error {
+	return p.PublishEventWithOptions(ctx, event, PublishOptions{})
+}
+
+// PublishEventWithOptions publishes an outbox event to Kafka with circuit breaker protection,
+// applying the topic and header overrides
+func (p *Producer) PublishEventWithOptions(ctx context.Context, event *models.OutboxEvent, opts PublishOptions) error {
 	if p.circuitBreaker != nil {
-		return p.publishEventWithCircuitBreaker(ctx, event)
+		return p.publishEventWithCircuitBreaker(ctx, event, opts)
 	}
 
-	return p.publishEventDirectly(ctx, event)
+	return p.publishEventDirectly(ctx, event, opts)
 }
 
 // publishEventWithCircuitBreaker publishes an event using circuit breaker protection
-func (p *Producer) publishEventWithCircuitBreaker(ctx context.Context, event *models.OutboxEvent) error {
+func (p *Producer) publishEventWithCircuitBreaker(ctx context.Context, event *models.OutboxEvent, opts PublishOptions) error {
 	return p.circuitBreaker.Execute(ctx, func() error {
-		return p.publishEventDirectly(ctx, event)
+		return p.publishEventDirectly(ctx, event, opts)
 	})
 }
 
 // publishEventDirectly publishes an event directly to Kafka with exponential retry
-func (p *Producer) publishEventDirectly(ctx context.Context, event *models.OutboxEvent) error {
+func (p *Producer) publishEventDirectly(ctx context.Context, event *models.OutboxEvent, opts PublishOptions) error {
 	if p.producer == nil {
-		log.Println("Kafka producer not initialized, skipping event publication")
+		p.logger.WarnContext(ctx, "Kafka producer not initialized, skipping event publication", logging.EventIDKey, event.ID)
 		return nil
 	}
 
+	topic := p.config.TopicEvents
+	if opts.Topic != "" {
+		topic = opts.Topic
+	}
+
 	message := &sarama.ProducerMessage{
-		Topic: p.config.TopicEvents,
+		Topic: topic,
 		Key:   sarama.StringEncoder(event.AggregateID),
 		Value: sarama.StringEncoder(p.createEventPayload(event)),
 		Headers: []sarama.RecordHeader{
 			{Key: []byte("event_type"), Value: []byte(event.EventType)},
 			{Key: []byte("aggregate_type"), Value: []byte(event.AggregateType)},
-			{Key: []byte("event_id"), Value: []byte(event.ID.String())},
+			{Key: []byte("event_id"), Value: []byte(event.MessageID().String())},
+			{Key: []byte("aggregate_version"), Value: []byte(strconv.FormatInt(event.AggregateVersion, 10))},
+			{Key: []byte("correlation_id"), Value: []byte(event.CorrelationID)},
+			{Key: []byte("causation_id"), Value: []byte(event.CausationID)},
+			{Key: []byte("transaction_id"), Value: []byte(transactionID(event))},
+			{Key: []byte("transaction_event_count"), Value: []byte(strconv.Itoa(event.TransactionEventCount))},
 		},
 	}
+	for key, value := range opts.Headers {
+		message.Headers = append(message.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+	for key, value := range tracing.Headers(ctx) {
+		message.Headers = append(message.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
 
 	var lastErr error
 	for attempt := 0; attempt <= p.config.MaxRetries; attempt++ {
@@ -131,14 +177,17 @@ func (p *Producer) publishEventDirectly(ctx context.Context, event *models.Outbo
 
 		partition, offset, err := p.producer.SendMessage(message)
 		if err == nil {
-			log.Printf("Event published successfully to Kafka - Topic: %s, Partition: %d, Offset: %d, EventID: %s",
-				p.config.TopicEvents, partition, offset, event.ID.String())
+			if opts.Receipt != nil {
+				*opts.Receipt = DeliveryReceipt{Topic: topic, Partition: partition, Offset: offset}
+			}
+			p.logger.DebugContext(ctx, "Event published to Kafka",
+				logging.EventIDKey, event.ID, "topic", topic, "partition", partition, "offset", offset)
 			return nil
 		}
 
 		lastErr = err
-		log.Printf("Failed to publish event to Kafka (attempt %d/%d): %v, EventID: %s",
-			attempt+1, p.config.MaxRetries+1, err, event.ID.String())
+		p.logger.WarnContext(ctx, "Failed to publish event to Kafka",
+			logging.EventIDKey, event.ID, "attempt", attempt+1, "max_attempts", p.config.MaxRetries+1, "error", err)
 
 		if attempt == p.config.MaxRetries {
 			break
@@ -150,7 +199,7 @@ func (p *Producer) publishEventDirectly(ctx context.Context, event *models.Outbo
 			p.metrics.RecordRetryDelayDuration(fmt.Sprintf("%d", attempt+1), delay)
 		}
 
-		log.Printf("Retrying in %v (attempt %d/%d)", delay, attempt+2, p.config.MaxRetries+1)
+		p.logger.DebugContext(ctx, "Retrying publish", logging.EventIDKey, event.ID, "delay", delay, "attempt", attempt+2)
 
 		select {
 		case <-ctx.Done():
@@ -187,12 +236,17 @@ func (p *Producer) CalculateRetryDelay(attempt int) time.Duration {
 // createEventPayload creates the event payload for Kafka
 func (p *Producer) createEventPayload(event *models.OutboxEvent) string {
 	payload := map[string]interface{}{
-		"event_id":       event.ID.String(),
-		"aggregate_id":   event.AggregateID,
-		"aggregate_type": event.AggregateType,
-		"event_type":     event.EventType,
-		"event_data":     event.EventData,
-		"created_at":     event.CreatedAt.Format(time.RFC3339),
+		"event_id":                event.MessageID().String(),
+		"aggregate_id":            event.AggregateID,
+		"aggregate_type":          event.AggregateType,
+		"aggregate_version":       event.AggregateVersion,
+		"correlation_id":          event.CorrelationID,
+		"causation_id":            event.CausationID,
+		"transaction_id":          transactionID(event),
+		"transaction_event_count": event.TransactionEventCount,
+		"event_type":              event.EventType,
+		"event_data":              event.EventData,
+		"created_at":              event.CreatedAt.Format(time.RFC3339),
 	}
 
 	if event.EventMetadata != nil {
@@ -201,29 +255,34 @@ func (p *Producer) createEventPayload(event *models.OutboxEvent) string {
 
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
-		log.Printf("Failed to marshal event payload: %v", err)
+		p.logger.Error("Failed to marshal event payload", logging.EventIDKey, event.ID, "error", err)
 		return "{}"
 	}
 
 	payloadSize := len(jsonPayload)
 	maxSize := 1024 * 1024 // 1MB
 	if payloadSize > maxSize {
-		log.Printf("Warning: Event payload size (%d bytes) exceeds maximum allowed size (%d bytes). EventID: %s",
-			payloadSize, maxSize, event.ID.String())
+		p.logger.Warn("Event payload exceeds maximum allowed size, truncating",
+			logging.EventIDKey, event.ID, "size", payloadSize, "max_size", maxSize)
 
 		truncatedPayload := map[string]interface{}{
-			"event_id":       event.ID.String(),
-			"aggregate_id":   event.AggregateID,
-			"aggregate_type": event.AggregateType,
-			"event_type":     event.EventType,
-			"created_at":     event.CreatedAt.Format(time.RFC3339),
-			"error":          "payload_truncated_due_to_size_limit",
-			"original_size":  payloadSize,
+			"event_id":                event.MessageID().String(),
+			"aggregate_id":            event.AggregateID,
+			"aggregate_type":          event.AggregateType,
+			"aggregate_version":       event.AggregateVersion,
+			"correlation_id":          event.CorrelationID,
+			"causation_id":            event.CausationID,
+			"transaction_id":          transactionID(event),
+			"transaction_event_count": event.TransactionEventCount,
+			"event_type":              event.EventType,
+			"created_at":              event.CreatedAt.Format(time.RFC3339),
+			"error":                   "payload_truncated_due_to_size_limit",
+			"original_size":           payloadSize,
 		}
 
 		truncatedJSON, err := json.Marshal(truncatedPayload)
 		if err != nil {
-			log.Printf("Failed to marshal truncated payload: %v", err)
+			p.logger.Error("Failed to marshal truncated payload", logging.EventIDKey, event.ID, "error", err)
 			return "{}"
 		}
 
@@ -233,13 +292,21 @@ func (p *Producer) createEventPayload(event *models.OutboxEvent) string {
 	return string(jsonPayload)
 }
 
+// transactionID returns the transaction ID of the event, empty when it has none
+func transactionID(event *models.OutboxEvent) string {
+	if event.TransactionID == nil {
+		return ""
+	}
+	return event.TransactionID.String()
+}
+
 // Close closes the Kafka producer
 func (p *Producer) Close() error {
 	if p.producer != nil {
 		if err := p.producer.Close(); err != nil {
 			return fmt.Errorf("failed to close Kafka producer: %w", err)
 		}
-		log.Println("Kafka producer closed successfully")
+		p.logger.Info("Kafka producer closed")
 	}
 	return nil
 }
@@ -7,8 +7,27 @@ import (
 	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
 )
 
+// PublishOptions overrides how an event is published
+type PublishOptions struct {
+	// Topic replaces the configured events topic when set
+	Topic string
+	// Headers are added to the default message headers
+	Headers map[string]string
+	// Receipt receives the partition and offset of the message when set. It stays empty
+	// when the producer runs in simulation mode.
+	Receipt *DeliveryReceipt
+}
+
+// DeliveryReceipt is the position of a published message in Kafka
+type DeliveryReceipt struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+}
+
 type EventProducer interface {
 	PublishEvent(ctx context.Context, event *models.OutboxEvent) error
+	PublishEventWithOptions(ctx context.Context, event *models.OutboxEvent, opts PublishOptions) error
 	Close() error
 	IsConnected() bool
 	GetConfig() *config.KafkaConfig
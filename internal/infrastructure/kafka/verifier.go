@@ -0,0 +1,112 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+)
+
+// DeliveryStatus is the result of the verification of a delivery receipt
+type DeliveryStatus string
+
+const (
+	// DeliveryFound means the message at the receipt offset carries the event ID
+	DeliveryFound DeliveryStatus = "found"
+	// DeliveryMissing means the receipt offset is past the end of the partition log or was skipped
+	DeliveryMissing DeliveryStatus = "missing"
+	// DeliveryMismatch means the message at the receipt offset belongs to another event
+	DeliveryMismatch DeliveryStatus = "mismatch"
+	// DeliveryExpired means the receipt offset was already removed by the topic retention
+	DeliveryExpired DeliveryStatus = "expired"
+)
+
+// DeliveryVerifier checks delivery receipts against the partition logs
+type DeliveryVerifier struct {
+	client   sarama.Client
+	consumer sarama.Consumer
+	timeout  time.Duration
+}
+
+// NewDeliveryVerifier creates a new DeliveryVerifier connected to the configured brokers
+func NewDeliveryVerifier(cfg *config.KafkaConfig) (*DeliveryVerifier, error) {
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Consumer.Return.Errors = true
+
+	client, err := sarama.NewClient(cfg.GetKafkaBrokers(), saramaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka client: %w", err)
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to create Kafka consumer: %w", err)
+	}
+
+	return &DeliveryVerifier{
+		client:   client,
+		consumer: consumer,
+		timeout:  cfg.Timeout,
+	}, nil
+}
+
+// Verify fetches the message at the receipt offset and compares its event_id header
+func (v *DeliveryVerifier) Verify(ctx context.Context, eventID uuid.UUID, receipt DeliveryReceipt) (DeliveryStatus, error) {
+	newest, err := v.client.GetOffset(receipt.Topic, receipt.Partition, sarama.OffsetNewest)
+	if err != nil {
+		return "", fmt.Errorf("failed to get newest offset of %s/%d: %w", receipt.Topic, receipt.Partition, err)
+	}
+	if receipt.Offset >= newest {
+		return DeliveryMissing, nil
+	}
+
+	oldest, err := v.client.GetOffset(receipt.Topic, receipt.Partition, sarama.OffsetOldest)
+	if err != nil {
+		return "", fmt.Errorf("failed to get oldest offset of %s/%d: %w", receipt.Topic, receipt.Partition, err)
+	}
+	if receipt.Offset < oldest {
+		return DeliveryExpired, nil
+	}
+
+	partitionConsumer, err := v.consumer.ConsumePartition(receipt.Topic, receipt.Partition, receipt.Offset)
+	if err != nil {
+		return "", fmt.Errorf("failed to consume %s/%d at offset %d: %w", receipt.Topic, receipt.Partition, receipt.Offset, err)
+	}
+	defer partitionConsumer.Close()
+
+	timer := time.NewTimer(v.timeout)
+	defer timer.Stop()
+
+	select {
+	case message := <-partitionConsumer.Messages():
+		if message.Offset != receipt.Offset {
+			return DeliveryMissing, nil
+		}
+		for _, header := range message.Headers {
+			if string(header.Key) == "event_id" && string(header.Value) == eventID.String() {
+				return DeliveryFound, nil
+			}
+		}
+		return DeliveryMismatch, nil
+	case err := <-partitionConsumer.Errors():
+		return "", fmt.Errorf("failed to fetch %s/%d at offset %d: %w", receipt.Topic, receipt.Partition, receipt.Offset, err)
+	case <-timer.C:
+		return "", fmt.Errorf("timed out fetching %s/%d at offset %d", receipt.Topic, receipt.Partition, receipt.Offset)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Close closes the consumer and the client
+func (v *DeliveryVerifier) Close() error {
+	if err := v.consumer.Close(); err != nil {
+		v.client.Close()
+		return err
+	}
+	return v.client.Close()
+}
@@ -0,0 +1,141 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+)
+
+// LevelFatal is the level of the messages logged right before the process exits
+const LevelFatal = slog.Level(12)
+
+// Keys of the contextual fields
+const (
+	EventIDKey       = "event_id"
+	EventTypeKey     = "event_type"
+	AggregateIDKey   = "aggregate_id"
+	RequestIDKey     = "request_id"
+	CorrelationIDKey = "correlation_id"
+	WorkerIDKey      = "worker_id"
+)
+
+// New creates the logger configured by cfg. The returned closer closes the log file and
+// must be called on exit.
+func New(cfg config.LoggingConfig) (*slog.Logger, io.Closer, error) {
+	var output io.WriteCloser
+	switch cfg.OutputPath {
+	case "", "stdout":
+		output = nopWriteCloser{os.Stdout}
+	case "stderr":
+		output = nopWriteCloser{os.Stderr}
+	default:
+		output = &lumberjack.Logger{
+			Filename:   cfg.OutputPath,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+			Compress:   cfg.Compress,
+		}
+	}
+
+	logger, err := NewWithWriter(output, cfg)
+	if err != nil {
+		output.Close()
+		return nil, nil, err
+	}
+
+	return logger, output, nil
+}
+
+// NewWithWriter creates the logger configured by cfg writing to output, ignoring the
+// output path and rotation settings
+func NewWithWriter(output io.Writer, cfg config.LoggingConfig) (*slog.Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: replaceLevel,
+	}
+
+	var handler slog.Handler
+	switch cfg.Format {
+	case "text":
+		handler = slog.NewTextHandler(output, options)
+	case "json", "":
+		handler = slog.NewJSONHandler(output, options)
+	default:
+		return nil, fmt.Errorf("invalid log format: %s", cfg.Format)
+	}
+
+	return slog.New(contextHandler{handler}), nil
+}
+
+// Setup creates the logger configured by cfg and installs it as the default logger, which
+// also sends the output of the standard log package through it
+func Setup(cfg config.LoggingConfig) (*slog.Logger, io.Closer, error) {
+	logger, closer, err := New(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slog.SetDefault(logger)
+	return logger, closer, nil
+}
+
+// ParseLevel parses a configured log level
+func ParseLevel(level string) (slog.Level, error) {
+	switch level {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	case "fatal":
+		return LevelFatal, nil
+	default:
+		return 0, fmt.Errorf("invalid log level: %s", level)
+	}
+}
+
+// OrDefault returns logger, or the default logger when it is nil. Constructors taking a
+// logger as their last parameter pass it through OrDefault, so nil is always accepted.
+func OrDefault(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+	return logger
+}
+
+// Fatal logs the message at the fatal level and exits the process
+func Fatal(logger *slog.Logger, msg string, args ...any) {
+	OrDefault(logger).Log(context.Background(), LevelFatal, msg, args...)
+	os.Exit(1)
+}
+
+// replaceLevel names the fatal level, which slog would print as ERROR+4
+func replaceLevel(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := attr.Value.Any().(slog.Level); ok && level == LevelFatal {
+			return slog.String(slog.LevelKey, "FATAL")
+		}
+	}
+	return attr
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -2,12 +2,10 @@ package metrics
 
 import (
 	"context"
-	"fmt"
-	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // Metrics holds all Prometheus metrics
@@ -16,27 +14,68 @@ type Metrics struct {
 	eventsPublishedTotal *prometheus.CounterVec
 	eventsFailedTotal    *prometheus.CounterVec
 	eventsRetriedTotal   *prometheus.CounterVec
+	eventsExpiredTotal   *prometheus.CounterVec
+	eventsThrottledTotal *prometheus.CounterVec
+	eventsAbandonedTotal *prometheus.CounterVec
+	rowsPurgedTotal      *prometheus.CounterVec
+	rowsArchivedTotal    *prometheus.CounterVec
+	partitionsDropped    *prometheus.CounterVec
+	eventsRecoveredTotal *prometheus.CounterVec
+	eventsReplayedTotal  *prometheus.CounterVec
+	eventsReconciled     *prometheus.CounterVec
 	circuitBreakerTrips  *prometheus.CounterVec
+	httpRequestsTotal    *prometheus.CounterVec
+	ordersCreatedTotal   *prometheus.CounterVec
+	ordersFailedTotal    *prometheus.CounterVec
+	dbQueryErrorsTotal   *prometheus.CounterVec
+	consumerMessages     *prometheus.CounterVec
+	consumerRetries      *prometheus.CounterVec
+	consumerSequence     *prometheus.CounterVec
+	inboxDuplicates      *prometheus.CounterVec
+	inboxRowsPurged      *prometheus.CounterVec
+	inboxGroupsApplied   *prometheus.CounterVec
+	inboxGroupsAbandoned *prometheus.CounterVec
+	inboxGroupsTimedOut  *prometheus.CounterVec
 
 	eventProcessingDuration *prometheus.HistogramVec
 	eventPublishingDuration *prometheus.HistogramVec
+	eventDeliveryLatency    *prometheus.HistogramVec
 	retryDelayDuration      *prometheus.HistogramVec
+	throttleWaitDuration    *prometheus.HistogramVec
+	httpRequestDuration     *prometheus.HistogramVec
+	httpResponseSize        *prometheus.HistogramVec
+	dbQueryDuration         *prometheus.HistogramVec
+	consumerDuration        *prometheus.HistogramVec
 
 	workerPoolSize      *prometheus.GaugeVec
 	eventsInQueue       *prometheus.GaugeVec
+	eventsInLane        *prometheus.GaugeVec
 	circuitBreakerState *prometheus.GaugeVec
 	activeWorkers       *prometheus.GaugeVec
-
-	registry *prometheus.Registry
+	eventsInFlight      *prometheus.GaugeVec
+	batchSize           *prometheus.GaugeVec
+	pollInterval        *prometheus.GaugeVec
+	outboxPartitions    *prometheus.GaugeVec
+	oldestPendingAge    *prometheus.GaugeVec
+	stuckEvents         *prometheus.GaugeVec
+	escalatedEvents     prometheus.Gauge
+	workerPaused        *prometheus.GaugeVec
+	discrepancies       *prometheus.GaugeVec
+	sloObjective        prometheus.Gauge
+	sloBudgetRemaining  prometheus.Gauge
+	sloBurnRate         *prometheus.GaugeVec
+	consumerLag         *prometheus.GaugeVec
+	consumerBuffered    *prometheus.GaugeVec
 }
 
-// NewMetrics creates and registers all Prometheus metrics
-func NewMetrics() *Metrics {
-	registry := prometheus.NewRegistry()
+// NewMetrics creates all Prometheus metrics and registers them on registerer, which is
+// the process registry shared by every component. A nil registerer uses a private registry.
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	if registerer == nil {
+		registerer = prometheus.NewRegistry()
+	}
 
 	metrics := &Metrics{
-		registry: registry,
-
 		eventsProcessedTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "txstream_events_processed_total",
@@ -69,6 +108,78 @@ func NewMetrics() *Metrics {
 			[]string{"retry_count", "event_type"},
 		),
 
+		eventsExpiredTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_events_expired_total",
+				Help: "Total number of events discarded because their TTL expired before publication",
+			},
+			[]string{"event_type"},
+		),
+
+		eventsThrottledTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_events_throttled_total",
+				Help: "Total number of events left pending because a publish rate limit was exceeded",
+			},
+			[]string{"topic", "event_type"},
+		),
+
+		eventsAbandonedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_events_abandoned_total",
+				Help: "Total number of in-flight events abandoned when the shutdown deadline was exceeded",
+			},
+			[]string{"event_type"},
+		),
+
+		rowsPurgedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_outbox_rows_purged_total",
+				Help: "Total number of outbox rows permanently deleted by the retention job",
+			},
+			[]string{},
+		),
+
+		rowsArchivedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_outbox_rows_archived_total",
+				Help: "Total number of outbox rows written to archive segments before purge",
+			},
+			[]string{},
+		),
+
+		partitionsDropped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_outbox_partitions_dropped_total",
+				Help: "Total number of outbox partitions dropped or detached by the partition manager",
+			},
+			[]string{"action"},
+		),
+
+		eventsRecoveredTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_events_recovered_total",
+				Help: "Total number of stuck or stalled events recovered by the watchdog",
+			},
+			[]string{"reason"},
+		),
+
+		eventsReplayedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_events_replayed_total",
+				Help: "Total number of events republished by replay jobs",
+			},
+			[]string{"source"},
+		),
+
+		eventsReconciled: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_reconciliation_repaired_total",
+				Help: "Total number of compensating events enqueued by the reconciler",
+			},
+			[]string{"type"},
+		),
+
 		circuitBreakerTrips: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "txstream_circuit_breaker_trips_total",
@@ -77,6 +188,102 @@ func NewMetrics() *Metrics {
 			[]string{"from_state", "to_state"},
 		),
 
+		httpRequestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_http_requests_total",
+				Help: "Total number of HTTP requests handled by the API, by route template and status code",
+			},
+			[]string{"method", "route", "status"},
+		),
+
+		ordersCreatedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_orders_created_total",
+				Help: "Total number of orders created by the API",
+			},
+			[]string{},
+		),
+
+		ordersFailedTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_orders_failed_total",
+				Help: "Total number of order creations rejected or failed, by reason",
+			},
+			[]string{"reason"},
+		),
+
+		dbQueryErrorsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_db_query_errors_total",
+				Help: "Total number of failed database statements, by operation and table",
+			},
+			[]string{"operation", "table"},
+		),
+
+		consumerMessages: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_consumer_messages_total",
+				Help: "Total number of messages consumed, by outcome (handled, skipped, dead_lettered, discarded, duplicate)",
+			},
+			[]string{"topic", "event_type", "outcome"},
+		),
+
+		consumerRetries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_consumer_retries_total",
+				Help: "Total number of handler attempts retried by the consumer",
+			},
+			[]string{"topic", "event_type"},
+		),
+
+		consumerSequence: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_consumer_sequence_anomalies_total",
+				Help: "Total number of consumed events out of their aggregate sequence, by status (gap, late, duplicate)",
+			},
+			[]string{"topic", "event_type", "status"},
+		),
+
+		inboxDuplicates: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_inbox_duplicates_total",
+				Help: "Total number of events skipped by the inbox because they were already processed",
+			},
+			[]string{"consumer_group", "event_type"},
+		),
+
+		inboxRowsPurged: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_inbox_rows_purged_total",
+				Help: "Total number of inbox rows deleted by the inbox retention job",
+			},
+			[]string{},
+		),
+
+		inboxGroupsApplied: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_inbox_transaction_groups_applied_total",
+				Help: "Total number of transactions whose events were all received and applied together",
+			},
+			[]string{"consumer_group"},
+		),
+
+		inboxGroupsAbandoned: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_inbox_group_events_abandoned_total",
+				Help: "Total number of events of incomplete transactions deleted by the inbox retention job",
+			},
+			[]string{},
+		),
+
+		inboxGroupsTimedOut: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "txstream_inbox_transaction_groups_timed_out_total",
+				Help: "Total number of transactions applied without all of their events after the group timeout",
+			},
+			[]string{"consumer_group"},
+		),
+
 		// Histograms
 		eventProcessingDuration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -96,6 +303,15 @@ func NewMetrics() *Metrics {
 			[]string{"topic", "event_type"},
 		),
 
+		eventDeliveryLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "txstream_event_delivery_latency_seconds",
+				Help:    "Time between the creation of an event in the outbox and its publication to Kafka",
+				Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10, 30, 60, 300, 900, 3600},
+			},
+			[]string{"topic", "event_type"},
+		),
+
 		retryDelayDuration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Name:    "txstream_retry_delay_duration_seconds",
@@ -105,6 +321,51 @@ func NewMetrics() *Metrics {
 			[]string{"retry_attempt"},
 		),
 
+		throttleWaitDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "txstream_throttle_wait_duration_seconds",
+				Help:    "Time spent waiting for publish rate limit tokens",
+				Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2, 5},
+			},
+			[]string{"topic", "event_type"},
+		),
+
+		httpRequestDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "txstream_http_request_duration_seconds",
+				Help:    "Time spent handling HTTP requests, by route template and status code",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"method", "route", "status"},
+		),
+
+		httpResponseSize: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "txstream_http_response_size_bytes",
+				Help:    "Size of the HTTP response bodies, by route template and status code",
+				Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+			},
+			[]string{"method", "route", "status"},
+		),
+
+		dbQueryDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "txstream_db_query_duration_seconds",
+				Help:    "Time spent executing database statements, by operation and table",
+				Buckets: []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+			},
+			[]string{"operation", "table"},
+		),
+
+		consumerDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "txstream_consumer_handling_duration_seconds",
+				Help:    "Time spent handling a consumed message, including its retries",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"topic", "event_type"},
+		),
+
 		workerPoolSize: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "txstream_worker_pool_size",
@@ -121,6 +382,14 @@ func NewMetrics() *Metrics {
 			[]string{"status"},
 		),
 
+		eventsInLane: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "txstream_events_in_lane",
+				Help: "Number of pending events in each priority lane",
+			},
+			[]string{"lane"},
+		),
+
 		circuitBreakerState: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "txstream_circuit_breaker_state",
@@ -136,40 +405,177 @@ func NewMetrics() *Metrics {
 			},
 			[]string{},
 		),
+
+		eventsInFlight: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "txstream_events_in_flight",
+				Help: "Number of events currently claimed and being published by the worker",
+			},
+			[]string{},
+		),
+
+		batchSize: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "txstream_worker_batch_size",
+				Help: "Current effective batch size of the worker",
+			},
+			[]string{},
+		),
+
+		pollInterval: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "txstream_worker_poll_interval_seconds",
+				Help: "Current effective polling interval of the worker",
+			},
+			[]string{},
+		),
+
+		outboxPartitions: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "txstream_outbox_partitions",
+				Help: "Number of partitions attached to the outbox table",
+			},
+			[]string{},
+		),
+
+		oldestPendingAge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "txstream_outbox_oldest_pending_age_seconds",
+				Help: "Age of the oldest pending event in the outbox",
+			},
+			[]string{},
+		),
+
+		workerPaused: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "txstream_worker_paused",
+				Help: "Whether the worker is paused (0=running, 1=paused)",
+			},
+			[]string{},
+		),
+
+		stuckEvents: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "txstream_outbox_stuck_events",
+				Help: "Number of pending events older than the stuck threshold",
+			},
+			[]string{},
+		),
+
+		escalatedEvents: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "txstream_outbox_escalated_stuck_events",
+				Help: "Number of pending events older than the escalation threshold of the watchdog",
+			},
+		),
+
+		discrepancies: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "txstream_reconciliation_discrepancies",
+				Help: "Number of discrepancies found by the last reconciliation run",
+			},
+			[]string{"type"},
+		),
+
+		sloObjective: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "txstream_slo_delivery_objective",
+				Help: "Ratio of events that must be published within the delivery latency threshold",
+			},
+		),
+
+		sloBudgetRemaining: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "txstream_slo_delivery_error_budget_remaining",
+				Help: "Ratio of the delivery latency error budget left in the SLO window, negative when overspent",
+			},
+		),
+
+		sloBurnRate: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "txstream_slo_delivery_burn_rate",
+				Help: "Rate at which the delivery latency error budget is spent, 1 spends it exactly over the SLO window",
+			},
+			[]string{"window"},
+		),
+
+		consumerLag: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "txstream_consumer_lag",
+				Help: "Messages behind the end of the partition after the last consumed message",
+			},
+			[]string{"topic", "partition"},
+		),
+
+		consumerBuffered: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "txstream_consumer_sequence_buffered",
+				Help: "Events held by the consumer until the missing versions of their aggregate arrive",
+			},
+			[]string{"topic", "partition"},
+		),
 	}
 
-	registry.MustRegister(
+	registerer.MustRegister(
 		metrics.eventsProcessedTotal,
 		metrics.eventsPublishedTotal,
 		metrics.eventsFailedTotal,
 		metrics.eventsRetriedTotal,
+		metrics.eventsExpiredTotal,
+		metrics.eventsThrottledTotal,
+		metrics.eventsAbandonedTotal,
+		metrics.rowsPurgedTotal,
+		metrics.rowsArchivedTotal,
+		metrics.partitionsDropped,
+		metrics.eventsRecoveredTotal,
+		metrics.eventsReplayedTotal,
+		metrics.eventsReconciled,
 		metrics.circuitBreakerTrips,
+		metrics.httpRequestsTotal,
+		metrics.ordersCreatedTotal,
+		metrics.ordersFailedTotal,
+		metrics.dbQueryErrorsTotal,
+		metrics.consumerMessages,
+		metrics.consumerRetries,
+		metrics.consumerSequence,
+		metrics.inboxDuplicates,
+		metrics.inboxRowsPurged,
+		metrics.inboxGroupsApplied,
+		metrics.inboxGroupsAbandoned,
+		metrics.inboxGroupsTimedOut,
 		metrics.eventProcessingDuration,
 		metrics.eventPublishingDuration,
+		metrics.eventDeliveryLatency,
 		metrics.retryDelayDuration,
+		metrics.throttleWaitDuration,
+		metrics.httpRequestDuration,
+		metrics.httpResponseSize,
+		metrics.dbQueryDuration,
+		metrics.consumerDuration,
 		metrics.workerPoolSize,
 		metrics.eventsInQueue,
+		metrics.eventsInLane,
 		metrics.circuitBreakerState,
 		metrics.activeWorkers,
+		metrics.eventsInFlight,
+		metrics.batchSize,
+		metrics.pollInterval,
+		metrics.outboxPartitions,
+		metrics.oldestPendingAge,
+		metrics.stuckEvents,
+		metrics.escalatedEvents,
+		metrics.workerPaused,
+		metrics.discrepancies,
+		metrics.sloObjective,
+		metrics.sloBudgetRemaining,
+		metrics.sloBurnRate,
+		metrics.consumerLag,
+		metrics.consumerBuffered,
 	)
 
 	return metrics
 }
 
-// StartMetricsServer starts the Prometheus metrics server
-func (m *Metrics) StartMetricsServer(port int, path string) error {
-	http.Handle(path, promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
-
-	addr := fmt.Sprintf(":%d", port)
-	go func() {
-		if err := http.ListenAndServe(addr, nil); err != nil {
-			panic(fmt.Sprintf("Failed to start metrics server: %v", err))
-		}
-	}()
-
-	return nil
-}
-
 // EventProcessing methods
 func (m *Metrics) RecordEventProcessed(status, eventType string) {
 	m.eventsProcessedTotal.WithLabelValues(status, eventType).Inc()
@@ -187,6 +593,42 @@ func (m *Metrics) RecordEventRetried(retryCount, eventType string) {
 	m.eventsRetriedTotal.WithLabelValues(retryCount, eventType).Inc()
 }
 
+func (m *Metrics) RecordEventExpired(eventType string) {
+	m.eventsExpiredTotal.WithLabelValues(eventType).Inc()
+}
+
+func (m *Metrics) RecordEventThrottled(topic, eventType string) {
+	m.eventsThrottledTotal.WithLabelValues(topic, eventType).Inc()
+}
+
+func (m *Metrics) RecordEventAbandoned(eventType string) {
+	m.eventsAbandonedTotal.WithLabelValues(eventType).Inc()
+}
+
+func (m *Metrics) RecordRowsPurged(count int) {
+	m.rowsPurgedTotal.WithLabelValues().Add(float64(count))
+}
+
+func (m *Metrics) RecordRowsArchived(count int) {
+	m.rowsArchivedTotal.WithLabelValues().Add(float64(count))
+}
+
+func (m *Metrics) RecordPartitionDropped(action string) {
+	m.partitionsDropped.WithLabelValues(action).Inc()
+}
+
+func (m *Metrics) RecordEventsRecovered(reason string, count int) {
+	m.eventsRecoveredTotal.WithLabelValues(reason).Add(float64(count))
+}
+
+func (m *Metrics) RecordEventReplayed(source string) {
+	m.eventsReplayedTotal.WithLabelValues(source).Inc()
+}
+
+func (m *Metrics) RecordEventReconciled(discrepancyType string) {
+	m.eventsReconciled.WithLabelValues(discrepancyType).Inc()
+}
+
 func (m *Metrics) RecordCircuitBreakerTrip(fromState, toState string) {
 	m.circuitBreakerTrips.WithLabelValues(fromState, toState).Inc()
 }
@@ -200,10 +642,18 @@ func (m *Metrics) RecordEventPublishingDuration(topic, eventType string, duratio
 	m.eventPublishingDuration.WithLabelValues(topic, eventType).Observe(duration.Seconds())
 }
 
+func (m *Metrics) RecordEventDeliveryLatency(topic, eventType string, latency time.Duration) {
+	m.eventDeliveryLatency.WithLabelValues(topic, eventType).Observe(latency.Seconds())
+}
+
 func (m *Metrics) RecordRetryDelayDuration(retryAttempt string, duration time.Duration) {
 	m.retryDelayDuration.WithLabelValues(retryAttempt).Observe(duration.Seconds())
 }
 
+func (m *Metrics) RecordThrottleWaitDuration(topic, eventType string, duration time.Duration) {
+	m.throttleWaitDuration.WithLabelValues(topic, eventType).Observe(duration.Seconds())
+}
+
 // Gauge methods
 func (m *Metrics) SetWorkerPoolSize(size int) {
 	m.workerPoolSize.WithLabelValues().Set(float64(size))
@@ -213,6 +663,10 @@ func (m *Metrics) SetEventsInQueue(status string, count int) {
 	m.eventsInQueue.WithLabelValues(status).Set(float64(count))
 }
 
+func (m *Metrics) SetEventsInLane(lane string, count int) {
+	m.eventsInLane.WithLabelValues(lane).Set(float64(count))
+}
+
 func (m *Metrics) SetCircuitBreakerState(state int) {
 	m.circuitBreakerState.WithLabelValues().Set(float64(state))
 }
@@ -221,6 +675,126 @@ func (m *Metrics) SetActiveWorkers(count int) {
 	m.activeWorkers.WithLabelValues().Set(float64(count))
 }
 
+func (m *Metrics) SetEventsInFlight(count int) {
+	m.eventsInFlight.WithLabelValues().Set(float64(count))
+}
+
+func (m *Metrics) SetBatchSize(size int) {
+	m.batchSize.WithLabelValues().Set(float64(size))
+}
+
+func (m *Metrics) SetPollInterval(interval time.Duration) {
+	m.pollInterval.WithLabelValues().Set(interval.Seconds())
+}
+
+func (m *Metrics) SetOutboxPartitions(count int) {
+	m.outboxPartitions.WithLabelValues().Set(float64(count))
+}
+
+func (m *Metrics) SetOldestPendingAge(age time.Duration) {
+	m.oldestPendingAge.WithLabelValues().Set(age.Seconds())
+}
+
+func (m *Metrics) SetWorkerPaused(paused bool) {
+	value := 0.0
+	if paused {
+		value = 1
+	}
+	m.workerPaused.WithLabelValues().Set(value)
+}
+
+func (m *Metrics) SetStuckEvents(count int) {
+	m.stuckEvents.WithLabelValues().Set(float64(count))
+}
+
+func (m *Metrics) SetEscalatedStuckEvents(count int) {
+	m.escalatedEvents.Set(float64(count))
+}
+
+func (m *Metrics) SetDiscrepancies(discrepancyType string, count int) {
+	m.discrepancies.WithLabelValues(discrepancyType).Set(float64(count))
+}
+
+func (m *Metrics) SetSLOObjective(objective float64) {
+	m.sloObjective.Set(objective)
+}
+
+func (m *Metrics) SetSLOErrorBudgetRemaining(remaining float64) {
+	m.sloBudgetRemaining.Set(remaining)
+}
+
+func (m *Metrics) SetSLOBurnRate(window string, rate float64) {
+	m.sloBurnRate.WithLabelValues(window).Set(rate)
+}
+
+// HTTP API methods
+func (m *Metrics) RecordHTTPRequest(method, route string, status int, duration time.Duration, responseSize int64) {
+	statusLabel := strconv.Itoa(status)
+	m.httpRequestsTotal.WithLabelValues(method, route, statusLabel).Inc()
+	m.httpRequestDuration.WithLabelValues(method, route, statusLabel).Observe(duration.Seconds())
+	m.httpResponseSize.WithLabelValues(method, route, statusLabel).Observe(float64(responseSize))
+}
+
+func (m *Metrics) RecordOrderCreated() {
+	m.ordersCreatedTotal.WithLabelValues().Inc()
+}
+
+func (m *Metrics) RecordOrderFailed(reason string) {
+	m.ordersFailedTotal.WithLabelValues(reason).Inc()
+}
+
+// Database methods
+func (m *Metrics) RecordDBQuery(operation, table string, duration time.Duration) {
+	m.dbQueryDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
+}
+
+func (m *Metrics) RecordDBQueryError(operation, table string) {
+	m.dbQueryErrorsTotal.WithLabelValues(operation, table).Inc()
+}
+
+// Consumer methods
+func (m *Metrics) RecordConsumerMessage(topic, eventType, outcome string, duration time.Duration) {
+	m.consumerMessages.WithLabelValues(topic, eventType, outcome).Inc()
+	m.consumerDuration.WithLabelValues(topic, eventType).Observe(duration.Seconds())
+}
+
+func (m *Metrics) RecordConsumerRetry(topic, eventType string) {
+	m.consumerRetries.WithLabelValues(topic, eventType).Inc()
+}
+
+func (m *Metrics) SetConsumerLag(topic string, partition int32, lag int64) {
+	m.consumerLag.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(lag))
+}
+
+func (m *Metrics) RecordConsumerSequence(topic, eventType, status string) {
+	m.consumerSequence.WithLabelValues(topic, eventType, status).Inc()
+}
+
+func (m *Metrics) SetConsumerSequenceBuffered(topic string, partition int32, buffered int) {
+	m.consumerBuffered.WithLabelValues(topic, strconv.Itoa(int(partition))).Set(float64(buffered))
+}
+
+// Inbox methods
+func (m *Metrics) RecordInboxDuplicate(consumerGroup, eventType string) {
+	m.inboxDuplicates.WithLabelValues(consumerGroup, eventType).Inc()
+}
+
+func (m *Metrics) RecordInboxRowsPurged(count int) {
+	m.inboxRowsPurged.WithLabelValues().Add(float64(count))
+}
+
+func (m *Metrics) RecordInboxGroupApplied(consumerGroup string) {
+	m.inboxGroupsApplied.WithLabelValues(consumerGroup).Inc()
+}
+
+func (m *Metrics) RecordInboxGroupEventsAbandoned(count int) {
+	m.inboxGroupsAbandoned.WithLabelValues().Add(float64(count))
+}
+
+func (m *Metrics) RecordInboxGroupTimedOut(consumerGroup string) {
+	m.inboxGroupsTimedOut.WithLabelValues(consumerGroup).Inc()
+}
+
 // Timer helper for measuring durations
 func (m *Metrics) Timer() *Timer {
 	return &Timer{
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+)
+
+// NewRegistry creates the registry shared by every component of a process, with the
+// Go runtime and process collectors already registered
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// RegisterDBStats registers the connection pool statistics of db, labeled with dbName
+func RegisterDBStats(registerer prometheus.Registerer, db *sql.DB, dbName string) error {
+	if err := registerer.Register(collectors.NewDBStatsCollector(db, dbName)); err != nil {
+		return fmt.Errorf("failed to register database pool collector: %w", err)
+	}
+	return nil
+}
+
+// NewServer creates the HTTP server exposing the metrics gathered by registry, plus the
+// pprof endpoints when enabled. The caller starts it and shuts it down.
+func NewServer(cfg config.MetricsConfig, registry *prometheus.Registry) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, promhttp.InstrumentMetricHandler(
+		registry, promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}),
+	))
+
+	if cfg.PprofEnabled {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+
+	return &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		// profiles and traces stream for the requested number of seconds
+		WriteTimeout: 5 * time.Minute,
+		IdleTimeout:  60 * time.Second,
+	}
+}
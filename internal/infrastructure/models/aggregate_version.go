@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AggregateVersion is the version of the last event written for an aggregate. It outlives
+// the outbox rows, which are purged by the retention, so versions are never reused.
+type AggregateVersion struct {
+	AggregateID string    `gorm:"type:varchar(255);primary_key" json:"aggregate_id"`
+	Version     int64     `gorm:"not null;default:0" json:"version"`
+	UpdatedAt   time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName returns the table name for AggregateVersion
+func (AggregateVersion) TableName() string {
+	return "aggregate_versions"
+}
+
+// NextAggregateVersion increments and returns the version of the aggregate in the transaction
+// of tx. The row stays locked until the transaction ends, so concurrent writers of the same
+// aggregate are serialized, and a rollback releases the version without leaving a gap.
+func NextAggregateVersion(tx *gorm.DB, aggregateID string) (int64, error) {
+	var version int64
+	err := tx.Session(&gorm.Session{NewDB: true}).Raw(`
+		INSERT INTO aggregate_versions (aggregate_id, version, updated_at)
+		VALUES (?, 1, NOW())
+		ON CONFLICT (aggregate_id) DO UPDATE
+		SET version = aggregate_versions.version + 1, updated_at = NOW()
+		RETURNING version`, aggregateID).Scan(&version).Error
+
+	return version, err
+}
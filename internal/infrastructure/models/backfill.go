@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BackfillCheckpoint is the progress of a backfill job, identified by the job name.
+// CursorCreatedAt and CursorID identify the last row of the walked table processed in the
+// current pass, both are nil before the first page.
+type BackfillCheckpoint struct {
+	Name            string     `gorm:"type:varchar(100);primary_key" json:"name"`
+	CursorCreatedAt *time.Time `json:"cursor_created_at,omitempty"`
+	CursorID        *uuid.UUID `gorm:"type:uuid" json:"cursor_id,omitempty"`
+	Processed       int64      `gorm:"not null;default:0" json:"processed"`
+	Created         int64      `gorm:"not null;default:0" json:"created"`
+	StartedAt       time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"started_at"`
+	UpdatedAt       time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+}
+
+// TableName returns the table name for BackfillCheckpoint
+func (BackfillCheckpoint) TableName() string {
+	return "backfill_checkpoints"
+}
+
+// BackfillOrderSnapshot records an order version the snapshot backfill wrote an event for.
+// The version is the order UpdatedAt, so each version is snapshotted once, also after its
+// outbox event was purged by the retention.
+type BackfillOrderSnapshot struct {
+	OrderID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"order_id"`
+	OrderUpdatedAt time.Time `gorm:"primaryKey" json:"order_updated_at"`
+	EventID        uuid.UUID `gorm:"type:uuid;not null" json:"event_id"`
+	CreatedAt      time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName returns the table name for BackfillOrderSnapshot
+func (BackfillOrderSnapshot) TableName() string {
+	return "backfill_order_snapshots"
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// InboxEntry records that a consumer group processed an event, identified by the event_id
+// header. The entry is written in the transaction of the handler writes, so an event is
+// either processed and recorded or neither.
+type InboxEntry struct {
+	ConsumerGroup string    `gorm:"type:varchar(255);primary_key" json:"consumer_group"`
+	EventID       string    `gorm:"type:varchar(255);primary_key" json:"event_id"`
+	EventType     string    `gorm:"type:varchar(100);not null" json:"event_type"`
+	AggregateID   string    `gorm:"type:varchar(255)" json:"aggregate_id,omitempty"`
+	Topic         string    `gorm:"type:varchar(255)" json:"topic,omitempty"`
+	Partition     int32     `json:"partition"`
+	Offset        int64     `json:"offset"`
+	ProcessedAt   time.Time `gorm:"not null;default:CURRENT_TIMESTAMP;index" json:"processed_at"`
+}
+
+// TableName returns the table name for InboxEntry
+func (InboxEntry) TableName() string {
+	return "inbox"
+}
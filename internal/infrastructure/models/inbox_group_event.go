@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// InboxGroupEvent is an event received by a consumer group and held until the other events
+// written by the same transaction are received, so the transaction is applied as a whole
+type InboxGroupEvent struct {
+	ConsumerGroup string    `gorm:"type:varchar(255);primary_key" json:"consumer_group"`
+	TransactionID string    `gorm:"type:varchar(255);primary_key" json:"transaction_id"`
+	EventID       string    `gorm:"type:varchar(255);primary_key" json:"event_id"`
+	EventCount    int       `gorm:"not null" json:"event_count"`
+	Event         string    `gorm:"type:jsonb;not null" json:"event"`
+	ReceivedAt    time.Time `gorm:"not null;default:CURRENT_TIMESTAMP;index" json:"received_at"`
+}
+
+// TableName returns the table name for InboxGroupEvent
+func (InboxGroupEvent) TableName() string {
+	return "inbox_group_events"
+}
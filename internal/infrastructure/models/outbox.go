@@ -7,21 +7,47 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/correlation"
 )
 
+// OutboxEvent is a row of the outbox table. When the table is partitioned, CreatedAt is
+// the partition key and part of the primary key, so it must never change after insert.
 type OutboxEvent struct {
-	ID            uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	AggregateID   string         `gorm:"not null;index" json:"aggregate_id"`
-	AggregateType string         `gorm:"type:varchar(100);not null;index" json:"aggregate_type"`
-	EventType     string         `gorm:"type:varchar(100);not null" json:"event_type"`
-	EventData     JSON           `gorm:"type:jsonb;not null" json:"event_data"`
-	EventMetadata JSON           `gorm:"type:jsonb" json:"event_metadata,omitempty"`
-	Status        OutboxStatus   `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
-	CreatedAt     time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP;index" json:"created_at"`
-	PublishedAt   *time.Time     `gorm:"index" json:"published_at,omitempty"`
-	RetryCount    int            `gorm:"not null;default:0" json:"retry_count"`
-	ErrorMessage  string         `gorm:"type:text" json:"error_message,omitempty"`
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	AggregateID   string    `gorm:"not null;index" json:"aggregate_id"`
+	AggregateType string    `gorm:"type:varchar(100);not null;index" json:"aggregate_type"`
+	EventType     string    `gorm:"type:varchar(100);not null" json:"event_type"`
+	EventData     JSON      `gorm:"type:jsonb;not null" json:"event_data"`
+	EventMetadata JSON      `gorm:"type:jsonb" json:"event_metadata,omitempty"`
+	// AggregateVersion is the position of the event in the sequence of its aggregate, assigned
+	// on insert; zero for the events written before versioning
+	AggregateVersion int64 `gorm:"not null;default:0" json:"aggregate_version"`
+	// CorrelationID is shared by the events descending from the same original request, and
+	// CausationID is the ID of the request or event that caused this one
+	CorrelationID string `gorm:"type:varchar(255);index" json:"correlation_id,omitempty"`
+	CausationID   string `gorm:"type:varchar(255);index" json:"causation_id,omitempty"`
+	// OriginalEventID is set on the redelivery of an event missing from Kafka, which is
+	// published with the ID of the original so consumers deduplicate it
+	OriginalEventID *uuid.UUID `gorm:"type:uuid" json:"original_event_id,omitempty"`
+	// TransactionID identifies the database transaction that wrote the event, and
+	// TransactionEventCount is the number of events it wrote, set when the event is claimed
+	TransactionID         *uuid.UUID     `gorm:"type:uuid;index" json:"transaction_id,omitempty"`
+	TransactionEventCount int            `gorm:"not null;default:0" json:"transaction_event_count"`
+	Status                OutboxStatus   `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Priority              OutboxPriority `gorm:"type:smallint;not null;default:0;index" json:"priority"`
+	CreatedAt             time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP;index" json:"created_at"`
+	PublishedAt           *time.Time     `gorm:"index" json:"published_at,omitempty"`
+	RetryCount            int            `gorm:"not null;default:0" json:"retry_count"`
+	ErrorMessage          string         `gorm:"type:text" json:"error_message,omitempty"`
+	ExpiresAt             *time.Time     `gorm:"index" json:"expires_at,omitempty"`
+	ClaimedBy             string         `gorm:"type:varchar(255)" json:"claimed_by,omitempty"`
+	ClaimedUntil          *time.Time     `gorm:"index" json:"claimed_until,omitempty"`
+	// KafkaTopic, KafkaPartition and KafkaOffset are the delivery receipt of a published event
+	KafkaTopic     string         `gorm:"type:varchar(255)" json:"kafka_topic,omitempty"`
+	KafkaPartition *int32         `json:"kafka_partition,omitempty"`
+	KafkaOffset    *int64         `json:"kafka_offset,omitempty"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 type OutboxStatus string
@@ -30,8 +56,69 @@ const (
 	OutboxStatusPending   OutboxStatus = "pending"
 	OutboxStatusPublished OutboxStatus = "published"
 	OutboxStatusFailed    OutboxStatus = "failed"
+	OutboxStatusExpired   OutboxStatus = "expired"
+	// OutboxStatusDeadLetter parks an event set aside by an operator, it is never retried automatically
+	OutboxStatusDeadLetter OutboxStatus = "dead_letter"
+)
+
+// OutboxStatuses lists every outbox event status
+var OutboxStatuses = []OutboxStatus{
+	OutboxStatusPending,
+	OutboxStatusPublished,
+	OutboxStatusFailed,
+	OutboxStatusExpired,
+	OutboxStatusDeadLetter,
+}
+
+// IsValid returns true if the status is a known outbox status
+func (s OutboxStatus) IsValid() bool {
+	for _, status := range OutboxStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// OutboxPriority defines the priority lane of an outbox event
+type OutboxPriority int
+
+const (
+	OutboxPriorityLow    OutboxPriority = -1
+	OutboxPriorityNormal OutboxPriority = 0
+	OutboxPriorityHigh   OutboxPriority = 1
 )
 
+// OutboxPriorities lists the priority lanes from the highest to the lowest
+var OutboxPriorities = []OutboxPriority{
+	OutboxPriorityHigh,
+	OutboxPriorityNormal,
+	OutboxPriorityLow,
+}
+
+func (p OutboxPriority) String() string {
+	switch p {
+	case OutboxPriorityHigh:
+		return "high"
+	case OutboxPriorityNormal:
+		return "normal"
+	case OutboxPriorityLow:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseOutboxPriority parses a priority lane name
+func ParseOutboxPriority(name string) (OutboxPriority, error) {
+	for _, priority := range OutboxPriorities {
+		if priority.String() == name {
+			return priority, nil
+		}
+	}
+	return OutboxPriorityNormal, fmt.Errorf("invalid priority lane: %s", name)
+}
+
 type JSON map[string]interface{}
 
 // Value implements the driver.Valuer interface
@@ -59,11 +146,23 @@ func (j *JSON) Scan(value interface{}) error {
 	}
 }
 
+// MessageID returns the event ID carried by the published message, the ID of the original
+// event for a redelivery and the event ID otherwise
+func (oe *OutboxEvent) MessageID() uuid.UUID {
+	if oe.OriginalEventID != nil {
+		return *oe.OriginalEventID
+	}
+	return oe.ID
+}
+
 func (OutboxEvent) TableName() string {
 	return "outbox"
 }
 
-// BeforeCreate hook to generate UUID if not provided
+// BeforeCreate hook to generate UUID if not provided, assign the next version of the
+// aggregate and the ID of the transaction writing the event, and correlate the event with
+// the request or event in the statement context. An event written outside of any
+// correlation starts its own, with its ID as correlation ID.
 func (oe *OutboxEvent) BeforeCreate(tx *gorm.DB) error {
 	if oe.ID == uuid.Nil {
 		oe.ID = uuid.New()
@@ -76,6 +175,46 @@ func (oe *OutboxEvent) BeforeCreate(tx *gorm.DB) error {
 		return err
 	}
 
+	if oe.AggregateVersion == 0 {
+		version, err := NextAggregateVersion(tx, oe.AggregateID)
+		if err != nil {
+			return fmt.Errorf("failed to assign aggregate version: %w", err)
+		}
+		oe.AggregateVersion = version
+	}
+
+	if oe.TransactionID == nil {
+		transactionID, err := CurrentTransactionID(tx)
+		if err != nil {
+			return fmt.Errorf("failed to assign transaction id: %w", err)
+		}
+		oe.TransactionID = &transactionID
+	}
+
+	ids := correlation.FromContext(tx.Statement.Context)
+	if oe.CorrelationID == "" {
+		oe.CorrelationID = ids.CorrelationID
+	}
+	if oe.CorrelationID == "" {
+		oe.CorrelationID = oe.ID.String()
+	}
+	if oe.CausationID == "" {
+		oe.CausationID = ids.CausationID
+	}
+
+	return nil
+}
+
+// AfterCreate hook to count the event in the events written by its transaction
+func (oe *OutboxEvent) AfterCreate(tx *gorm.DB) error {
+	if oe.TransactionID == nil {
+		return nil
+	}
+
+	if _, err := CountTransactionEvent(tx, *oe.TransactionID); err != nil {
+		return fmt.Errorf("failed to count transaction events: %w", err)
+	}
+
 	return nil
 }
 
@@ -110,6 +249,31 @@ func (oe *OutboxEvent) MarkAsFailed(errorMsg string) {
 	oe.RetryCount++
 }
 
+// MarkAsExpired marks the event as expired so it is never published
+func (oe *OutboxEvent) MarkAsExpired() {
+	oe.Status = OutboxStatusExpired
+}
+
+// SetTTL defines the event expiry relative to its creation date
+func (oe *OutboxEvent) SetTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	createdAt := oe.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	expiresAt := createdAt.Add(ttl)
+	oe.ExpiresAt = &expiresAt
+}
+
+// IsExpired checks if the event expiry has been reached
+func (oe *OutboxEvent) IsExpired(now time.Time) bool {
+	return oe.ExpiresAt != nil && !now.Before(*oe.ExpiresAt)
+}
+
 // ResetForRetry resets the event for a new attempt
 func (oe *OutboxEvent) ResetForRetry() {
 	oe.Status = OutboxStatusPending
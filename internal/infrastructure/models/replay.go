@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReplayStatus string
+
+const (
+	ReplayStatusRunning   ReplayStatus = "running"
+	ReplayStatusCompleted ReplayStatus = "completed"
+	ReplayStatusFailed    ReplayStatus = "failed"
+	ReplayStatusCancelled ReplayStatus = "cancelled"
+)
+
+// Replay sources, archived events are replayed before the events still in the outbox
+const (
+	ReplaySourceOutbox  = "outbox"
+	ReplaySourceArchive = "archive"
+	ReplaySourceAll     = "all"
+)
+
+// ReplayJob is a replay of historical events with its selection and progress checkpoint.
+// The checkpoint is the position after the last replayed event: an archive segment and
+// the number of its events already replayed, or the outbox (created_at, id) keyset.
+type ReplayJob struct {
+	ID            uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Status        ReplayStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	Source        string       `gorm:"type:varchar(20);not null" json:"source"`
+	AggregateID   string       `gorm:"type:varchar(255)" json:"aggregate_id,omitempty"`
+	AggregateType string       `gorm:"type:varchar(100)" json:"aggregate_type,omitempty"`
+	EventType     string       `gorm:"type:varchar(100)" json:"event_type,omitempty"`
+	CreatedAfter  *time.Time   `json:"created_after,omitempty"`
+	CreatedBefore *time.Time   `json:"created_before,omitempty"`
+	Topic         string       `gorm:"type:varchar(255)" json:"topic,omitempty"`
+	RateLimit     float64      `gorm:"not null;default:0" json:"rate_limit"`
+
+	CheckpointSource    string     `gorm:"type:varchar(20)" json:"checkpoint_source,omitempty"`
+	CheckpointSegment   string     `gorm:"type:varchar(255)" json:"checkpoint_segment,omitempty"`
+	CheckpointOffset    int        `gorm:"not null;default:0" json:"checkpoint_offset,omitempty"`
+	CheckpointCreatedAt *time.Time `json:"checkpoint_created_at,omitempty"`
+	CheckpointEventID   *uuid.UUID `gorm:"type:uuid" json:"checkpoint_event_id,omitempty"`
+
+	Replayed     int64      `gorm:"not null;default:0" json:"replayed"`
+	ErrorMessage string     `gorm:"type:text" json:"error_message,omitempty"`
+	HeartbeatAt  time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"heartbeat_at"`
+	CreatedAt    time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
+
+// TableName returns the table name for ReplayJob
+func (ReplayJob) TableName() string {
+	return "replay_jobs"
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OutboxTransaction is the number of outbox events written by a database transaction, kept
+// on a single row so writing an event costs one update whatever the size of the transaction
+type OutboxTransaction struct {
+	TransactionID uuid.UUID `gorm:"type:uuid;primary_key" json:"transaction_id"`
+	EventCount    int       `gorm:"not null;default:0" json:"event_count"`
+	CreatedAt     time.Time `gorm:"not null;default:CURRENT_TIMESTAMP;index" json:"created_at"`
+}
+
+// TableName returns the table name for OutboxTransaction
+func (OutboxTransaction) TableName() string {
+	return "outbox_transactions"
+}
+
+// CurrentTransactionID returns the ID of the database transaction of tx, the same for every
+// statement of the transaction. It is derived from the transaction ID and start time, so it
+// is not reused when the database transaction IDs restart.
+func CurrentTransactionID(tx *gorm.DB) (uuid.UUID, error) {
+	var id string
+	err := tx.Session(&gorm.Session{NewDB: true}).
+		Raw(`SELECT md5(pg_current_xact_id()::text || '/' || now()::text)::uuid`).
+		Scan(&id).Error
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return uuid.Parse(id)
+}
+
+// CountTransactionEvent increments and returns the number of outbox events written so far by
+// the transaction. The count is final once the transaction commits, and is copied onto each
+// event when it is claimed for publication.
+func CountTransactionEvent(tx *gorm.DB, transactionID uuid.UUID) (int, error) {
+	var count int
+	err := tx.Session(&gorm.Session{NewDB: true}).Raw(`
+		INSERT INTO outbox_transactions (transaction_id, event_count, created_at)
+		VALUES (?, 1, NOW())
+		ON CONFLICT (transaction_id) DO UPDATE
+		SET event_count = outbox_transactions.event_count + 1
+		RETURNING event_count`, transactionID).Scan(&count).Error
+
+	return count, err
+}
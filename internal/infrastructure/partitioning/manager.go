@@ -0,0 +1,420 @@
+package partitioning
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+)
+
+const (
+	tableName       = "outbox"
+	legacyTableName = "outbox_legacy"
+)
+
+// ErrNotPartitioned is returned when the outbox table has not been converted to a partitioned table
+var ErrNotPartitioned = fmt.Errorf("outbox table is not partitioned")
+
+// legacyIndex is an index of the outbox table before the conversion
+type legacyIndex struct {
+	Name       string `gorm:"column:indexname"`
+	Definition string `gorm:"column:indexdef"`
+}
+
+// MaintenanceResult summarizes a maintenance run
+type MaintenanceResult struct {
+	Created  []string
+	Dropped  []string
+	Detached []string
+	Skipped  []string
+}
+
+// Manager creates the future partitions of the outbox table ahead of time and
+// drops or detaches the partitions older than the retention period
+type Manager struct {
+	config   config.PartitioningConfig
+	db       *gorm.DB
+	metrics  *metrics.Metrics
+	logger   *slog.Logger
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewManager creates a new partition Manager
+func NewManager(cfg config.PartitioningConfig, db *gorm.DB, metrics *metrics.Metrics, logger *slog.Logger) *Manager {
+	return &Manager{
+		config:   cfg,
+		db:       db,
+		metrics:  metrics,
+		logger:   logging.OrDefault(logger),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start runs the maintenance immediately and then periodically until the context is cancelled or Stop is called
+func (m *Manager) Start(ctx context.Context) {
+	m.logger.InfoContext(ctx, "Starting partition manager",
+		"granularity", m.config.Granularity,
+		"premake", m.config.Premake,
+		"retention", m.config.Retention,
+		"detach_only", m.config.DetachOnly)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(m.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := m.Maintain(ctx); err != nil {
+				m.logger.ErrorContext(ctx, "Partition maintenance failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-m.stopChan:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic maintenance
+func (m *Manager) Stop() {
+	close(m.stopChan)
+	m.wg.Wait()
+}
+
+// IsPartitioned returns true if the outbox table is a partitioned table
+func (m *Manager) IsPartitioned(ctx context.Context) (bool, error) {
+	return isPartitioned(m.db.WithContext(ctx))
+}
+
+// Partitions returns the partitions attached to the outbox table ordered by range
+func (m *Manager) Partitions(ctx context.Context) ([]Partition, error) {
+	return listPartitions(m.db.WithContext(ctx))
+}
+
+// Maintain creates the missing partitions for the next premake periods and
+// drops or detaches the partitions entirely older than the retention period
+func (m *Manager) Maintain(ctx context.Context) (*MaintenanceResult, error) {
+	db := m.db.WithContext(ctx)
+
+	partitioned, err := isPartitioned(db)
+	if err != nil {
+		return nil, err
+	}
+	if !partitioned {
+		return nil, ErrNotPartitioned
+	}
+
+	result := &MaintenanceResult{}
+	now := time.Now()
+
+	created, err := m.createPartitions(db, now)
+	result.Created = created
+	if err != nil {
+		return result, err
+	}
+
+	if m.config.Retention > 0 {
+		if err := m.expirePartitions(db, now.Add(-m.config.Retention), result); err != nil {
+			return result, err
+		}
+	}
+
+	partitions, err := listPartitions(db)
+	if err != nil {
+		return result, err
+	}
+	if m.metrics != nil {
+		m.metrics.SetOutboxPartitions(len(partitions))
+	}
+
+	return result, nil
+}
+
+// Convert converts an existing unpartitioned outbox table into a partitioned table.
+// The existing table is kept as the legacy partition covering everything up to the end
+// of the current period, so no rows are copied; it is dropped by the retention like any
+// other partition once all its rows are older than the retention period.
+// Returns false if the table is already partitioned.
+func (m *Manager) Convert(ctx context.Context) (bool, error) {
+	converted := false
+
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		partitioned, err := isPartitioned(tx)
+		if err != nil {
+			return err
+		}
+		if partitioned {
+			return nil
+		}
+
+		if err := tx.Exec("LOCK TABLE outbox IN ACCESS EXCLUSIVE MODE").Error; err != nil {
+			return fmt.Errorf("failed to lock outbox table: %w", err)
+		}
+
+		var latest struct {
+			CreatedAt *time.Time
+		}
+		if err := tx.Raw("SELECT MAX(created_at) AS created_at FROM outbox").Scan(&latest).Error; err != nil {
+			return fmt.Errorf("failed to read latest event: %w", err)
+		}
+
+		// The legacy partition must hold every existing row, including those written
+		// during the current period, so its range ends at the start of the next one
+		now := time.Now()
+		if latest.CreatedAt != nil && latest.CreatedAt.After(now) {
+			now = *latest.CreatedAt
+		}
+		boundary := NextPeriod(PeriodStart(now, m.config.Granularity), m.config.Granularity)
+
+		statements := []string{
+			fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tableName, legacyTableName),
+			fmt.Sprintf("UPDATE %s SET created_at = NOW() WHERE created_at IS NULL", legacyTableName),
+			fmt.Sprintf("ALTER TABLE %s ALTER COLUMN created_at SET NOT NULL", legacyTableName),
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("failed to prepare legacy table: %w", err)
+			}
+		}
+
+		indexes, err := renameLegacyIndexes(tx)
+		if err != nil {
+			return err
+		}
+
+		statements = []string{
+			fmt.Sprintf("CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING COMMENTS) PARTITION BY RANGE (created_at)",
+				tableName, legacyTableName),
+			// The partition key must be part of the primary key of a partitioned table
+			fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (id, created_at)", tableName),
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("failed to create partitioned table: %w", err)
+			}
+		}
+
+		// Recreate the indexes of the legacy table, whatever migration or AutoMigrate created
+		// them, so the partitioned table and every partition inherit the same set
+		for _, index := range indexes {
+			statement, ok := PartitionedIndex(index.Name, index.Definition)
+			if !ok {
+				continue
+			}
+			if err := tx.Exec(statement).Error; err != nil {
+				return fmt.Errorf("failed to create index %s: %w", index.Name, err)
+			}
+		}
+
+		attach := fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM (MINVALUE) TO ('%s')",
+			tableName, legacyTableName, boundary.Format(time.RFC3339))
+		if err := tx.Exec(attach).Error; err != nil {
+			return fmt.Errorf("failed to attach legacy partition: %w", err)
+		}
+
+		if _, err := m.createPartitions(tx, boundary); err != nil {
+			return err
+		}
+
+		converted = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if converted {
+		m.logger.InfoContext(ctx, "Converted outbox table to partitions", "granularity", m.config.Granularity)
+	}
+	return converted, nil
+}
+
+// createPartitions creates the partitions for the premake periods starting at the period containing from
+func (m *Manager) createPartitions(db *gorm.DB, from time.Time) ([]string, error) {
+	existing, err := listPartitions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []string
+	start := PeriodStart(from, m.config.Granularity)
+	for i := 0; i < m.config.Premake; i++ {
+		partition := PartitionFor(start, m.config.Granularity)
+		start = *partition.To
+
+		if overlaps(existing, partition) {
+			continue
+		}
+
+		statement := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')",
+			partition.Name, tableName, partition.From.Format(time.RFC3339), partition.To.Format(time.RFC3339))
+		if err := db.Exec(statement).Error; err != nil {
+			return created, fmt.Errorf("failed to create partition %s: %w", partition.Name, err)
+		}
+
+		m.logger.Info("Created outbox partition", "partition", partition.Name)
+		created = append(created, partition.Name)
+	}
+
+	return created, nil
+}
+
+// expirePartitions drops or detaches the partitions whose whole range is before the cutoff.
+// Partitions still holding pending or failed events are kept so no event is lost unpublished.
+func (m *Manager) expirePartitions(db *gorm.DB, cutoff time.Time, result *MaintenanceResult) error {
+	partitions, err := listPartitions(db)
+	if err != nil {
+		return err
+	}
+
+	for _, partition := range partitions {
+		if partition.To == nil || partition.To.After(cutoff) {
+			continue
+		}
+
+		var unfinished int64
+		query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE status IN (?, ?)", partition.Name)
+		if err := db.Raw(query, models.OutboxStatusPending, models.OutboxStatusFailed).Scan(&unfinished).Error; err != nil {
+			return fmt.Errorf("failed to check partition %s: %w", partition.Name, err)
+		}
+		if unfinished > 0 {
+			m.logger.Warn("Keeping expired outbox partition with events not published yet", "partition", partition.Name, "unpublished", unfinished)
+			result.Skipped = append(result.Skipped, partition.Name)
+			continue
+		}
+
+		if err := db.Exec(fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", tableName, partition.Name)).Error; err != nil {
+			return fmt.Errorf("failed to detach partition %s: %w", partition.Name, err)
+		}
+
+		if m.config.DetachOnly {
+			m.logger.Info("Detached outbox partition", "partition", partition.Name)
+			result.Detached = append(result.Detached, partition.Name)
+			m.recordDropped("detach")
+			continue
+		}
+
+		if err := db.Exec(fmt.Sprintf("DROP TABLE %s", partition.Name)).Error; err != nil {
+			return fmt.Errorf("failed to drop partition %s: %w", partition.Name, err)
+		}
+
+		m.logger.Info("Dropped outbox partition", "partition", partition.Name)
+		result.Dropped = append(result.Dropped, partition.Name)
+		m.recordDropped("drop")
+	}
+
+	return nil
+}
+
+func (m *Manager) recordDropped(action string) {
+	if m.metrics != nil {
+		m.metrics.RecordPartitionDropped(action)
+	}
+}
+
+// isPartitioned returns true if the outbox table is a partitioned table
+func isPartitioned(db *gorm.DB) (bool, error) {
+	var partitioned bool
+	err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = to_regclass(?))", tableName).
+		Scan(&partitioned).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check outbox partitioning: %w", err)
+	}
+	return partitioned, nil
+}
+
+// listPartitions returns the partitions attached to the outbox table ordered by range
+func listPartitions(db *gorm.DB) ([]Partition, error) {
+	var rows []struct {
+		Name  string
+		Bound string
+	}
+	err := db.Raw(`SELECT c.relname AS name, pg_get_expr(c.relpartbound, c.oid) AS bound
+		FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = to_regclass(?)`, tableName).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox partitions: %w", err)
+	}
+
+	partitions := make([]Partition, 0, len(rows))
+	for _, row := range rows {
+		from, to, err := ParseBound(row.Bound)
+		if err != nil {
+			return nil, fmt.Errorf("partition %s: %w", row.Name, err)
+		}
+		partitions = append(partitions, Partition{Name: row.Name, From: from, To: to})
+	}
+
+	sort.Slice(partitions, func(i, j int) bool {
+		if partitions[i].From == nil || partitions[j].From == nil {
+			return partitions[i].From == nil && partitions[j].From != nil
+		}
+		return partitions[i].From.Before(*partitions[j].From)
+	})
+
+	return partitions, nil
+}
+
+// renameLegacyIndexes prefixes the indexes of the legacy table with legacy_, freeing their
+// names for the partitioned table, and returns their original names and definitions
+func renameLegacyIndexes(tx *gorm.DB) ([]legacyIndex, error) {
+	var indexes []legacyIndex
+	err := tx.Raw("SELECT indexname, indexdef FROM pg_indexes WHERE schemaname = current_schema() AND tablename = ? ORDER BY indexname", legacyTableName).
+		Scan(&indexes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list legacy indexes: %w", err)
+	}
+
+	for _, index := range indexes {
+		statement := fmt.Sprintf("ALTER INDEX %s RENAME TO legacy_%s", index.Name, index.Name)
+		if err := tx.Exec(statement).Error; err != nil {
+			return nil, fmt.Errorf("failed to rename index %s: %w", index.Name, err)
+		}
+	}
+	return indexes, nil
+}
+
+// PartitionedIndex returns the statement creating an index of the legacy table, given its
+// pg_indexes definition, on the partitioned table. Unique indexes, including the primary key,
+// are skipped: a partitioned table only enforces uniqueness on keys containing created_at.
+func PartitionedIndex(name, definition string) (string, bool) {
+	if strings.HasPrefix(definition, "CREATE UNIQUE INDEX") {
+		return "", false
+	}
+
+	_, method, found := strings.Cut(definition, " USING ")
+	if !found {
+		return "", false
+	}
+	return fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s USING %s", name, tableName, method), true
+}
+
+// overlaps returns true if the partition range intersects an existing partition
+func overlaps(existing []Partition, partition Partition) bool {
+	for _, other := range existing {
+		if other.Covers(*partition.From) {
+			return true
+		}
+		if other.From != nil && !other.From.Before(*partition.From) && other.From.Before(*partition.To) {
+			return true
+		}
+	}
+	return false
+}
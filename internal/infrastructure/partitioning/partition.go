@@ -0,0 +1,99 @@
+package partitioning
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+)
+
+// Partition describes a range partition of the outbox table.
+// A nil From means the partition starts at MINVALUE, like the legacy partition created on conversion.
+type Partition struct {
+	Name string
+	From *time.Time
+	To   *time.Time
+}
+
+// Covers returns true if the partition contains the given instant
+func (p Partition) Covers(t time.Time) bool {
+	if p.From != nil && t.Before(*p.From) {
+		return false
+	}
+	if p.To != nil && !t.Before(*p.To) {
+		return false
+	}
+	return true
+}
+
+// PeriodStart returns the start of the partition period containing t, in UTC.
+// Weekly partitions start on Monday.
+func PeriodStart(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if granularity == config.PartitionGranularityWeek {
+		offset := (int(start.Weekday()) + 6) % 7
+		start = start.AddDate(0, 0, -offset)
+	}
+	return start
+}
+
+// NextPeriod returns the start of the period following the one starting at start
+func NextPeriod(start time.Time, granularity string) time.Time {
+	if granularity == config.PartitionGranularityWeek {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// PartitionFor returns the partition of the period containing t
+func PartitionFor(t time.Time, granularity string) Partition {
+	from := PeriodStart(t, granularity)
+	to := NextPeriod(from, granularity)
+	return Partition{
+		Name: fmt.Sprintf("%s_p%s", tableName, from.Format("20060102")),
+		From: &from,
+		To:   &to,
+	}
+}
+
+var boundPattern = regexp.MustCompile(`^FOR VALUES FROM \((.+)\) TO \((.+)\)$`)
+
+var boundLayouts = []string{
+	"2006-01-02 15:04:05.999999-07",
+	"2006-01-02 15:04:05.999999-07:00",
+	"2006-01-02 15:04:05.999999",
+}
+
+// ParseBound parses a partition bound expression as returned by pg_get_expr
+func ParseBound(expr string) (from, to *time.Time, err error) {
+	matches := boundPattern.FindStringSubmatch(strings.TrimSpace(expr))
+	if matches == nil {
+		return nil, nil, fmt.Errorf("unsupported partition bound: %s", expr)
+	}
+
+	if from, err = parseBoundValue(matches[1]); err != nil {
+		return nil, nil, err
+	}
+	if to, err = parseBoundValue(matches[2]); err != nil {
+		return nil, nil, err
+	}
+	return from, to, nil
+}
+
+func parseBoundValue(value string) (*time.Time, error) {
+	if value == "MINVALUE" || value == "MAXVALUE" {
+		return nil, nil
+	}
+
+	value = strings.Trim(value, "'")
+	for _, layout := range boundLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid partition bound value: %s", value)
+}
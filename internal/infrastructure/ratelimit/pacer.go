@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Pacer spaces operations evenly to honor a rate in operations per second
+type Pacer struct {
+	interval time.Duration
+	next     time.Time
+}
+
+// NewPacer creates a new Pacer, a rate of zero disables pacing
+func NewPacer(rate float64) *Pacer {
+	p := &Pacer{}
+	if rate > 0 {
+		p.interval = time.Duration(float64(time.Second) / rate)
+	}
+	return p
+}
+
+// Wait blocks until the next operation may run or the context is done
+func (p *Pacer) Wait(ctx context.Context) error {
+	if p.interval == 0 {
+		return ctx.Err()
+	}
+
+	now := time.Now()
+	if p.next.After(now) {
+		timer := time.NewTimer(p.next.Sub(now))
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+		now = p.next
+	}
+	p.next = now.Add(p.interval)
+	return nil
+}
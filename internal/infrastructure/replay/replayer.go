@@ -0,0 +1,395 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/kafka"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/ratelimit"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/retention"
+)
+
+// Headers added to the replayed messages
+const (
+	HeaderReplay          = "replay"
+	HeaderOriginalEventID = "original_event_id"
+	HeaderReplayJobID     = "replay_job_id"
+)
+
+var (
+	// ErrInvalidRequest is returned when a replay request is invalid
+	ErrInvalidRequest = errors.New("invalid replay request")
+	// ErrArchiveDisabled is returned when archived events are requested without an archive
+	ErrArchiveDisabled = errors.New("archive is not enabled")
+	// ErrNotResumable is returned when a job is completed or still running
+	ErrNotResumable = errors.New("replay job cannot be resumed")
+	// ErrNotRunning is returned when cancelling a job that is not running in this process
+	ErrNotRunning = errors.New("replay job is not running")
+
+	errJobCancelled = errors.New("cancelled by an operator")
+	errStopped      = errors.New("interrupted by shutdown")
+)
+
+// Request selects the events to replay and where to publish them
+type Request struct {
+	Source        string
+	AggregateID   string
+	AggregateType string
+	EventType     string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// Topic overrides the configured events topic
+	Topic string
+	// RateLimit in events per second, nil uses the configured rate and zero disables throttling
+	RateLimit *float64
+}
+
+// Replayer republishes historical outbox and archived events through the event producer.
+// Jobs checkpoint their progress, so a job interrupted by a crash resumes after the
+// last checkpoint and only the events replayed since then are published again.
+type Replayer struct {
+	config     config.ReplayConfig
+	replayRepo repositories.ReplayRepository
+	outboxRepo repositories.OutboxRepository
+	archive    *retention.Archive
+	producer   kafka.EventProducer
+	metrics    *metrics.Metrics
+	logger     *slog.Logger
+
+	mu      sync.Mutex
+	running map[uuid.UUID]context.CancelCauseFunc
+	wg      sync.WaitGroup
+}
+
+// NewReplayer creates a new Replayer. The archive is nil when archiving is disabled.
+func NewReplayer(
+	cfg config.ReplayConfig,
+	replayRepo repositories.ReplayRepository,
+	outboxRepo repositories.OutboxRepository,
+	archive *retention.Archive,
+	producer kafka.EventProducer,
+	metrics *metrics.Metrics,
+	logger *slog.Logger,
+) *Replayer {
+	return &Replayer{
+		config:     cfg,
+		replayRepo: replayRepo,
+		outboxRepo: outboxRepo,
+		archive:    archive,
+		producer:   producer,
+		metrics:    metrics,
+		logger:     logging.OrDefault(logger),
+		running:    make(map[uuid.UUID]context.CancelCauseFunc),
+	}
+}
+
+// Create validates the request and saves a new running job
+func (r *Replayer) Create(ctx context.Context, req *Request) (*models.ReplayJob, error) {
+	job, err := r.newJob(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.replayRepo.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create replay job: %w", err)
+	}
+
+	return job, nil
+}
+
+// Start creates a job and runs it in the background
+func (r *Replayer) Start(ctx context.Context, req *Request) (*models.ReplayJob, error) {
+	job, err := r.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := *job
+	r.runAsync(job)
+	return &snapshot, nil
+}
+
+// Count returns how many events a request selects without publishing them
+func (r *Replayer) Count(ctx context.Context, req *Request) (int64, error) {
+	job, err := r.newJob(req)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = r.iterate(ctx, job, func(event *models.OutboxEvent, pos position) error {
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// Resume marks a failed, cancelled or abandoned job as running again from its checkpoint.
+// A running job is abandoned when its heartbeat is older than three checkpoint periods.
+func (r *Replayer) Resume(ctx context.Context, id string) (*models.ReplayJob, error) {
+	job, err := r.replayRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	_, runningHere := r.running[job.ID]
+	r.mu.Unlock()
+
+	switch {
+	case job.Status == models.ReplayStatusCompleted:
+		return nil, fmt.Errorf("%w: job %s is completed", ErrNotResumable, id)
+	case runningHere:
+		return nil, fmt.Errorf("%w: job %s is running", ErrNotResumable, id)
+	case job.Status == models.ReplayStatusRunning && time.Since(job.HeartbeatAt) < r.staleAfter(job):
+		return nil, fmt.Errorf("%w: job %s is running, its last heartbeat was at %s",
+			ErrNotResumable, id, job.HeartbeatAt.Format(time.RFC3339))
+	}
+
+	job.Status = models.ReplayStatusRunning
+	job.ErrorMessage = ""
+	job.HeartbeatAt = time.Now()
+	if err := r.replayRepo.Save(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to resume replay job: %w", err)
+	}
+
+	return job, nil
+}
+
+// ResumeAsync resumes a job and runs it in the background
+func (r *Replayer) ResumeAsync(ctx context.Context, id string) (*models.ReplayJob, error) {
+	job, err := r.Resume(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := *job
+	r.runAsync(job)
+	return &snapshot, nil
+}
+
+// Cancel cancels a job running in the background of this process
+func (r *Replayer) Cancel(id string) error {
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: invalid job id: %s", ErrInvalidRequest, id)
+	}
+
+	r.mu.Lock()
+	cancel, ok := r.running[jobID]
+	r.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotRunning, id)
+	}
+
+	cancel(errJobCancelled)
+	return nil
+}
+
+// Get gets a replay job
+func (r *Replayer) Get(ctx context.Context, id string) (*models.ReplayJob, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("%w: invalid job id: %s", ErrInvalidRequest, id)
+	}
+	return r.replayRepo.GetByID(ctx, id)
+}
+
+// List lists the most recent replay jobs
+func (r *Replayer) List(ctx context.Context, limit int) ([]models.ReplayJob, error) {
+	return r.replayRepo.List(ctx, limit)
+}
+
+// Stop interrupts the jobs running in the background and waits for them to save their checkpoint
+func (r *Replayer) Stop() {
+	r.mu.Lock()
+	for _, cancel := range r.running {
+		cancel(errStopped)
+	}
+	r.mu.Unlock()
+
+	r.wg.Wait()
+}
+
+// Run replays the events of a job from its checkpoint and saves the final status.
+// An interrupted job is saved as failed, or cancelled when cancelled by an operator.
+func (r *Replayer) Run(ctx context.Context, job *models.ReplayJob) error {
+	r.logger.InfoContext(ctx, "Starting replay job", "job_id", job.ID, "source", job.Source, "replayed", job.Replayed)
+
+	pacer := ratelimit.NewPacer(job.RateLimit)
+	pending := 0
+	lastCheckpoint := time.Now()
+
+	err := r.iterate(ctx, job, func(event *models.OutboxEvent, pos position) error {
+		if err := pacer.Wait(ctx); err != nil {
+			return err
+		}
+
+		if err := r.publish(ctx, job, event); err != nil {
+			return fmt.Errorf("failed to replay event %s: %w", event.ID, err)
+		}
+
+		pos.apply(job)
+		job.Replayed++
+		r.recordReplayed(pos.source)
+
+		pending++
+		if pending >= r.config.CheckpointEvery || time.Since(lastCheckpoint) >= r.config.CheckpointInterval {
+			if err := r.checkpoint(ctx, job); err != nil {
+				return err
+			}
+			pending = 0
+			lastCheckpoint = time.Now()
+		}
+		return nil
+	})
+
+	if err != nil && ctx.Err() != nil {
+		err = context.Cause(ctx)
+	}
+
+	r.finish(ctx, job, err)
+	return err
+}
+
+func (r *Replayer) newJob(req *Request) (*models.ReplayJob, error) {
+	if req.AggregateID == "" && req.AggregateType == "" && req.EventType == "" &&
+		req.CreatedAfter == nil && req.CreatedBefore == nil {
+		return nil, fmt.Errorf("%w: select events by aggregate, event type or created-at range", ErrInvalidRequest)
+	}
+	if req.CreatedAfter != nil && req.CreatedBefore != nil && !req.CreatedAfter.Before(*req.CreatedBefore) {
+		return nil, fmt.Errorf("%w: created after must be before created before", ErrInvalidRequest)
+	}
+
+	source := req.Source
+	if source == "" {
+		source = models.ReplaySourceAll
+	}
+	switch source {
+	case models.ReplaySourceOutbox, models.ReplaySourceAll:
+	case models.ReplaySourceArchive:
+		if r.archive == nil {
+			return nil, ErrArchiveDisabled
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown source %s", ErrInvalidRequest, source)
+	}
+
+	rateLimit := r.config.RateLimit
+	if req.RateLimit != nil {
+		if *req.RateLimit < 0 {
+			return nil, fmt.Errorf("%w: rate limit cannot be negative", ErrInvalidRequest)
+		}
+		rateLimit = *req.RateLimit
+	}
+
+	now := time.Now()
+	return &models.ReplayJob{
+		ID:            uuid.New(),
+		Status:        models.ReplayStatusRunning,
+		Source:        source,
+		AggregateID:   req.AggregateID,
+		AggregateType: req.AggregateType,
+		EventType:     req.EventType,
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+		Topic:         req.Topic,
+		RateLimit:     rateLimit,
+		HeartbeatAt:   now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+func (r *Replayer) runAsync(job *models.ReplayJob) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	r.mu.Lock()
+	r.running[job.ID] = cancel
+	r.mu.Unlock()
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer func() {
+			r.mu.Lock()
+			delete(r.running, job.ID)
+			r.mu.Unlock()
+			cancel(nil)
+		}()
+
+		if err := r.Run(ctx, job); err != nil {
+			r.logger.ErrorContext(ctx, "Replay job stopped", "job_id", job.ID, "error", err)
+		}
+	}()
+}
+
+// publish republishes an event with the replay headers
+func (r *Replayer) publish(ctx context.Context, job *models.ReplayJob, event *models.OutboxEvent) error {
+	return r.producer.PublishEventWithOptions(ctx, event, kafka.PublishOptions{
+		Topic: job.Topic,
+		Headers: map[string]string{
+			HeaderReplay:          "true",
+			HeaderOriginalEventID: event.MessageID().String(),
+			HeaderReplayJobID:     job.ID.String(),
+		},
+	})
+}
+
+func (r *Replayer) checkpoint(ctx context.Context, job *models.ReplayJob) error {
+	job.HeartbeatAt = time.Now()
+	if err := r.replayRepo.Save(ctx, job); err != nil {
+		return fmt.Errorf("failed to save replay checkpoint: %w", err)
+	}
+	return nil
+}
+
+// finish saves the final status of a job, even when its context is cancelled
+func (r *Replayer) finish(ctx context.Context, job *models.ReplayJob, err error) {
+	now := time.Now()
+	switch {
+	case err == nil:
+		job.Status = models.ReplayStatusCompleted
+		job.CompletedAt = &now
+		r.logger.InfoContext(ctx, "Replay job completed", "job_id", job.ID, "replayed", job.Replayed)
+	case errors.Is(err, errJobCancelled):
+		job.Status = models.ReplayStatusCancelled
+		job.ErrorMessage = err.Error()
+	default:
+		job.Status = models.ReplayStatusFailed
+		job.ErrorMessage = err.Error()
+	}
+
+	if saveErr := r.checkpoint(context.WithoutCancel(ctx), job); saveErr != nil {
+		r.logger.ErrorContext(ctx, "Failed to save replay job", "job_id", job.ID, "error", saveErr)
+	}
+}
+
+// staleAfter returns how long a running job may go without a heartbeat before it is
+// considered abandoned
+func (r *Replayer) staleAfter(job *models.ReplayJob) time.Duration {
+	period := r.config.CheckpointInterval
+	if job.RateLimit > 0 {
+		if perEvent := time.Duration(float64(time.Second) / job.RateLimit); perEvent > period {
+			period = perEvent
+		}
+	}
+	return 3 * period
+}
+
+func (r *Replayer) recordReplayed(source string) {
+	if r.metrics != nil {
+		r.metrics.RecordEventReplayed(source)
+	}
+}
@@ -0,0 +1,170 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/retention"
+)
+
+// position is the checkpoint after an event
+type position struct {
+	source    string
+	segment   string
+	offset    int
+	createdAt time.Time
+	eventID   uuid.UUID
+}
+
+func (p position) apply(job *models.ReplayJob) {
+	job.CheckpointSource = p.source
+	switch p.source {
+	case models.ReplaySourceArchive:
+		job.CheckpointSegment = p.segment
+		job.CheckpointOffset = p.offset
+	case models.ReplaySourceOutbox:
+		createdAt, eventID := p.createdAt, p.eventID
+		job.CheckpointCreatedAt = &createdAt
+		job.CheckpointEventID = &eventID
+	}
+}
+
+type eventFunc func(event *models.OutboxEvent, pos position) error
+
+// iterate calls fn for the events selected by a job after its checkpoint, oldest first:
+// the archived events, then the published events still in the outbox
+func (r *Replayer) iterate(ctx context.Context, job *models.ReplayJob, fn eventFunc) error {
+	if job.Source != models.ReplaySourceOutbox && r.archive != nil && job.CheckpointSource != models.ReplaySourceOutbox {
+		if err := r.iterateArchive(ctx, job, fn); err != nil {
+			return err
+		}
+	}
+
+	if job.Source != models.ReplaySourceArchive {
+		return r.iterateOutbox(ctx, job, fn)
+	}
+	return nil
+}
+
+func (r *Replayer) iterateArchive(ctx context.Context, job *models.ReplayJob, fn eventFunc) error {
+	segments, err := r.archive.Segments()
+	if err != nil {
+		return err
+	}
+
+	// Skip the segments before the checkpoint segment, they are already replayed
+	resuming := job.CheckpointSource == models.ReplaySourceArchive && job.CheckpointSegment != ""
+	for _, segment := range segments {
+		skip := 0
+		if resuming {
+			if segment.File != job.CheckpointSegment {
+				continue
+			}
+			resuming = false
+			skip = job.CheckpointOffset
+		}
+
+		if !segmentOverlaps(job, segment) {
+			continue
+		}
+
+		events, err := r.archive.Read(segment.File)
+		if err != nil {
+			return err
+		}
+
+		for i := skip; i < len(events); i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !matches(job, &events[i]) {
+				continue
+			}
+
+			pos := position{source: models.ReplaySourceArchive, segment: segment.File, offset: i + 1}
+			if err := fn(&events[i], pos); err != nil {
+				return err
+			}
+		}
+	}
+
+	if resuming {
+		return fmt.Errorf("checkpoint segment %s is not in the archive index", job.CheckpointSegment)
+	}
+	return nil
+}
+
+func (r *Replayer) iterateOutbox(ctx context.Context, job *models.ReplayJob, fn eventFunc) error {
+	filter := repositories.OutboxEventFilter{
+		Statuses:      []models.OutboxStatus{models.OutboxStatusPublished},
+		EventType:     job.EventType,
+		AggregateID:   job.AggregateID,
+		AggregateType: job.AggregateType,
+		CreatedAfter:  job.CreatedAfter,
+		CreatedBefore: job.CreatedBefore,
+		Ascending:     true,
+	}
+	if job.CheckpointSource == models.ReplaySourceOutbox && job.CheckpointCreatedAt != nil && job.CheckpointEventID != nil {
+		filter.After = &repositories.OutboxCursor{CreatedAt: *job.CheckpointCreatedAt, ID: *job.CheckpointEventID}
+	}
+
+	for {
+		events, err := r.outboxRepo.ListEvents(ctx, filter, r.config.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to select events to replay: %w", err)
+		}
+
+		for i := range events {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			pos := position{source: models.ReplaySourceOutbox, createdAt: events[i].CreatedAt, eventID: events[i].ID}
+			if err := fn(&events[i], pos); err != nil {
+				return err
+			}
+		}
+
+		if len(events) < r.config.BatchSize {
+			return nil
+		}
+		last := events[len(events)-1]
+		filter.After = &repositories.OutboxCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// segmentOverlaps returns false when a segment is entirely outside the created-at range of a job
+func segmentOverlaps(job *models.ReplayJob, segment retention.Segment) bool {
+	if job.CreatedAfter != nil && segment.LastCreatedAt.Before(*job.CreatedAfter) {
+		return false
+	}
+	if job.CreatedBefore != nil && !segment.FirstCreatedAt.Before(*job.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+// matches applies the selection of a job to an archived event
+func matches(job *models.ReplayJob, event *models.OutboxEvent) bool {
+	if job.AggregateID != "" && event.AggregateID != job.AggregateID {
+		return false
+	}
+	if job.AggregateType != "" && event.AggregateType != job.AggregateType {
+		return false
+	}
+	if job.EventType != "" && event.EventType != job.EventType {
+		return false
+	}
+	if job.CreatedAfter != nil && event.CreatedAt.Before(*job.CreatedAfter) {
+		return false
+	}
+	if job.CreatedBefore != nil && !event.CreatedAt.Before(*job.CreatedBefore) {
+		return false
+	}
+	return true
+}
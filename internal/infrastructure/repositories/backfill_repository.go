@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+)
+
+type BackfillRepository interface {
+	GetCheckpoint(ctx context.Context, name string) (*models.BackfillCheckpoint, error)
+	SaveCheckpoint(ctx context.Context, checkpoint *models.BackfillCheckpoint) error
+	CreateOrderSnapshot(ctx context.Context, snapshot *models.BackfillOrderSnapshot, event *models.OutboxEvent) (bool, error)
+}
+
+type backfillRepository struct {
+	db *gorm.DB
+}
+
+func NewBackfillRepository(db *gorm.DB) BackfillRepository {
+	return &backfillRepository{db: db}
+}
+
+// GetCheckpoint gets the checkpoint of a backfill job, a job that never ran starts at the first row
+func (r *backfillRepository) GetCheckpoint(ctx context.Context, name string) (*models.BackfillCheckpoint, error) {
+	var checkpoint models.BackfillCheckpoint
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&checkpoint).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &models.BackfillCheckpoint{Name: name, StartedAt: time.Now()}, nil
+		}
+		return nil, err
+	}
+
+	return &checkpoint, nil
+}
+
+// SaveCheckpoint creates or updates the checkpoint of a backfill job
+func (r *backfillRepository) SaveCheckpoint(ctx context.Context, checkpoint *models.BackfillCheckpoint) error {
+	return r.db.WithContext(ctx).Save(checkpoint).Error
+}
+
+// CreateOrderSnapshot writes the snapshot event of an order version unless the version was
+// already snapshotted. The version is recorded in the same transaction as the event and its
+// primary key makes concurrent runs write the event once.
+func (r *backfillRepository) CreateOrderSnapshot(ctx context.Context, snapshot *models.BackfillOrderSnapshot, event *models.OutboxEvent) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(snapshot)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+
+	return created, err
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+)
+
+type InboxRepository interface {
+	Record(ctx context.Context, entry *models.InboxEntry) (bool, error)
+	Exists(ctx context.Context, consumerGroup, eventID string) (bool, error)
+	DeleteProcessedBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	LockGroup(ctx context.Context, consumerGroup, transactionID string) error
+	AddGroupEvent(ctx context.Context, event *models.InboxGroupEvent) error
+	ListGroupEvents(ctx context.Context, consumerGroup, transactionID string) ([]models.InboxGroupEvent, error)
+	ListExpiredGroups(ctx context.Context, consumerGroup string, receivedBefore time.Time, limit int) ([]string, error)
+	DeleteGroup(ctx context.Context, consumerGroup, transactionID string) error
+	DeleteGroupEventsReceivedBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+type inboxRepository struct {
+	db *gorm.DB
+}
+
+// NewInboxRepository creates an InboxRepository on db, which is the handler transaction when
+// recording the processed events
+func NewInboxRepository(db *gorm.DB) InboxRepository {
+	return &inboxRepository{db: db}
+}
+
+// Record inserts the entry unless the consumer group already recorded the event, and returns
+// false for an event already recorded. An entry inserted by a transaction still in progress
+// blocks the insert until that transaction ends.
+func (r *inboxRepository) Record(ctx context.Context, entry *models.InboxEntry) (bool, error) {
+	if entry.ProcessedAt.IsZero() {
+		entry.ProcessedAt = time.Now()
+	}
+
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(entry)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Exists returns true if the consumer group recorded the event
+func (r *inboxRepository) Exists(ctx context.Context, consumerGroup, eventID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.InboxEntry{}).
+		Where("consumer_group = ? AND event_id = ?", consumerGroup, eventID).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// DeleteProcessedBefore deletes at most limit entries processed before cutoff
+func (r *inboxRepository) DeleteProcessedBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		DELETE FROM inbox
+		WHERE (consumer_group, event_id) IN (
+			SELECT consumer_group, event_id FROM inbox
+			WHERE processed_at < ?
+			ORDER BY processed_at
+			LIMIT ?
+		)`, cutoff, limit)
+
+	return result.RowsAffected, result.Error
+}
+
+// LockGroup locks the transaction for the consumer group until the end of the current
+// transaction, so the events of a transaction are collected one at a time and the last one
+// sees all the others
+func (r *inboxRepository) LockGroup(ctx context.Context, consumerGroup, transactionID string) error {
+	return r.db.WithContext(ctx).
+		Exec("SELECT pg_advisory_xact_lock(hashtext(?), hashtext(?))", consumerGroup, transactionID).Error
+}
+
+// AddGroupEvent holds an event until the other events of its transaction are received
+func (r *inboxRepository) AddGroupEvent(ctx context.Context, event *models.InboxGroupEvent) error {
+	if event.ReceivedAt.IsZero() {
+		event.ReceivedAt = time.Now()
+	}
+
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(event).Error
+}
+
+// ListGroupEvents lists the events held for the transaction
+func (r *inboxRepository) ListGroupEvents(ctx context.Context, consumerGroup, transactionID string) ([]models.InboxGroupEvent, error) {
+	var events []models.InboxGroupEvent
+	err := r.db.WithContext(ctx).
+		Where("consumer_group = ? AND transaction_id = ?", consumerGroup, transactionID).
+		Order("received_at").
+		Find(&events).Error
+
+	return events, err
+}
+
+// ListExpiredGroups lists at most limit transactions of the consumer group whose first held
+// event was received before receivedBefore, oldest first
+func (r *inboxRepository) ListExpiredGroups(ctx context.Context, consumerGroup string, receivedBefore time.Time, limit int) ([]string, error) {
+	var transactionIDs []string
+	err := r.db.WithContext(ctx).
+		Model(&models.InboxGroupEvent{}).
+		Select("transaction_id").
+		Where("consumer_group = ?", consumerGroup).
+		Group("transaction_id").
+		Having("MIN(received_at) < ?", receivedBefore).
+		Order("MIN(received_at)").
+		Limit(limit).
+		Pluck("transaction_id", &transactionIDs).Error
+
+	return transactionIDs, err
+}
+
+// DeleteGroup deletes the events held for the transaction
+func (r *inboxRepository) DeleteGroup(ctx context.Context, consumerGroup, transactionID string) error {
+	return r.db.WithContext(ctx).
+		Where("consumer_group = ? AND transaction_id = ?", consumerGroup, transactionID).
+		Delete(&models.InboxGroupEvent{}).Error
+}
+
+// DeleteGroupEventsReceivedBefore deletes at most limit held events received before cutoff,
+// whose transaction never completed
+func (r *inboxRepository) DeleteGroupEventsReceivedBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		DELETE FROM inbox_group_events
+		WHERE (consumer_group, transaction_id, event_id) IN (
+			SELECT consumer_group, transaction_id, event_id FROM inbox_group_events
+			WHERE received_at < ?
+			ORDER BY received_at
+			LIMIT ?
+		)`, cutoff, limit)
+
+	return result.RowsAffected, result.Error
+}
@@ -3,7 +3,9 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
@@ -16,8 +18,17 @@ type OrderRepository interface {
 	Update(ctx context.Context, order *models.Order) error
 	Delete(ctx context.Context, id string) error
 	List(ctx context.Context, limit, offset int) ([]models.Order, error)
+	ListAfter(ctx context.Context, after *OrderCursor, limit int) ([]models.Order, error)
 	GetByCustomerID(ctx context.Context, customerID string, limit, offset int) ([]models.Order, error)
 	GetByStatus(ctx context.Context, status models.OrderStatus, limit, offset int) ([]models.Order, error)
+	ListWithoutEvent(ctx context.Context, eventType string, createdAfter, createdBefore time.Time, limit int) ([]models.Order, error)
+	CountWithoutEvent(ctx context.Context, eventType string, createdAfter, createdBefore time.Time) (int64, error)
+}
+
+// OrderCursor is a position in a listing of orders ordered by creation date and ID
+type OrderCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
 }
 
 type orderRepository struct {
@@ -101,6 +112,23 @@ func (r *orderRepository) List(ctx context.Context, limit, offset int) ([]models
 	return orders, err
 }
 
+// ListAfter lists the orders following the cursor, oldest first, or from the first order when
+// after is nil. The ID breaks the ties between orders created at the same time.
+func (r *orderRepository) ListAfter(ctx context.Context, after *OrderCursor, limit int) ([]models.Order, error) {
+	query := r.db.WithContext(ctx).Preload("Items")
+	if after != nil {
+		query = query.Where("(created_at, id) > (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var orders []models.Order
+	err := query.
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(&orders).Error
+
+	return orders, err
+}
+
 // GetByCustomerID gets orders by customer ID
 func (r *orderRepository) GetByCustomerID(ctx context.Context, customerID string, limit, offset int) ([]models.Order, error) {
 	var orders []models.Order
@@ -128,3 +156,33 @@ func (r *orderRepository) GetByStatus(ctx context.Context, status models.OrderSt
 
 	return orders, err
 }
+
+// ListWithoutEvent lists the orders created in the given window that have no outbox event
+// of the given type, oldest first. Soft-deleted events count as existing.
+func (r *orderRepository) ListWithoutEvent(ctx context.Context, eventType string, createdAfter, createdBefore time.Time, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.withoutEvent(ctx, eventType, createdAfter, createdBefore).
+		Preload("Items").
+		Order("orders.created_at ASC").
+		Limit(limit).
+		Find(&orders).Error
+
+	return orders, err
+}
+
+// CountWithoutEvent counts the orders created in the given window that have no outbox event of the given type
+func (r *orderRepository) CountWithoutEvent(ctx context.Context, eventType string, createdAfter, createdBefore time.Time) (int64, error) {
+	var count int64
+	err := r.withoutEvent(ctx, eventType, createdAfter, createdBefore).
+		Model(&models.Order{}).
+		Count(&count).Error
+
+	return count, err
+}
+
+func (r *orderRepository) withoutEvent(ctx context.Context, eventType string, createdAfter, createdBefore time.Time) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Where("orders.created_at >= ? AND orders.created_at < ?", createdAfter, createdBefore).
+		Where(`NOT EXISTS (SELECT 1 FROM outbox WHERE outbox.aggregate_type = 'Order'
+			AND outbox.aggregate_id = orders.id::text AND outbox.event_type = ?)`, eventType)
+}
@@ -3,37 +3,98 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
 )
 
+// ErrEventNotFound is returned when an outbox event does not exist
+var ErrEventNotFound = fmt.Errorf("outbox event not found")
+
+// ErrEventNotClaimable is returned when an event is no longer pending or is claimed by another worker
+var ErrEventNotClaimable = fmt.Errorf("outbox event is not pending or is claimed by another worker")
+
+// OutboxEventFilter selects outbox events, zero fields are ignored
+type OutboxEventFilter struct {
+	IDs           []uuid.UUID
+	Statuses      []models.OutboxStatus
+	EventType     string
+	AggregateID   string
+	AggregateType string
+	CorrelationID string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// After continues a listing after the given position
+	After *OutboxCursor
+	// Ascending lists the oldest events first
+	Ascending bool
+}
+
+// OutboxCursor is a position in a listing ordered by creation date and ID
+type OutboxCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
 type OutboxRepository interface {
 	Create(ctx context.Context, event *models.OutboxEvent) error
+	CreateIfNotExists(ctx context.Context, event *models.OutboxEvent) (bool, error)
 	GetByID(ctx context.Context, id string) (*models.OutboxEvent, error)
 	GetPendingEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	GetPendingEventsByPriority(ctx context.Context, priority models.OutboxPriority, limit int) ([]models.OutboxEvent, error)
+	CountPendingEventsByPriority(ctx context.Context) (map[models.OutboxPriority]int64, error)
 	GetFailedEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	GetExpiredEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error)
 	Update(ctx context.Context, event *models.OutboxEvent) error
 	Delete(ctx context.Context, id string) error
 	MarkAsPublished(ctx context.Context, id string) error
+	MarkAsDelivered(ctx context.Context, id string, topic string, partition int32, offset int64) error
+	GetDeliveredEvents(ctx context.Context, publishedAfter, publishedBefore time.Time, limit int) ([]models.OutboxEvent, error)
 	MarkAsFailed(ctx context.Context, id string, errorMsg string) error
+	MarkAsExpired(ctx context.Context, id string, expiresAt time.Time) error
 	GetEventsByAggregate(ctx context.Context, aggregateID, aggregateType string) ([]models.OutboxEvent, error)
 	GetEventsByType(ctx context.Context, eventType string, limit, offset int) ([]models.OutboxEvent, error)
 	CleanupOldEvents(ctx context.Context, olderThan time.Duration) error
+	GetPublishedEventsBefore(ctx context.Context, cutoff time.Time, limit int) ([]models.OutboxEvent, error)
+	HardDeleteEvents(ctx context.Context, ids []uuid.UUID) (int64, error)
+	DeleteTransactionsBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	RestoreEvents(ctx context.Context, events []models.OutboxEvent) (int64, error)
 
 	GetPendingEventForUpdate(ctx context.Context, id string) (*models.OutboxEvent, error)
 	MarkAsPublishedWithLock(ctx context.Context, id string) error
 	MarkAsFailedWithLock(ctx context.Context, id string, errorMsg string) error
+
+	ClaimEvent(ctx context.Context, id, workerID string, lease time.Duration) (*models.OutboxEvent, error)
+	ReleaseClaim(ctx context.Context, id, workerID string) error
+	ReleaseClaims(ctx context.Context, workerID string) (int64, error)
+	ReleaseExpiredClaims(ctx context.Context) (int64, error)
+	CountEventsByStatus(ctx context.Context) (map[models.OutboxStatus]int64, error)
+	GetOldestPendingCreatedAt(ctx context.Context) (*time.Time, error)
+	GetStuckEvents(ctx context.Context, createdBefore time.Time, limit int) ([]models.OutboxEvent, error)
+	CountStuckEvents(ctx context.Context, createdBefore time.Time) (int64, error)
+	CountDeliveries(ctx context.Context, since time.Time, threshold time.Duration) (total, late int64, err error)
+
+	ListEvents(ctx context.Context, filter OutboxEventFilter, limit int) ([]models.OutboxEvent, error)
+	RetryEvents(ctx context.Context, ids []uuid.UUID) (int64, error)
+	ResetRetryCount(ctx context.Context, ids []uuid.UUID) (int64, error)
+	DeadLetterEvents(ctx context.Context, ids []uuid.UUID, reason string) (int64, error)
+	DeleteEvents(ctx context.Context, ids []uuid.UUID) (int64, error)
 }
 
 type outboxRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	logger *slog.Logger
 }
 
-func NewOutboxRepository(db *gorm.DB) OutboxRepository {
-	return &outboxRepository{db: db}
+// NewOutboxRepository creates a new OutboxRepository
+func NewOutboxRepository(db *gorm.DB, logger *slog.Logger) OutboxRepository {
+	return &outboxRepository{db: db, logger: logging.OrDefault(logger)}
 }
 
 // Create creates a new outbox event
@@ -41,6 +102,30 @@ func (r *outboxRepository) Create(ctx context.Context, event *models.OutboxEvent
 	return r.db.WithContext(ctx).Create(event).Error
 }
 
+// CreateIfNotExists creates an event unless an event with the same ID exists, including
+// soft-deleted ones. The ID is checked explicitly because a partitioned table only
+// enforces the uniqueness of (id, created_at).
+func (r *outboxRepository) CreateIfNotExists(ctx context.Context, event *models.OutboxEvent) (bool, error) {
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Unscoped().Model(&models.OutboxEvent{}).Where("id = ?", event.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+
+	return created, err
+}
+
 // GetByID gets an outbox event by ID
 func (r *outboxRepository) GetByID(ctx context.Context, id string) (*models.OutboxEvent, error) {
 	var event models.OutboxEvent
@@ -50,7 +135,7 @@ func (r *outboxRepository) GetByID(ctx context.Context, id string) (*models.Outb
 
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("outbox event not found with id: %s", id)
+			return nil, fmt.Errorf("%w with id: %s", ErrEventNotFound, id)
 		}
 		return nil, err
 	}
@@ -76,17 +161,77 @@ func (r *outboxRepository) GetPendingEventForUpdate(ctx context.Context, id stri
 }
 
 // GetPendingEvents gets pending events for publication
+// An event is held back while a pending event of its aggregate with a lower version was
+// created after it, so a batch never holds a version without the versions preceding it.
 func (r *outboxRepository) GetPendingEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
 	var events []models.OutboxEvent
 	err := r.db.WithContext(ctx).
 		Where("status = ?", models.OutboxStatusPending).
-		Order("created_at ASC").
+		Where(pendingPredecessorGuard("older.created_at > outbox.created_at"), models.OutboxStatusPending).
+		Order("created_at ASC, aggregate_version ASC").
+		Limit(limit).
+		Find(&events).Error
+
+	return events, err
+}
+
+// GetPendingEventsByPriority gets pending events of a single priority lane for publication.
+// An event is held back while a preceding pending event of its aggregate waits in another lane,
+// or was created after it, so the lanes never publish the events of an aggregate out of order.
+func (r *outboxRepository) GetPendingEventsByPriority(ctx context.Context, priority models.OutboxPriority, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND priority = ?", models.OutboxStatusPending, priority).
+		Where(pendingPredecessorGuard("(older.priority <> outbox.priority OR older.created_at > outbox.created_at)"), models.OutboxStatusPending).
+		Order("created_at ASC, aggregate_version ASC").
 		Limit(limit).
 		Find(&events).Error
 
 	return events, err
 }
 
+// pendingPredecessorGuard excludes the events of an aggregate preceded by one of its pending events
+// matching condition. Events are ordered by aggregate version, or by creation date when one of
+// them predates the versions and has version 0.
+func pendingPredecessorGuard(condition string) string {
+	return `NOT EXISTS (
+		SELECT 1 FROM outbox AS older
+		WHERE older.aggregate_type = outbox.aggregate_type
+			AND older.aggregate_id = outbox.aggregate_id
+			AND older.status = ?
+			AND older.deleted_at IS NULL
+			AND CASE WHEN older.aggregate_version > 0 AND outbox.aggregate_version > 0
+				THEN older.aggregate_version < outbox.aggregate_version
+				ELSE older.created_at < outbox.created_at
+			END
+			AND ` + condition + `
+	)`
+}
+
+// CountPendingEventsByPriority counts pending events in each priority lane
+func (r *outboxRepository) CountPendingEventsByPriority(ctx context.Context) (map[models.OutboxPriority]int64, error) {
+	var rows []struct {
+		Priority models.OutboxPriority
+		Count    int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Select("priority, COUNT(*) AS count").
+		Where("status = ?", models.OutboxStatusPending).
+		Group("priority").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[models.OutboxPriority]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Priority] = row.Count
+	}
+
+	return counts, nil
+}
+
 // GetFailedEvents gets events that failed publication
 func (r *outboxRepository) GetFailedEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
 	var events []models.OutboxEvent
@@ -99,6 +244,18 @@ func (r *outboxRepository) GetFailedEvents(ctx context.Context, limit int) ([]mo
 	return events, err
 }
 
+// GetExpiredEvents gets events that expired before publication
+func (r *outboxRepository) GetExpiredEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Where("status = ?", models.OutboxStatusExpired).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&events).Error
+
+	return events, err
+}
+
 // Update updates an existing outbox event
 func (r *outboxRepository) Update(ctx context.Context, event *models.OutboxEvent) error {
 	return r.db.WithContext(ctx).Save(event).Error
@@ -125,8 +282,37 @@ func (r *outboxRepository) MarkAsPublished(ctx context.Context, id string) error
 		Model(&models.OutboxEvent{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
-			"status":       models.OutboxStatusPublished,
-			"published_at": &now,
+			"status":        models.OutboxStatusPublished,
+			"published_at":  &now,
+			"claimed_by":    "",
+			"claimed_until": nil,
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("outbox event not found with id: %s", id)
+	}
+
+	return nil
+}
+
+// MarkAsDelivered marks an event as published and records its delivery receipt
+func (r *outboxRepository) MarkAsDelivered(ctx context.Context, id string, topic string, partition int32, offset int64) error {
+	now := time.Now()
+	result := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          models.OutboxStatusPublished,
+			"published_at":    &now,
+			"claimed_by":      "",
+			"claimed_until":   nil,
+			"kafka_topic":     topic,
+			"kafka_partition": partition,
+			"kafka_offset":    offset,
 		})
 
 	if result.Error != nil {
@@ -140,6 +326,20 @@ func (r *outboxRepository) MarkAsPublished(ctx context.Context, id string) error
 	return nil
 }
 
+// GetDeliveredEvents gets the published events with a delivery receipt published in the
+// given window, most recent first
+func (r *outboxRepository) GetDeliveredEvents(ctx context.Context, publishedAfter, publishedBefore time.Time, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND kafka_offset IS NOT NULL", models.OutboxStatusPublished).
+		Where("published_at >= ? AND published_at < ?", publishedAfter, publishedBefore).
+		Order("published_at DESC").
+		Limit(limit).
+		Find(&events).Error
+
+	return events, err
+}
+
 // MarkAsPublishedWithLock marks an event as published with row lock to prevent race conditions
 func (r *outboxRepository) MarkAsPublishedWithLock(ctx context.Context, id string) error {
 	event, err := r.GetPendingEventForUpdate(ctx, id)
@@ -163,6 +363,8 @@ func (r *outboxRepository) MarkAsFailed(ctx context.Context, id string, errorMsg
 			"status":        models.OutboxStatusFailed,
 			"error_message": errorMsg,
 			"retry_count":   gorm.Expr("retry_count + 1"),
+			"claimed_by":    "",
+			"claimed_until": nil,
 		})
 
 	if result.Error != nil {
@@ -176,6 +378,29 @@ func (r *outboxRepository) MarkAsFailed(ctx context.Context, id string, errorMsg
 	return nil
 }
 
+// MarkAsExpired marks a pending event as expired so it is never published
+func (r *outboxRepository) MarkAsExpired(ctx context.Context, id string, expiresAt time.Time) error {
+	result := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("id = ? AND status = ?", id, models.OutboxStatusPending).
+		Updates(map[string]interface{}{
+			"status":        models.OutboxStatusExpired,
+			"expires_at":    expiresAt,
+			"claimed_by":    "",
+			"claimed_until": nil,
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("pending outbox event not found with id: %s", id)
+	}
+
+	return nil
+}
+
 // MarkAsFailedWithLock marks an event as failed with row lock to prevent race conditions
 func (r *outboxRepository) MarkAsFailedWithLock(ctx context.Context, id string, errorMsg string) error {
 	event, err := r.GetPendingEventForUpdate(ctx, id)
@@ -190,6 +415,159 @@ func (r *outboxRepository) MarkAsFailedWithLock(ctx context.Context, id string,
 	return r.db.WithContext(ctx).Save(event).Error
 }
 
+// ClaimEvent claims a pending event for a worker during the lease, so no other worker
+// publishes it concurrently. Claims whose lease expired can be taken over. The final event
+// count of the transaction that wrote the event is copied onto it.
+func (r *outboxRepository) ClaimEvent(ctx context.Context, id, workerID string, lease time.Duration) (*models.OutboxEvent, error) {
+	now := time.Now()
+	claimedUntil := now.Add(lease)
+
+	result := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("id = ? AND status = ?", id, models.OutboxStatusPending).
+		Where("claimed_until IS NULL OR claimed_until < ? OR claimed_by = ?", now, workerID).
+		Updates(map[string]interface{}{
+			"claimed_by":    workerID,
+			"claimed_until": claimedUntil,
+			"transaction_event_count": gorm.Expr(`COALESCE((
+				SELECT event_count FROM outbox_transactions
+				WHERE outbox_transactions.transaction_id = outbox.transaction_id
+			), transaction_event_count)`),
+		})
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, ErrEventNotClaimable
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// ReleaseClaim releases the claim a worker holds on an event
+func (r *outboxRepository) ReleaseClaim(ctx context.Context, id, workerID string) error {
+	return r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("id = ? AND claimed_by = ?", id, workerID).
+		Updates(map[string]interface{}{
+			"claimed_by":    "",
+			"claimed_until": nil,
+		}).Error
+}
+
+// ReleaseClaims releases every claim held by a worker and returns how many were released
+func (r *outboxRepository) ReleaseClaims(ctx context.Context, workerID string) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("claimed_by = ?", workerID).
+		Updates(map[string]interface{}{
+			"claimed_by":    "",
+			"claimed_until": nil,
+		})
+
+	return r.logBulkUpdate(ctx, "release_claims", result)
+}
+
+// ReleaseExpiredClaims releases the claims whose lease expired on pending events, left behind
+// by workers that crashed or stalled, and returns how many were released
+func (r *outboxRepository) ReleaseExpiredClaims(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("status = ? AND claimed_until < ?", models.OutboxStatusPending, time.Now()).
+		Updates(map[string]interface{}{
+			"claimed_by":    "",
+			"claimed_until": nil,
+		})
+
+	return r.logBulkUpdate(ctx, "release_expired_claims", result)
+}
+
+// CountEventsByStatus counts events in each status
+func (r *outboxRepository) CountEventsByStatus(ctx context.Context) (map[models.OutboxStatus]int64, error) {
+	var rows []struct {
+		Status models.OutboxStatus
+		Count  int64
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[models.OutboxStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+// GetOldestPendingCreatedAt gets the creation date of the oldest pending event, or nil if there is none
+func (r *outboxRepository) GetOldestPendingCreatedAt(ctx context.Context) (*time.Time, error) {
+	var oldest struct {
+		CreatedAt *time.Time
+	}
+	err := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Select("MIN(created_at) AS created_at").
+		Where("status = ?", models.OutboxStatusPending).
+		Scan(&oldest).Error
+
+	return oldest.CreatedAt, err
+}
+
+// GetStuckEvents gets pending events created before the given date, oldest first
+func (r *outboxRepository) GetStuckEvents(ctx context.Context, createdBefore time.Time, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND created_at < ?", models.OutboxStatusPending, createdBefore).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&events).Error
+
+	return events, err
+}
+
+// CountStuckEvents counts pending events created before the given date
+func (r *outboxRepository) CountStuckEvents(ctx context.Context, createdBefore time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("status = ? AND created_at < ?", models.OutboxStatusPending, createdBefore).
+		Count(&count).Error
+
+	return count, err
+}
+
+// CountDeliveries counts the events published since the given date and how many of them
+// were published later than the threshold after their creation. Events that were never
+// delivered count as late deliveries too: those created since the given date and still
+// pending past the threshold, failed or dead lettered, and those expired since the given date.
+func (r *outboxRepository) CountDeliveries(ctx context.Context, since time.Time, threshold time.Duration) (int64, int64, error) {
+	var counts struct {
+		Total int64
+		Late  int64
+	}
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&models.OutboxEvent{}).
+		Select("COUNT(*) AS total, COUNT(*) FILTER (WHERE status <> ? OR published_at - created_at > ? * INTERVAL '1 second') AS late",
+			models.OutboxStatusPublished, threshold.Seconds()).
+		Where("(status = ? AND published_at >= ?) OR (status = ? AND created_at >= ? AND created_at < ?) OR (status IN ? AND created_at >= ?) OR (status = ? AND expires_at >= ?)",
+			models.OutboxStatusPublished, since,
+			models.OutboxStatusPending, since, time.Now().Add(-threshold),
+			[]models.OutboxStatus{models.OutboxStatusFailed, models.OutboxStatusDeadLetter}, since,
+			models.OutboxStatusExpired, since).
+		Scan(&counts).Error
+
+	return counts.Total, counts.Late, err
+}
+
 // GetEventsByAggregate gets events by aggregate
 func (r *outboxRepository) GetEventsByAggregate(ctx context.Context, aggregateID, aggregateType string) ([]models.OutboxEvent, error) {
 	var events []models.OutboxEvent
@@ -222,5 +600,193 @@ func (r *outboxRepository) CleanupOldEvents(ctx context.Context, olderThan time.
 		Where("status = ? AND published_at < ?", models.OutboxStatusPublished, cutoffTime).
 		Delete(&models.OutboxEvent{})
 
-	return result.Error
+	_, err := r.logBulkUpdate(ctx, "cleanup", result)
+	return err
+}
+
+// GetPublishedEventsBefore gets events published before the cutoff, including soft-deleted ones
+func (r *outboxRepository) GetPublishedEventsBefore(ctx context.Context, cutoff time.Time, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Where("status = ? AND published_at < ?", models.OutboxStatusPublished, cutoff).
+		Order("published_at ASC").
+		Limit(limit).
+		Find(&events).Error
+
+	return events, err
+}
+
+// HardDeleteEvents permanently removes events, bypassing the soft delete
+func (r *outboxRepository) HardDeleteEvents(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Where("id IN ?", ids).
+		Delete(&models.OutboxEvent{})
+
+	return r.logBulkUpdate(ctx, "hard_delete", result)
+}
+
+// DeleteTransactionsBefore deletes at most limit event counts of transactions that started
+// before cutoff and have no pending event left to claim, and returns the number deleted
+func (r *outboxRepository) DeleteTransactionsBefore(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		DELETE FROM outbox_transactions WHERE transaction_id IN (
+			SELECT transaction_id FROM outbox_transactions AS settled
+			WHERE settled.created_at < ?
+				AND NOT EXISTS (
+					SELECT 1 FROM outbox
+					WHERE outbox.transaction_id = settled.transaction_id AND outbox.status = ?
+				)
+			LIMIT ?
+		)`, cutoff, models.OutboxStatusPending, limit)
+
+	return r.logBulkUpdate(ctx, "delete_transactions", result)
+}
+
+// RestoreEvents inserts previously archived events, skipping the ones that still exist. The
+// hooks are skipped so the events keep their aggregate version, including the unversioned ones.
+func (r *outboxRepository) RestoreEvents(ctx context.Context, events []models.OutboxEvent) (int64, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.WithContext(ctx).
+		Session(&gorm.Session{SkipHooks: true}).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(&events, 100)
+
+	return r.logBulkUpdate(ctx, "restore", result)
+}
+
+// ListEvents lists the events matching the filter, newest first unless the filter is ascending
+func (r *outboxRepository) ListEvents(ctx context.Context, filter OutboxEventFilter, limit int) ([]models.OutboxEvent, error) {
+	query := r.db.WithContext(ctx).Model(&models.OutboxEvent{})
+
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+	if filter.AggregateID != "" {
+		query = query.Where("aggregate_id = ?", filter.AggregateID)
+	}
+	if filter.AggregateType != "" {
+		query = query.Where("aggregate_type = ?", filter.AggregateType)
+	}
+	if filter.CorrelationID != "" {
+		query = query.Where("correlation_id = ?", filter.CorrelationID)
+	}
+	if filter.CreatedAfter != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filter.CreatedBefore)
+	}
+
+	order := "created_at DESC, id DESC"
+	if filter.Ascending {
+		order = "created_at ASC, id ASC"
+	}
+	if filter.After != nil {
+		if filter.Ascending {
+			query = query.Where("(created_at, id) > (?, ?)", filter.After.CreatedAt, filter.After.ID)
+		} else {
+			query = query.Where("(created_at, id) < (?, ?)", filter.After.CreatedAt, filter.After.ID)
+		}
+	}
+
+	var events []models.OutboxEvent
+	err := query.
+		Order(order).
+		Limit(limit).
+		Find(&events).Error
+
+	return events, err
+}
+
+// RetryEvents moves failed and dead-lettered events back to pending, keeping their retry count
+func (r *outboxRepository) RetryEvents(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("id IN ? AND status IN ?", ids, []models.OutboxStatus{models.OutboxStatusFailed, models.OutboxStatusDeadLetter}).
+		Updates(map[string]interface{}{
+			"status":        models.OutboxStatusPending,
+			"claimed_by":    "",
+			"claimed_until": nil,
+		})
+
+	return r.logBulkUpdate(ctx, "retry", result)
+}
+
+// ResetRetryCount resets the retry count of events that are not published or expired
+func (r *outboxRepository) ResetRetryCount(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("id IN ? AND status IN ?", ids, []models.OutboxStatus{models.OutboxStatusPending, models.OutboxStatusFailed, models.OutboxStatusDeadLetter}).
+		Update("retry_count", 0)
+
+	return r.logBulkUpdate(ctx, "reset_retry_count", result)
+}
+
+// DeadLetterEvents parks pending and failed events that no worker is publishing
+func (r *outboxRepository) DeadLetterEvents(ctx context.Context, ids []uuid.UUID, reason string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("id IN ? AND status IN ?", ids, []models.OutboxStatus{models.OutboxStatusPending, models.OutboxStatusFailed}).
+		Where("claimed_until IS NULL OR claimed_until < ?", time.Now()).
+		Updates(map[string]interface{}{
+			"status":        models.OutboxStatusDeadLetter,
+			"error_message": reason,
+			"claimed_by":    "",
+			"claimed_until": nil,
+		})
+
+	return r.logBulkUpdate(ctx, "dead_letter", result)
+}
+
+// DeleteEvents soft deletes events that are out of delivery and that no worker is publishing
+func (r *outboxRepository) DeleteEvents(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.WithContext(ctx).
+		Where("id IN ? AND status IN ?", ids, []models.OutboxStatus{models.OutboxStatusPublished, models.OutboxStatusFailed, models.OutboxStatusExpired, models.OutboxStatusDeadLetter}).
+		Where("claimed_until IS NULL OR claimed_until < ?", time.Now()).
+		Delete(&models.OutboxEvent{})
+
+	return r.logBulkUpdate(ctx, "delete", result)
+}
+
+// logBulkUpdate logs a statement changing many events and returns its outcome
+func (r *outboxRepository) logBulkUpdate(ctx context.Context, operation string, result *gorm.DB) (int64, error) {
+	if result.Error != nil {
+		r.logger.ErrorContext(ctx, "Outbox bulk update failed", "operation", operation, "error", result.Error)
+		return result.RowsAffected, result.Error
+	}
+	if result.RowsAffected > 0 {
+		r.logger.DebugContext(ctx, "Outbox bulk update", "operation", operation, "rows", result.RowsAffected)
+	}
+	return result.RowsAffected, nil
 }
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+)
+
+// ErrReplayJobNotFound is returned when a replay job does not exist
+var ErrReplayJobNotFound = fmt.Errorf("replay job not found")
+
+type ReplayRepository interface {
+	Create(ctx context.Context, job *models.ReplayJob) error
+	GetByID(ctx context.Context, id string) (*models.ReplayJob, error)
+	List(ctx context.Context, limit int) ([]models.ReplayJob, error)
+	Save(ctx context.Context, job *models.ReplayJob) error
+}
+
+type replayRepository struct {
+	db *gorm.DB
+}
+
+func NewReplayRepository(db *gorm.DB) ReplayRepository {
+	return &replayRepository{db: db}
+}
+
+// Create creates a new replay job
+func (r *replayRepository) Create(ctx context.Context, job *models.ReplayJob) error {
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+// GetByID gets a replay job by ID
+func (r *replayRepository) GetByID(ctx context.Context, id string) (*models.ReplayJob, error) {
+	var job models.ReplayJob
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&job).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("%w with id: %s", ErrReplayJobNotFound, id)
+		}
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// List lists the most recent replay jobs
+func (r *replayRepository) List(ctx context.Context, limit int) ([]models.ReplayJob, error) {
+	var jobs []models.ReplayJob
+	err := r.db.WithContext(ctx).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&jobs).Error
+
+	return jobs, err
+}
+
+// Save saves the status and checkpoint of a replay job
+func (r *replayRepository) Save(ctx context.Context, job *models.ReplayJob) error {
+	return r.db.WithContext(ctx).Save(job).Error
+}
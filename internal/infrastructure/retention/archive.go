@@ -0,0 +1,185 @@
+package retention
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+)
+
+const indexFileName = "index.ndjson"
+
+// Segment describes an archive segment file in the archive index
+type Segment struct {
+	File           string    `json:"file"`
+	Rows           int       `json:"rows"`
+	FirstCreatedAt time.Time `json:"first_created_at"`
+	LastCreatedAt  time.Time `json:"last_created_at"`
+	ArchivedAt     time.Time `json:"archived_at"`
+}
+
+// Archive stores outbox events as gzip compressed NDJSON segment files on local disk
+type Archive struct {
+	dir string
+}
+
+// NewArchive creates a new Archive in the given directory
+func NewArchive(dir string) (*Archive, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create archive dir %s: %w", dir, err)
+	}
+	return &Archive{dir: dir}, nil
+}
+
+// Write writes the events to a new segment and registers it in the index.
+// The segment is synced to disk before it is indexed, so rows are only purged once archived.
+func (a *Archive) Write(events []models.OutboxEvent) (*Segment, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	segment := &Segment{
+		File:           fmt.Sprintf("outbox-%s-%s.ndjson.gz", now.Format("20060102T150405Z"), uuid.New().String()[:8]),
+		Rows:           len(events),
+		FirstCreatedAt: events[0].CreatedAt,
+		LastCreatedAt:  events[0].CreatedAt,
+		ArchivedAt:     now,
+	}
+	for _, event := range events {
+		if event.CreatedAt.Before(segment.FirstCreatedAt) {
+			segment.FirstCreatedAt = event.CreatedAt
+		}
+		if event.CreatedAt.After(segment.LastCreatedAt) {
+			segment.LastCreatedAt = event.CreatedAt
+		}
+	}
+
+	path := filepath.Join(a.dir, segment.File)
+	tmpPath := path + ".tmp"
+
+	if err := writeSegment(tmpPath, events); err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to finalize segment %s: %w", segment.File, err)
+	}
+
+	if err := a.appendIndex(segment); err != nil {
+		return nil, err
+	}
+
+	return segment, nil
+}
+
+// Segments returns the segments registered in the index
+func (a *Archive) Segments() ([]Segment, error) {
+	file, err := os.Open(filepath.Join(a.dir, indexFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Segment{}, nil
+		}
+		return nil, fmt.Errorf("failed to open archive index: %w", err)
+	}
+	defer file.Close()
+
+	var segments []Segment
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var segment Segment
+		if err := json.Unmarshal(scanner.Bytes(), &segment); err != nil {
+			return nil, fmt.Errorf("invalid archive index entry: %w", err)
+		}
+		segments = append(segments, segment)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read archive index: %w", err)
+	}
+
+	return segments, nil
+}
+
+// Read reads the events stored in a segment
+func (a *Archive) Read(segmentFile string) ([]models.OutboxEvent, error) {
+	file, err := os.Open(filepath.Join(a.dir, filepath.Base(segmentFile)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open segment %s: %w", segmentFile, err)
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress segment %s: %w", segmentFile, err)
+	}
+	defer reader.Close()
+
+	var events []models.OutboxEvent
+	decoder := json.NewDecoder(reader)
+	for decoder.More() {
+		var event models.OutboxEvent
+		if err := decoder.Decode(&event); err != nil {
+			return nil, fmt.Errorf("invalid event in segment %s: %w", segmentFile, err)
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// writeSegment writes the events as gzip compressed NDJSON and syncs the file
+func writeSegment(path string, events []models.OutboxEvent) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create segment: %w", err)
+	}
+	defer file.Close()
+
+	writer := gzip.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to compress segment: %w", err)
+	}
+
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync segment: %w", err)
+	}
+
+	return nil
+}
+
+// appendIndex registers a segment in the archive index
+func (a *Archive) appendIndex(segment *Segment) error {
+	file, err := os.OpenFile(filepath.Join(a.dir, indexFileName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open archive index: %w", err)
+	}
+	defer file.Close()
+
+	line, err := json.Marshal(segment)
+	if err != nil {
+		return fmt.Errorf("failed to encode index entry: %w", err)
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write archive index: %w", err)
+	}
+
+	return file.Sync()
+}
@@ -0,0 +1,204 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+// Result summarizes a retention run
+type Result struct {
+	Purged   int
+	Archived int
+	Segments []string
+}
+
+// Job permanently deletes published outbox events after the retention period,
+// optionally archiving them first
+type Job struct {
+	config     config.RetentionConfig
+	outboxRepo repositories.OutboxRepository
+	archive    *Archive
+	metrics    *metrics.Metrics
+	logger     *slog.Logger
+	stopChan   chan struct{}
+	wg         sync.WaitGroup
+}
+
+// NewJob creates a new retention Job
+func NewJob(cfg config.RetentionConfig, outboxRepo repositories.OutboxRepository, metrics *metrics.Metrics, logger *slog.Logger) (*Job, error) {
+	job := &Job{
+		config:     cfg,
+		outboxRepo: outboxRepo,
+		metrics:    metrics,
+		logger:     logging.OrDefault(logger),
+		stopChan:   make(chan struct{}),
+	}
+
+	if cfg.ArchiveEnabled {
+		archive, err := NewArchive(cfg.ArchiveDir)
+		if err != nil {
+			return nil, err
+		}
+		job.archive = archive
+	}
+
+	return job, nil
+}
+
+// Start runs the retention job periodically until the context is cancelled or Stop is called
+func (j *Job) Start(ctx context.Context) {
+	j.logger.InfoContext(ctx, "Starting retention job",
+		"period", j.config.Period,
+		"interval", j.config.Interval,
+		"batch_size", j.config.BatchSize,
+		"archive", j.config.ArchiveEnabled)
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(j.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-j.stopChan:
+				return
+			case <-ticker.C:
+				if _, err := j.Run(ctx); err != nil {
+					j.logger.ErrorContext(ctx, "Retention run failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic retention job
+func (j *Job) Stop() {
+	close(j.stopChan)
+	j.wg.Wait()
+}
+
+// Run purges the published events older than the retention period in bounded batches
+func (j *Job) Run(ctx context.Context) (*Result, error) {
+	cutoff := time.Now().Add(-j.config.Period)
+	result := &Result{}
+
+	for batch := 0; batch < j.config.MaxBatchesPerRun; batch++ {
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		default:
+		}
+
+		events, err := j.outboxRepo.GetPublishedEventsBefore(ctx, cutoff, j.config.BatchSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to fetch events to purge: %w", err)
+		}
+
+		if len(events) == 0 {
+			break
+		}
+
+		if j.archive != nil {
+			segment, err := j.archive.Write(events)
+			if err != nil {
+				return result, fmt.Errorf("failed to archive events: %w", err)
+			}
+			result.Archived += segment.Rows
+			result.Segments = append(result.Segments, segment.File)
+			j.recordArchived(segment.Rows)
+		}
+
+		ids := make([]uuid.UUID, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+		}
+
+		purged, err := j.outboxRepo.HardDeleteEvents(ctx, ids)
+		if err != nil {
+			return result, fmt.Errorf("failed to purge events: %w", err)
+		}
+		result.Purged += int(purged)
+		j.recordPurged(int(purged))
+
+		if len(events) < j.config.BatchSize {
+			break
+		}
+	}
+
+	if result.Purged > 0 {
+		j.logger.InfoContext(ctx, "Retention run purged events",
+			"purged", result.Purged, "archived", result.Archived, "cutoff", cutoff)
+	}
+
+	if err := j.purgeTransactions(ctx, cutoff); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// purgeTransactions deletes the event counts of the transactions older than cutoff, whose
+// events were all claimed and carry the count themselves
+func (j *Job) purgeTransactions(ctx context.Context, cutoff time.Time) error {
+	for batch := 0; batch < j.config.MaxBatchesPerRun; batch++ {
+		deleted, err := j.outboxRepo.DeleteTransactionsBefore(ctx, cutoff, j.config.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to purge transaction event counts: %w", err)
+		}
+		if deleted < int64(j.config.BatchSize) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Restore inserts the events of an archived segment back into the outbox
+func (j *Job) Restore(ctx context.Context, segmentFile string) (int, error) {
+	if j.archive == nil {
+		return 0, fmt.Errorf("archiving is not enabled")
+	}
+
+	events, err := j.archive.Read(segmentFile)
+	if err != nil {
+		return 0, err
+	}
+
+	restored, err := j.outboxRepo.RestoreEvents(ctx, events)
+	if err != nil {
+		return 0, fmt.Errorf("failed to restore segment %s: %w", segmentFile, err)
+	}
+
+	j.logger.InfoContext(ctx, "Restored archived segment", "segment", segmentFile, "restored", restored, "events", len(events))
+	return int(restored), nil
+}
+
+// Archive returns the archive used by the job, or nil when archiving is disabled
+func (j *Job) Archive() *Archive {
+	return j.archive
+}
+
+func (j *Job) recordPurged(count int) {
+	if j.metrics != nil {
+		j.metrics.RecordRowsPurged(count)
+	}
+}
+
+func (j *Job) recordArchived(count int) {
+	if j.metrics != nil {
+		j.metrics.RecordRowsArchived(count)
+	}
+}
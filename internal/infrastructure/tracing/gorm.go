@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "txstream:span"
+
+// GormPlugin creates a span for every GORM statement, as a child of the span in the
+// statement context
+type GormPlugin struct{}
+
+// NewGormPlugin creates a new GormPlugin
+func NewGormPlugin() *GormPlugin {
+	return &GormPlugin{}
+}
+
+// Name returns the plugin name
+func (p *GormPlugin) Name() string {
+	return "txstream:tracing"
+}
+
+// Initialize registers the callbacks around each GORM operation
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("txstream:before_create", before("create")),
+		callbacks.Create().After("gorm:create").Register("txstream:after_create", after),
+		callbacks.Query().Before("gorm:query").Register("txstream:before_query", before("query")),
+		callbacks.Query().After("gorm:query").Register("txstream:after_query", after),
+		callbacks.Update().Before("gorm:update").Register("txstream:before_update", before("update")),
+		callbacks.Update().After("gorm:update").Register("txstream:after_update", after),
+		callbacks.Delete().Before("gorm:delete").Register("txstream:before_delete", before("delete")),
+		callbacks.Delete().After("gorm:delete").Register("txstream:after_delete", after),
+		callbacks.Row().Before("gorm:row").Register("txstream:before_row", before("row")),
+		callbacks.Row().After("gorm:row").Register("txstream:after_row", after),
+		callbacks.Raw().Before("gorm:raw").Register("txstream:before_raw", before("raw")),
+		callbacks.Raw().After("gorm:raw").Register("txstream:after_raw", after),
+	)
+}
+
+func before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement.Context == nil {
+			return
+		}
+
+		ctx, span := Tracer().Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(operation)),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func after(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBCollectionName(db.Statement.Table),
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && db.Error != gorm.ErrRecordNotFound {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
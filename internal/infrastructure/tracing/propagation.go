@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+)
+
+// Keys of the W3C trace context in the event metadata and in the Kafka headers
+const (
+	TraceParentKey = "traceparent"
+	TraceStateKey  = "tracestate"
+)
+
+// InjectMetadata stores the trace context of the span in ctx in the event metadata, so
+// the publication of the event can be linked to the request that created it
+func InjectMetadata(ctx context.Context, metadata models.JSON) {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	for _, key := range []string{TraceParentKey, TraceStateKey} {
+		if value := carrier.Get(key); value != "" {
+			metadata[key] = value
+		}
+	}
+}
+
+// Headers returns the trace context of the span in ctx as message headers
+func Headers(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	return carrier
+}
+
+// SpanContextFromMetadata returns the trace context stored in the event metadata, invalid
+// when the event was created outside of a trace
+func SpanContextFromMetadata(metadata models.JSON) trace.SpanContext {
+	carrier := propagation.MapCarrier{}
+	for _, key := range []string{TraceParentKey, TraceStateKey} {
+		if value, ok := metadata[key].(string); ok {
+			carrier.Set(key, value)
+		}
+	}
+
+	ctx := propagation.TraceContext{}.Extract(context.Background(), carrier)
+	return trace.SpanContextFromContext(ctx)
+}
+
+// StartPublishSpan starts the span of the publication of an outbox event. The event is
+// published long after the request that created it, so the span starts a new trace linked
+// to the trace stored in the event metadata instead of continuing it.
+func StartPublishSpan(ctx context.Context, event *models.OutboxEvent, topic string) (context.Context, trace.Span) {
+	options := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithNewRoot(),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(topic),
+			semconv.MessagingMessageID(event.ID.String()),
+			attribute.String("txstream.event_type", event.EventType),
+			attribute.String("txstream.aggregate_id", event.AggregateID),
+		),
+	}
+	if origin := SpanContextFromMetadata(event.EventMetadata); origin.IsValid() {
+		options = append(options, trace.WithLinks(trace.Link{SpanContext: origin}))
+	}
+
+	return Tracer().Start(ctx, topic+" publish", options...)
+}
+
+// StartConsumeSpan starts the span of the processing of a consumed message, as a child of
+// the publish span whose trace context is carried by the message headers
+func StartConsumeSpan(ctx context.Context, topic string, headers map[string]string, eventID, eventType string) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+
+	return Tracer().Start(ctx, topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeDeliver,
+			semconv.MessagingDestinationName(topic),
+			semconv.MessagingMessageID(eventID),
+			attribute.String("txstream.event_type", eventType),
+		),
+	)
+}
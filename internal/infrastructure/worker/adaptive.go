@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+)
+
+// latencySmoothing is the weight of the newest sample in the latency moving averages
+const latencySmoothing = 0.3
+
+// AdaptiveController adjusts the polling interval and batch size of the worker.
+// Full batches drain the queue without waiting, idle polls and polls with failures back off
+// exponentially and the batch size follows the measured publish latency and DB query time.
+type AdaptiveController struct {
+	minInterval         time.Duration
+	maxInterval         time.Duration
+	minBatchSize        int
+	maxBatchSize        int
+	targetBatchDuration time.Duration
+
+	interval  time.Duration
+	batchSize int
+
+	avgQueryTime      time.Duration
+	avgPublishLatency time.Duration
+}
+
+// NewAdaptiveController creates a new AdaptiveController starting from the configured interval and batch size
+func NewAdaptiveController(cfg config.WorkerConfig) *AdaptiveController {
+	return &AdaptiveController{
+		minInterval:         cfg.MinInterval,
+		maxInterval:         cfg.MaxInterval,
+		minBatchSize:        cfg.MinBatchSize,
+		maxBatchSize:        cfg.MaxBatchSize,
+		targetBatchDuration: cfg.TargetBatchDuration,
+		interval:            clampDuration(cfg.Interval, cfg.MinInterval, cfg.MaxInterval),
+		batchSize:           clampInt(cfg.BatchSize, cfg.MinBatchSize, cfg.MaxBatchSize),
+	}
+}
+
+// Interval returns the time to wait before the next poll
+func (c *AdaptiveController) Interval() time.Duration {
+	return c.interval
+}
+
+// BatchSize returns the number of events to fetch in the next poll
+func (c *AdaptiveController) BatchSize() int {
+	return c.batchSize
+}
+
+// Set overrides the current interval and batch size, clamped to the configured bounds.
+// Adaptation continues from the new values.
+func (c *AdaptiveController) Set(interval time.Duration, batchSize int) {
+	c.interval = clampDuration(interval, c.minInterval, c.maxInterval)
+	c.batchSize = clampInt(batchSize, c.minBatchSize, c.maxBatchSize)
+}
+
+// Observe records the outcome of a poll: the number of events published and failed with the
+// requested batch size, the time spent querying them and the average processing latency per
+// event. A poll with failed events backs off like an idle poll, so an outage of the broker or
+// the database does not keep the worker polling without waiting.
+func (c *AdaptiveController) Observe(published, failed, requested int, queryTime, publishLatency time.Duration) {
+	c.avgQueryTime = smooth(c.avgQueryTime, queryTime)
+	if failed > 0 {
+		c.interval = clampDuration(c.interval*2, c.minInterval, c.maxInterval)
+		return
+	}
+
+	if published > 0 {
+		c.avgPublishLatency = smooth(c.avgPublishLatency, publishLatency)
+	}
+
+	switch {
+	case published >= requested:
+		c.interval = 0
+	case published > 0:
+		c.interval = c.minInterval
+	case c.interval < c.minInterval:
+		c.interval = c.minInterval
+	default:
+		c.interval = clampDuration(c.interval*2, c.minInterval, c.maxInterval)
+	}
+
+	if published == 0 || c.avgPublishLatency <= 0 {
+		return
+	}
+
+	// Size the next batch so that querying and publishing it fits in the target duration,
+	// moving at most by a factor of two per poll to avoid oscillation
+	budget := c.targetBatchDuration - c.avgQueryTime
+	ideal := c.minBatchSize
+	if budget > 0 {
+		ideal = int(budget / c.avgPublishLatency)
+	}
+	ideal = clampInt(ideal, c.batchSize/2, c.batchSize*2)
+
+	// Only a full batch proves there is enough backlog to grow into
+	if published < requested && ideal > c.batchSize {
+		ideal = c.batchSize
+	}
+	c.batchSize = clampInt(ideal, c.minBatchSize, c.maxBatchSize)
+}
+
+func smooth(average, sample time.Duration) time.Duration {
+	if average <= 0 {
+		return sample
+	}
+	return time.Duration(latencySmoothing*float64(sample) + (1-latencySmoothing)*float64(average))
+}
+
+func clampDuration(value, min, max time.Duration) time.Duration {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+func clampInt(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
@@ -0,0 +1,127 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidSettings is returned when a runtime settings update is rejected
+var ErrInvalidSettings = errors.New("invalid worker settings")
+
+// RuntimeSettings are the worker settings that can be changed without a restart
+type RuntimeSettings struct {
+	BatchSize int
+	Interval  time.Duration
+	PoolSize  int
+}
+
+// SettingsUpdate holds the runtime settings to change, nil fields are left unchanged
+type SettingsUpdate struct {
+	BatchSize *int
+	Interval  *time.Duration
+	PoolSize  *int
+}
+
+// Pause stops claiming new events. The events already claimed are still published.
+func (w *OutboxWorker) Pause() {
+	if w.paused.CompareAndSwap(false, true) {
+		w.logger.Info("OutboxWorker paused")
+		w.metrics.SetWorkerPaused(true)
+	}
+}
+
+// Resume resumes claiming events after a pause or a drain
+func (w *OutboxWorker) Resume() {
+	if w.paused.CompareAndSwap(true, false) {
+		w.logger.Info("OutboxWorker resumed")
+		w.metrics.SetWorkerPaused(false)
+	}
+}
+
+// IsPaused returns true if the worker is paused
+func (w *OutboxWorker) IsPaused() bool {
+	return w.paused.Load()
+}
+
+// Drain pauses the worker and waits until the batch being processed completes, so no
+// event is in flight when it returns. The worker stays paused until Resume is called.
+func (w *OutboxWorker) Drain(ctx context.Context) error {
+	w.Pause()
+
+	select {
+	case w.batchSem <- struct{}{}:
+		<-w.batchSem
+		w.logger.Info("OutboxWorker drained")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("drain incomplete with %d events in flight: %w", w.InFlight(), ctx.Err())
+	}
+}
+
+// InFlight returns the number of events currently claimed and being published
+func (w *OutboxWorker) InFlight() int {
+	return len(w.inflight.snapshot())
+}
+
+// Settings returns the effective runtime settings. With adaptive polling the batch
+// size and interval are the values currently chosen by the adaptive controller.
+func (w *OutboxWorker) Settings() RuntimeSettings {
+	w.tuneMu.Lock()
+	defer w.tuneMu.Unlock()
+
+	settings := w.settings
+	if w.adaptive != nil {
+		settings.BatchSize = w.adaptive.BatchSize()
+		settings.Interval = w.adaptive.Interval()
+	}
+	return settings
+}
+
+// UpdateSettings changes the runtime settings. With adaptive polling the new batch size
+// and interval are clamped to the adaptive bounds and adaptation continues from them.
+func (w *OutboxWorker) UpdateSettings(update SettingsUpdate) (RuntimeSettings, error) {
+	if update.BatchSize != nil && *update.BatchSize <= 0 {
+		return w.Settings(), fmt.Errorf("%w: batch size must be positive", ErrInvalidSettings)
+	}
+	if update.Interval != nil && *update.Interval <= 0 {
+		return w.Settings(), fmt.Errorf("%w: interval must be positive", ErrInvalidSettings)
+	}
+	if update.PoolSize != nil && *update.PoolSize <= 0 {
+		return w.Settings(), fmt.Errorf("%w: pool size must be positive", ErrInvalidSettings)
+	}
+
+	w.tuneMu.Lock()
+	if update.BatchSize != nil {
+		w.settings.BatchSize = *update.BatchSize
+	}
+	if update.Interval != nil {
+		w.settings.Interval = *update.Interval
+	}
+	if update.PoolSize != nil {
+		w.settings.PoolSize = *update.PoolSize
+	}
+	if w.adaptive != nil && (update.BatchSize != nil || update.Interval != nil) {
+		interval, batchSize := w.adaptive.Interval(), w.adaptive.BatchSize()
+		if update.Interval != nil {
+			interval = *update.Interval
+		}
+		if update.BatchSize != nil {
+			batchSize = *update.BatchSize
+		}
+		w.adaptive.Set(interval, batchSize)
+	}
+	w.tuneMu.Unlock()
+
+	settings := w.Settings()
+	w.metrics.SetBatchSize(settings.BatchSize)
+	w.metrics.SetPollInterval(settings.Interval)
+	w.metrics.SetWorkerPoolSize(settings.PoolSize)
+
+	w.logger.Info("OutboxWorker settings updated",
+		"batch_size", settings.BatchSize,
+		"interval", settings.Interval,
+		"pool_size", settings.PoolSize)
+	return settings, nil
+}
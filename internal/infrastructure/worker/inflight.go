@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+)
+
+// inflightTracker keeps track of the events claimed by the worker that are still being published
+type inflightTracker struct {
+	mu     sync.Mutex
+	events map[uuid.UUID]models.OutboxEvent
+}
+
+func newInflightTracker() *inflightTracker {
+	return &inflightTracker{
+		events: make(map[uuid.UUID]models.OutboxEvent),
+	}
+}
+
+// add starts tracking an event and returns the number of events in flight
+func (t *inflightTracker) add(event models.OutboxEvent) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.events[event.ID] = event
+	return len(t.events)
+}
+
+// remove stops tracking an event and returns the number of events in flight
+func (t *inflightTracker) remove(id uuid.UUID) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.events, id)
+	return len(t.events)
+}
+
+// snapshot returns the events currently in flight
+func (t *inflightTracker) snapshot() []models.OutboxEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	events := make([]models.OutboxEvent, 0, len(t.events))
+	for _, event := range t.events {
+		events = append(events, event)
+	}
+	return events
+}
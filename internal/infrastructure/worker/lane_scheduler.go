@@ -0,0 +1,122 @@
+package worker
+
+import (
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+)
+
+// LaneScheduler splits the worker batches across priority lanes using weighted fair scheduling,
+// so high priority events are favoured without completely starving the lower lanes
+type LaneScheduler struct {
+	weights map[models.OutboxPriority]int
+	current map[models.OutboxPriority]int
+}
+
+// NewLaneScheduler creates a new LaneScheduler instance
+func NewLaneScheduler(weights map[models.OutboxPriority]int) *LaneScheduler {
+	laneWeights := make(map[models.OutboxPriority]int, len(models.OutboxPriorities))
+	for _, priority := range models.OutboxPriorities {
+		weight := weights[priority]
+		if weight <= 0 {
+			weight = 1
+		}
+		laneWeights[priority] = weight
+	}
+
+	return &LaneScheduler{
+		weights: laneWeights,
+		current: make(map[models.OutboxPriority]int, len(models.OutboxPriorities)),
+	}
+}
+
+// PriorityWeights maps the configured weights, keyed by lane name, to the priority lanes
+func PriorityWeights(weights map[string]int) map[models.OutboxPriority]int {
+	priorityWeights := make(map[models.OutboxPriority]int, len(weights))
+	for _, priority := range models.OutboxPriorities {
+		if weight, ok := weights[priority.String()]; ok {
+			priorityWeights[priority] = weight
+		}
+	}
+	return priorityWeights
+}
+
+// Quotas returns how many events each lane may fetch in a batch of the given size.
+// Every lane gets at least one slot when the batch is large enough to hold all lanes.
+func (s *LaneScheduler) Quotas(batchSize int) map[models.OutboxPriority]int {
+	quotas := make(map[models.OutboxPriority]int, len(models.OutboxPriorities))
+	if batchSize <= 0 {
+		return quotas
+	}
+
+	totalWeight := 0
+	for _, priority := range models.OutboxPriorities {
+		totalWeight += s.weights[priority]
+	}
+
+	assigned := 0
+	for _, priority := range models.OutboxPriorities {
+		quota := batchSize * s.weights[priority] / totalWeight
+		if quota == 0 && batchSize >= len(models.OutboxPriorities) {
+			quota = 1
+		}
+		quotas[priority] = quota
+		assigned += quota
+	}
+
+	// Distribute the rounding remainder from the highest lane down, and take back any
+	// excess created by the minimum slot from the lowest lane up
+	for i := 0; assigned < batchSize; i = (i + 1) % len(models.OutboxPriorities) {
+		quotas[models.OutboxPriorities[i]]++
+		assigned++
+	}
+	for assigned > batchSize {
+		for i := len(models.OutboxPriorities) - 1; i >= 0 && assigned > batchSize; i-- {
+			priority := models.OutboxPriorities[i]
+			if quotas[priority] > 1 {
+				quotas[priority]--
+				assigned--
+			}
+		}
+	}
+
+	return quotas
+}
+
+// Interleave merges the events fetched from each lane into a single processing order
+// using smooth weighted round robin, keeping the created_at order within every lane
+func (s *LaneScheduler) Interleave(lanes map[models.OutboxPriority][]models.OutboxEvent) []models.OutboxEvent {
+	total := 0
+	for _, events := range lanes {
+		total += len(events)
+	}
+
+	ordered := make([]models.OutboxEvent, 0, total)
+	positions := make(map[models.OutboxPriority]int, len(lanes))
+
+	for len(ordered) < total {
+		activeWeight := 0
+		selected := models.OutboxPriorityNormal
+		selectedWeight := 0
+		found := false
+
+		for _, priority := range models.OutboxPriorities {
+			if positions[priority] >= len(lanes[priority]) {
+				continue
+			}
+
+			s.current[priority] += s.weights[priority]
+			activeWeight += s.weights[priority]
+
+			if !found || s.current[priority] > selectedWeight {
+				selected = priority
+				selectedWeight = s.current[priority]
+				found = true
+			}
+		}
+
+		s.current[selected] -= activeWeight
+		ordered = append(ordered, lanes[selected][positions[selected]])
+		positions[selected]++
+	}
+
+	return ordered
+}
@@ -2,16 +2,51 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
 	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/kafka"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/tracing"
+)
+
+var (
+	// errEventThrottled is returned when an event is left pending by the publish rate limits
+	errEventThrottled = errors.New("event throttled by rate limit")
+	// errWorkerStopping is returned when an event is not claimed because the worker is stopping
+	errWorkerStopping = errors.New("worker is stopping")
+	// errWorkerPaused is returned when an event is not claimed because the worker is paused
+	errWorkerPaused = errors.New("worker is paused")
+	// errEventClaimedElsewhere is returned when another worker holds the claim on an event or
+	// already took it out of pending, so the later events of its aggregate wait for it
+	errEventClaimedElsewhere = errors.New("event claimed by another worker")
+	// errEventPermanentlyFailed is returned for an event out of retries, which holds back the
+	// later events of its aggregate until an operator retries or dead letters it
+	errEventPermanentlyFailed = errors.New("event permanently failed")
+)
+
+const (
+	// abandonGracePeriod bounds the wait for the cancelled publishes to return once the
+	// shutdown deadline is exceeded
+	abandonGracePeriod = 5 * time.Second
+	// markTimeout bounds the update recording a completed publish
+	markTimeout = 5 * time.Second
+	// releaseTimeout bounds the release of the claims left by an incomplete shutdown
+	releaseTimeout = 5 * time.Second
 )
 
 // OutboxWorker processes outbox events and publishes them to Kafka
@@ -20,39 +55,83 @@ type OutboxWorker struct {
 	outboxRepo repositories.OutboxRepository
 	producer   kafka.EventProducer
 	metrics    *metrics.Metrics
-	stopChan   chan struct{}
-	wg         sync.WaitGroup
+	eventTTLs  map[string]time.Duration
+	scheduler  *LaneScheduler
+	limiter    *RateLimiter
+	adaptive   *AdaptiveController
+	inflight   *inflightTracker
+	workerID   string
+	logger     *slog.Logger
+
+	// tuneMu guards the runtime settings and the adaptive controller, which are
+	// changed by the control-plane API while the worker is running
+	tuneMu   sync.Mutex
+	settings RuntimeSettings
+	paused   atomic.Bool
+	// batchSem is held while a batch is processed, so a drain can wait for it
+	batchSem chan struct{}
+
+	// processCtx is used for the in-flight publishes and is only cancelled when the
+	// shutdown deadline is exceeded, so a stop signal never interrupts a publish midway
+	processCtx    context.Context
+	cancelProcess context.CancelFunc
+
+	stopChan chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
 }
 
-// NewOutboxWorker creates a new OutboxWorker instance
-func NewOutboxWorker(cfg *config.Config, outboxRepo repositories.OutboxRepository, producer kafka.EventProducer) *OutboxWorker {
-	metrics := metrics.NewMetrics()
+// NewOutboxWorker creates a new OutboxWorker instance. A nil metrics records on a private
+// registry.
+func NewOutboxWorker(cfg *config.Config, outboxRepo repositories.OutboxRepository, producer kafka.EventProducer, workerMetrics *metrics.Metrics, logger *slog.Logger) *OutboxWorker {
+	if workerMetrics == nil {
+		workerMetrics = metrics.NewMetrics(nil)
+	}
+	processCtx, cancelProcess := context.WithCancel(context.Background())
+	workerID := newWorkerID()
 
 	worker := &OutboxWorker{
 		config:     cfg,
 		outboxRepo: outboxRepo,
 		producer:   producer,
-		metrics:    metrics,
+		metrics:    workerMetrics,
+		eventTTLs:  cfg.Worker.GetEventTTLs(),
+		scheduler:  NewLaneScheduler(PriorityWeights(cfg.Worker.GetPriorityWeights())),
+		limiter:    NewRateLimiter(cfg.Worker.GetTopicRateLimits(), cfg.Worker.GetEventTypeRateLimits()),
+		inflight:   newInflightTracker(),
+		workerID:   workerID,
+		logger:     logging.OrDefault(logger).With(logging.WorkerIDKey, workerID),
 		stopChan:   make(chan struct{}),
+		batchSem:   make(chan struct{}, 1),
+
+		settings: RuntimeSettings{
+			BatchSize: cfg.Worker.BatchSize,
+			Interval:  cfg.Worker.Interval,
+			PoolSize:  cfg.Worker.PoolSize,
+		},
+
+		processCtx:    processCtx,
+		cancelProcess: cancelProcess,
 	}
 
-	if cfg.Metrics.Enabled {
-		if err := metrics.StartMetricsServer(cfg.Metrics.Port, cfg.Metrics.Path); err != nil {
-			log.Printf("Failed to start metrics server: %v", err)
-		} else {
-			log.Printf("Metrics server started on port %d at %s", cfg.Metrics.Port, cfg.Metrics.Path)
-		}
+	if cfg.Worker.AdaptiveEnabled {
+		worker.adaptive = NewAdaptiveController(cfg.Worker)
 	}
 
-	metrics.SetWorkerPoolSize(cfg.Worker.PoolSize)
+	workerMetrics.SetWorkerPoolSize(cfg.Worker.PoolSize)
+	workerMetrics.SetWorkerPaused(false)
+	workerMetrics.SetBatchSize(worker.batchSize())
+	workerMetrics.SetPollInterval(worker.pollInterval())
 
 	return worker
 }
 
 // Start begins processing outbox events
 func (w *OutboxWorker) Start(ctx context.Context) error {
-	log.Printf("Starting OutboxWorker with pool size: %d, batch size: %d, interval: %v",
-		w.config.Worker.PoolSize, w.config.Worker.BatchSize, w.config.Worker.Interval)
+	w.logger.InfoContext(ctx, "Starting OutboxWorker",
+		"pool_size", w.config.Worker.PoolSize,
+		"batch_size", w.config.Worker.BatchSize,
+		"interval", w.config.Worker.Interval)
 
 	w.wg.Add(1)
 	go w.run(ctx)
@@ -60,63 +139,250 @@ func (w *OutboxWorker) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop gracefully stops the worker
+// Stop gracefully stops the worker using the configured shutdown timeout
 func (w *OutboxWorker) Stop() {
-	log.Println("Stopping OutboxWorker...")
-	close(w.stopChan)
-	w.wg.Wait()
-	log.Println("OutboxWorker stopped")
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.Worker.ShutdownTimeout)
+	defer cancel()
+
+	if err := w.Shutdown(ctx); err != nil {
+		w.logger.Warn("OutboxWorker shutdown incomplete", "error", err)
+	}
+}
+
+// Shutdown stops the worker in two phases. It first stops claiming new events and lets
+// the in-flight publishes finish until the context deadline. When the deadline is exceeded
+// the remaining publishes are cancelled, the pool is given abandonGracePeriod to return and
+// the claims still held are then released, so another worker can pick the abandoned events up.
+func (w *OutboxWorker) Shutdown(ctx context.Context) error {
+	w.logger.Info("Stopping OutboxWorker, draining in-flight events")
+	w.stopOnce.Do(func() {
+		close(w.stopChan)
+	})
+
+	drained := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		w.cancelProcess()
+		w.logger.Info("OutboxWorker stopped")
+		return nil
+	case <-ctx.Done():
+	}
+
+	abandoned := w.inflight.snapshot()
+	w.cancelProcess()
+
+	for _, event := range abandoned {
+		w.logger.Warn("Abandoning in-flight event", logging.EventIDKey, event.ID, logging.EventTypeKey, event.EventType)
+		w.metrics.RecordEventAbandoned(event.EventType)
+	}
+
+	// Release the claims once the pool returned, so no publish still running can record
+	// its outcome on an event another worker already claimed
+	select {
+	case <-drained:
+	case <-time.After(abandonGracePeriod):
+		w.logger.Warn("Cancelled publishes did not return in time", "grace_period", abandonGracePeriod)
+	}
+
+	releaseCtx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+
+	released, err := w.outboxRepo.ReleaseClaims(releaseCtx, w.workerID)
+	if err != nil {
+		w.logger.Error("Failed to release claims held by worker", "error", err)
+	} else {
+		w.logger.Info("Released claims held by worker", "released", released)
+	}
+
+	return fmt.Errorf("shutdown deadline exceeded, abandoned %d in-flight events", len(abandoned))
 }
 
 // run is the main processing loop
 func (w *OutboxWorker) run(ctx context.Context) {
 	defer w.wg.Done()
 
-	ticker := time.NewTicker(w.config.Worker.Interval)
-	defer ticker.Stop()
+	timer := time.NewTimer(w.pollInterval())
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("Context cancelled, stopping worker")
+			w.logger.Info("Context cancelled, stopping worker")
 			return
 		case <-w.stopChan:
-			log.Println("Stop signal received, stopping worker")
+			w.logger.Info("Stop signal received, stopping worker")
 			return
-		case <-ticker.C:
-			w.processBatch(ctx)
+		case <-timer.C:
+			if !w.IsPaused() {
+				w.processBatch(ctx)
+			}
+			timer.Reset(w.pollInterval())
 		}
 	}
 }
 
+// pollInterval returns the time to wait before the next batch
+func (w *OutboxWorker) pollInterval() time.Duration {
+	w.tuneMu.Lock()
+	defer w.tuneMu.Unlock()
+
+	if w.adaptive != nil {
+		return w.adaptive.Interval()
+	}
+	return w.settings.Interval
+}
+
+// batchSize returns the number of events to fetch in the next batch
+func (w *OutboxWorker) batchSize() int {
+	w.tuneMu.Lock()
+	defer w.tuneMu.Unlock()
+
+	if w.adaptive != nil {
+		return w.adaptive.BatchSize()
+	}
+	return w.settings.BatchSize
+}
+
+// poolSize returns the number of goroutines processing a batch
+func (w *OutboxWorker) poolSize() int {
+	w.tuneMu.Lock()
+	defer w.tuneMu.Unlock()
+
+	return w.settings.PoolSize
+}
+
+// observeBatch feeds the outcome of a batch to the adaptive controller
+func (w *OutboxWorker) observeBatch(published, failed, requested int, queryTime, publishLatency time.Duration) {
+	w.tuneMu.Lock()
+	defer w.tuneMu.Unlock()
+
+	if w.adaptive == nil {
+		return
+	}
+
+	w.adaptive.Observe(published, failed, requested, queryTime, publishLatency)
+	w.metrics.SetBatchSize(w.adaptive.BatchSize())
+	w.metrics.SetPollInterval(w.adaptive.Interval())
+}
+
 // processBatch fetches and processes a batch of events
 func (w *OutboxWorker) processBatch(ctx context.Context) {
+	w.batchSem <- struct{}{}
+	defer func() {
+		<-w.batchSem
+	}()
+
 	timer := w.metrics.Timer()
 
-	events, err := w.outboxRepo.GetPendingEvents(ctx, w.config.Worker.BatchSize)
+	w.updateLaneMetrics(ctx)
+
+	batchSize := w.batchSize()
+	queryTimer := w.metrics.Timer()
+	events, err := w.fetchPendingEvents(ctx, batchSize)
+	queryTime := queryTimer.Duration()
 	if err != nil {
-		log.Printf("Failed to fetch pending events: %v", err)
+		w.logger.ErrorContext(ctx, "Failed to fetch pending events", "error", err)
 		w.metrics.RecordEventFailed("database_error", "unknown")
+		w.observeBatch(0, 1, batchSize, queryTime, 0)
 		return
 	}
 
-	w.metrics.SetEventsInQueue("pending", len(events))
-
 	if len(events) == 0 {
+		w.observeBatch(0, 0, batchSize, queryTime, 0)
 		return
 	}
 
-	log.Printf("Processing batch of %d events", len(events))
+	w.logger.DebugContext(ctx, "Processing batch", "events", len(events))
 
-	for _, event := range events {
-		if err := w.processEvent(ctx, &event); err != nil {
-			log.Printf("Failed to process event %s: %v", event.ID, err)
-		}
+	processTimer := w.metrics.Timer()
+	published, failed := w.dispatchEvents(w.processCtx, events)
+
+	var publishLatency time.Duration
+	if processed := published + failed; processed > 0 {
+		publishLatency = processTimer.Duration() / time.Duration(processed)
 	}
+	w.observeBatch(published, failed, batchSize, queryTime, publishLatency)
 
 	w.metrics.RecordEventProcessingDuration("batch", timer.Duration())
 }
 
+// dispatchEvents processes a batch with a ChainPool of the current pool size. Returns the
+// number of events completed and failed; skipped events and the events left behind a failure
+// are in neither.
+func (w *OutboxWorker) dispatchEvents(ctx context.Context, events []models.OutboxEvent) (published, failed int) {
+	var completed, errored int64
+
+	NewChainPool(w.poolSize(), w.metrics).Run(ctx, GroupByAggregate(events), func(ctx context.Context, event *models.OutboxEvent) error {
+		err := w.processEvent(ctx, event)
+		switch {
+		case err == nil:
+			atomic.AddInt64(&completed, 1)
+		case errors.Is(err, errEventThrottled), errors.Is(err, errWorkerStopping), errors.Is(err, errWorkerPaused),
+			errors.Is(err, errEventClaimedElsewhere), errors.Is(err, errEventPermanentlyFailed):
+		default:
+			atomic.AddInt64(&errored, 1)
+			w.logger.ErrorContext(ctx, "Failed to process event", logging.EventIDKey, event.ID, "error", err)
+		}
+		return err
+	})
+
+	return int(completed), int(errored)
+}
+
+// fetchPendingEvents fetches a batch of pending events split across the priority lanes
+func (w *OutboxWorker) fetchPendingEvents(ctx context.Context, batchSize int) ([]models.OutboxEvent, error) {
+	quotas := w.scheduler.Quotas(batchSize)
+	lanes := make(map[models.OutboxPriority][]models.OutboxEvent, len(quotas))
+
+	fetched := 0
+	for _, priority := range models.OutboxPriorities {
+		events, err := w.outboxRepo.GetPendingEventsByPriority(ctx, priority, quotas[priority])
+		if err != nil {
+			return nil, err
+		}
+		lanes[priority] = events
+		fetched += len(events)
+	}
+
+	// Hand the slots left unused by idle lanes to the busy ones, highest priority first
+	for _, priority := range models.OutboxPriorities {
+		remaining := batchSize - fetched
+		if remaining <= 0 {
+			break
+		}
+		if len(lanes[priority]) < quotas[priority] {
+			continue
+		}
+
+		events, err := w.outboxRepo.GetPendingEventsByPriority(ctx, priority, len(lanes[priority])+remaining)
+		if err != nil {
+			return nil, err
+		}
+		fetched += len(events) - len(lanes[priority])
+		lanes[priority] = events
+	}
+
+	return w.scheduler.Interleave(lanes), nil
+}
+
+// updateLaneMetrics reports the number of pending events in each priority lane
+func (w *OutboxWorker) updateLaneMetrics(ctx context.Context) {
+	counts, err := w.outboxRepo.CountPendingEventsByPriority(ctx)
+	if err != nil {
+		w.logger.ErrorContext(ctx, "Failed to count pending events by priority", "error", err)
+		return
+	}
+
+	for _, priority := range models.OutboxPriorities {
+		w.metrics.SetEventsInLane(priority.String(), int(counts[priority]))
+	}
+}
+
 // processEvent processes a single event
 func (w *OutboxWorker) processEvent(ctx context.Context, event *models.OutboxEvent) error {
 	ctx, timer := metrics.ContextWithTimer(ctx, w.metrics)
@@ -124,42 +390,73 @@ func (w *OutboxWorker) processEvent(ctx context.Context, event *models.OutboxEve
 		w.metrics.RecordEventProcessingDuration(event.EventType, timer.Duration())
 	}()
 
-	log.Printf("Processing event: %s, type: %s", event.ID, event.EventType)
+	ctx = eventContext(ctx, event)
+	w.logger.DebugContext(ctx, "Processing event")
 
-	lockedEvent, err := w.outboxRepo.GetPendingEventForUpdate(ctx, event.ID.String())
+	select {
+	case <-w.stopChan:
+		return errWorkerStopping
+	default:
+	}
+
+	if w.IsPaused() {
+		return errWorkerPaused
+	}
+
+	lockedEvent, err := w.outboxRepo.ClaimEvent(ctx, event.ID.String(), w.workerID, w.config.Worker.LeaseDuration)
 	if err != nil {
-		log.Printf("Failed to acquire lock for event %s: %v", event.ID, err)
+		if errors.Is(err, repositories.ErrEventNotClaimable) {
+			w.logger.DebugContext(ctx, "Event already claimed or no longer pending, skipping its aggregate")
+			w.metrics.RecordEventProcessed("already_claimed", event.EventType)
+			return errEventClaimedElsewhere
+		}
+
+		w.logger.ErrorContext(ctx, "Failed to claim event", "error", err)
 		w.metrics.RecordEventFailed("lock_error", event.EventType)
-		return fmt.Errorf("failed to acquire lock: %w", err)
+		return fmt.Errorf("failed to claim event: %w", err)
 	}
 
+	w.metrics.SetEventsInFlight(w.inflight.add(*lockedEvent))
+	defer func() {
+		w.metrics.SetEventsInFlight(w.inflight.remove(lockedEvent.ID))
+	}()
+
 	if lockedEvent.Status == models.OutboxStatusPublished {
-		log.Printf("Event %s already published, skipping", event.ID)
+		w.logger.DebugContext(ctx, "Event already published, skipping")
 		w.metrics.RecordEventProcessed("already_published", event.EventType)
 		return nil
 	}
 
 	if lockedEvent.Status == models.OutboxStatusFailed && lockedEvent.RetryCount >= w.config.Worker.MaxRetries {
-		log.Printf("Event %s permanently failed after %d retries", event.ID, lockedEvent.RetryCount)
+		w.logger.WarnContext(ctx, "Event permanently failed, holding back its aggregate", "retry_count", lockedEvent.RetryCount)
 		w.metrics.RecordEventProcessed("permanently_failed", event.EventType)
-		return nil
+		return errEventPermanentlyFailed
+	}
+
+	if expiresAt, expired := w.checkExpiry(lockedEvent, time.Now()); expired {
+		return w.handleExpiredEvent(ctx, lockedEvent, expiresAt)
+	}
+
+	if err := w.waitForRateLimit(ctx, w.config.Kafka.TopicEvents, lockedEvent); err != nil {
+		w.releaseClaim(ctx, lockedEvent)
+		return err
 	}
 
 	publishTimer := w.metrics.Timer()
-	err = w.producer.PublishEvent(ctx, lockedEvent)
+	receipt, err := w.publish(ctx, lockedEvent)
 	publishDuration := publishTimer.Duration()
 
 	if err != nil {
-		log.Printf("Failed to publish event %s: %v", event.ID, err)
+		w.logger.ErrorContext(ctx, "Failed to publish event", "error", err)
 
 		w.metrics.RecordEventFailed("publish_error", event.EventType)
 		w.metrics.RecordEventPublishingDuration(w.config.Kafka.TopicEvents, event.EventType, publishDuration)
 
-		return w.handlePublishError(lockedEvent, err)
+		return w.handlePublishError(ctx, lockedEvent, err)
 	}
 
-	if err := w.outboxRepo.MarkAsPublished(ctx, lockedEvent.ID.String()); err != nil {
-		log.Printf("Failed to mark event %s as published: %v", event.ID, err)
+	if err := w.markAsPublished(ctx, lockedEvent, receipt); err != nil {
+		w.logger.ErrorContext(ctx, "Failed to mark event as published", "error", err)
 		w.metrics.RecordEventFailed("update_error", event.EventType)
 		return fmt.Errorf("failed to mark as published: %w", err)
 	}
@@ -167,35 +464,140 @@ func (w *OutboxWorker) processEvent(ctx context.Context, event *models.OutboxEve
 	w.metrics.RecordEventProcessed("published", event.EventType)
 	w.metrics.RecordEventPublished(w.config.Kafka.TopicEvents, event.EventType)
 	w.metrics.RecordEventPublishingDuration(w.config.Kafka.TopicEvents, event.EventType, publishDuration)
+	w.metrics.RecordEventDeliveryLatency(w.config.Kafka.TopicEvents, event.EventType, time.Since(lockedEvent.CreatedAt))
+
+	w.logger.InfoContext(ctx, "Event published", "topic", receipt.Topic, "partition", receipt.Partition, "offset", receipt.Offset)
+	return nil
+}
+
+// publish publishes the event inside a producer span linked to the trace of the request
+// that created it
+func (w *OutboxWorker) publish(ctx context.Context, event *models.OutboxEvent) (kafka.DeliveryReceipt, error) {
+	ctx, span := tracing.StartPublishSpan(ctx, event, w.config.Kafka.TopicEvents)
+	defer span.End()
+
+	var receipt kafka.DeliveryReceipt
+	if err := w.producer.PublishEventWithOptions(ctx, event, kafka.PublishOptions{Receipt: &receipt}); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return receipt, err
+	}
+
+	if receipt.Topic != "" {
+		span.SetAttributes(
+			semconv.MessagingDestinationPartitionID(strconv.Itoa(int(receipt.Partition))),
+			semconv.MessagingKafkaMessageOffset(int(receipt.Offset)),
+		)
+	}
+	return receipt, nil
+}
+
+// markAsPublished marks the event as published, recording its delivery receipt when the
+// producer returned one. The update is detached from the cancellation of processCtx: once
+// Kafka acknowledged the event, a shutdown deadline must not leave it pending to be sent again.
+func (w *OutboxWorker) markAsPublished(ctx context.Context, event *models.OutboxEvent, receipt kafka.DeliveryReceipt) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), markTimeout)
+	defer cancel()
+
+	if receipt.Topic == "" {
+		return w.outboxRepo.MarkAsPublished(ctx, event.ID.String())
+	}
+	return w.outboxRepo.MarkAsDelivered(ctx, event.ID.String(), receipt.Topic, receipt.Partition, receipt.Offset)
+}
+
+// checkExpiry resolves the event expiry, using the per event type TTL when the event has none
+func (w *OutboxWorker) checkExpiry(event *models.OutboxEvent, now time.Time) (time.Time, bool) {
+	if event.ExpiresAt != nil {
+		return *event.ExpiresAt, event.IsExpired(now)
+	}
 
-	log.Printf("Successfully published event: %s", event.ID)
+	ttl, ok := w.eventTTLs[event.EventType]
+	if !ok {
+		ttl = w.config.Worker.DefaultEventTTL
+	}
+	if ttl <= 0 {
+		return time.Time{}, false
+	}
+
+	expiresAt := event.CreatedAt.Add(ttl)
+	return expiresAt, !now.Before(expiresAt)
+}
+
+// handleExpiredEvent discards an event whose TTL expired before publication
+func (w *OutboxWorker) handleExpiredEvent(ctx context.Context, event *models.OutboxEvent, expiresAt time.Time) error {
+	if err := w.outboxRepo.MarkAsExpired(ctx, event.ID.String(), expiresAt); err != nil {
+		w.logger.ErrorContext(ctx, "Failed to mark event as expired", "error", err)
+		w.metrics.RecordEventFailed("update_error", event.EventType)
+		return fmt.Errorf("failed to mark as expired: %w", err)
+	}
+
+	w.metrics.RecordEventExpired(event.EventType)
+	w.metrics.RecordEventProcessed("expired", event.EventType)
+
+	w.logger.WarnContext(ctx, "Event expired, discarding instead of publishing", "expires_at", expiresAt)
 	return nil
 }
 
+// waitForRateLimit waits for the publish rate limits of the topic and event type.
+// Events that cannot be published within the max wait stay pending without counting as
+// failures, and errEventThrottled is returned.
+func (w *OutboxWorker) waitForRateLimit(ctx context.Context, topic string, event *models.OutboxEvent) error {
+	if !w.limiter.Enabled() {
+		return nil
+	}
+
+	wait, allowed := w.limiter.Reserve(topic, event.EventType, w.config.Worker.RateLimitMaxWait)
+	if !allowed {
+		w.logger.DebugContext(ctx, "Event throttled by rate limit, leaving it pending", "wait", wait)
+		w.metrics.RecordEventThrottled(topic, event.EventType)
+		w.metrics.RecordEventProcessed("throttled", event.EventType)
+		return errEventThrottled
+	}
+
+	w.metrics.RecordThrottleWaitDuration(topic, event.EventType, wait)
+	if wait <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// releaseClaim releases the claim on an event that is left pending
+func (w *OutboxWorker) releaseClaim(ctx context.Context, event *models.OutboxEvent) {
+	if err := w.outboxRepo.ReleaseClaim(ctx, event.ID.String(), w.workerID); err != nil {
+		w.logger.ErrorContext(ctx, "Failed to release claim on event", "error", err)
+	}
+}
+
 // handlePublishError handles publish failures with retry logic
-func (w *OutboxWorker) handlePublishError(event *models.OutboxEvent, err error) error {
+func (w *OutboxWorker) handlePublishError(ctx context.Context, event *models.OutboxEvent, err error) error {
 	event.RetryCount++
 
 	w.metrics.RecordEventRetried(fmt.Sprintf("%d", event.RetryCount), event.EventType)
 
 	if event.RetryCount < w.config.Worker.MaxRetries {
 		errorMsg := fmt.Sprintf("Failed to publish (attempt %d/%d): %v", event.RetryCount, w.config.Worker.MaxRetries+1, err)
-		if updateErr := w.outboxRepo.MarkAsFailed(context.Background(), event.ID.String(), errorMsg); updateErr != nil {
-			log.Printf("Failed to mark event %s as failed: %v", event.ID, updateErr)
+		if updateErr := w.outboxRepo.MarkAsFailed(ctx, event.ID.String(), errorMsg); updateErr != nil {
+			w.logger.ErrorContext(ctx, "Failed to mark event as failed", "error", updateErr)
 			return fmt.Errorf("failed to update retry count: %w", updateErr)
 		}
 
-		log.Printf("Event %s failed, will retry (attempt %d/%d)", event.ID, event.RetryCount, w.config.Worker.MaxRetries+1)
+		w.logger.WarnContext(ctx, "Event failed, will retry", "attempt", event.RetryCount, "max_attempts", w.config.Worker.MaxRetries+1)
 		return fmt.Errorf("publish failed, will retry: %w", err)
 	}
 
 	errorMsg := fmt.Sprintf("Failed to publish after %d attempts: %v", w.config.Worker.MaxRetries+1, err)
-	if updateErr := w.outboxRepo.MarkAsFailed(context.Background(), event.ID.String(), errorMsg); updateErr != nil {
-		log.Printf("Failed to mark event %s as permanently failed: %v", event.ID, updateErr)
+	if updateErr := w.outboxRepo.MarkAsFailed(ctx, event.ID.String(), errorMsg); updateErr != nil {
+		w.logger.ErrorContext(ctx, "Failed to mark event as permanently failed", "error", updateErr)
 		return fmt.Errorf("failed to mark as permanently failed: %w", updateErr)
 	}
 
-	log.Printf("Event %s permanently failed after %d attempts", event.ID, event.RetryCount)
+	w.logger.ErrorContext(ctx, "Event permanently failed", "attempts", event.RetryCount)
 	return fmt.Errorf("publish failed permanently after %d attempts: %w", w.config.Worker.MaxRetries+1, err)
 }
 
@@ -203,3 +605,21 @@ func (w *OutboxWorker) handlePublishError(event *models.OutboxEvent, err error)
 func (w *OutboxWorker) GetMetrics() *metrics.Metrics {
 	return w.metrics
 }
+
+// eventContext adds the identifiers of the event to the log fields of ctx
+func eventContext(ctx context.Context, event *models.OutboxEvent) context.Context {
+	return logging.WithAttrs(ctx,
+		slog.String(logging.EventIDKey, event.ID.String()),
+		slog.String(logging.EventTypeKey, event.EventType),
+		slog.String(logging.AggregateIDKey, event.AggregateID),
+	)
+}
+
+// newWorkerID creates an identifier for the claims of this worker instance
+func newWorkerID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "outbox-worker"
+	}
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.New().String()[:8])
+}
@@ -0,0 +1,111 @@
+package worker
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+)
+
+// EventFunc processes a single outbox event
+type EventFunc func(ctx context.Context, event *models.OutboxEvent) error
+
+// ChainPool processes the per aggregate chains of a batch with a fixed number of goroutines.
+// The events of a chain are processed in order by a single goroutine and a chain stops at its
+// first error, so the rest of the aggregate is left pending for the next batch.
+type ChainPool struct {
+	size    int
+	metrics *metrics.Metrics
+}
+
+// NewChainPool creates a new ChainPool of the given size. A nil metrics records on a private registry.
+func NewChainPool(size int, poolMetrics *metrics.Metrics) *ChainPool {
+	if size < 1 {
+		size = 1
+	}
+	if poolMetrics == nil {
+		poolMetrics = metrics.NewMetrics(nil)
+	}
+
+	return &ChainPool{
+		size:    size,
+		metrics: poolMetrics,
+	}
+}
+
+// Run processes the chains and returns once all of them completed or stopped
+func (p *ChainPool) Run(ctx context.Context, chains [][]models.OutboxEvent, process EventFunc) {
+	size := p.size
+	if size > len(chains) {
+		size = len(chains)
+	}
+
+	chainChan := make(chan []models.OutboxEvent, len(chains))
+	for _, chain := range chains {
+		chainChan <- chain
+	}
+	close(chainChan)
+
+	var active int64
+	var wg sync.WaitGroup
+
+	for i := 0; i < size; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			p.metrics.SetActiveWorkers(int(atomic.AddInt64(&active, 1)))
+			defer func() {
+				p.metrics.SetActiveWorkers(int(atomic.AddInt64(&active, -1)))
+			}()
+
+			for chain := range chainChan {
+				for j := range chain {
+					if err := process(ctx, &chain[j]); err != nil {
+						break
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// GroupByAggregate splits the events into per aggregate chains, keeping the batch order of the
+// aggregates. Each chain is ordered by aggregate version, or by creation date for legacy events
+// written before the versions, whose version is 0.
+func GroupByAggregate(events []models.OutboxEvent) [][]models.OutboxEvent {
+	index := make(map[string]int)
+	var chains [][]models.OutboxEvent
+
+	for _, event := range events {
+		key := event.AggregateType + ":" + event.AggregateID
+		position, ok := index[key]
+		if !ok {
+			position = len(chains)
+			index[key] = position
+			chains = append(chains, nil)
+		}
+		chains[position] = append(chains[position], event)
+	}
+
+	for _, chain := range chains {
+		sort.SliceStable(chain, func(i, j int) bool {
+			return precedes(&chain[i], &chain[j])
+		})
+	}
+
+	return chains
+}
+
+// precedes reports whether event comes before other in the sequence of their aggregate
+func precedes(event, other *models.OutboxEvent) bool {
+	if event.AggregateVersion > 0 && other.AggregateVersion > 0 {
+		return event.AggregateVersion < other.AggregateVersion
+	}
+	return event.CreatedAt.Before(other.CreatedAt)
+}
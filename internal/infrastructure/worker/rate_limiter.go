@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter enforces token bucket publish rate limits per topic and per event type
+type RateLimiter struct {
+	mu sync.Mutex
+
+	topicBuckets     map[string]*tokenBucket
+	eventTypeBuckets map[string]*tokenBucket
+}
+
+// tokenBucket refills at rate tokens per second up to burst tokens.
+// Reservations may drive the tokens negative, which delays the next reservations.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a new RateLimiter from the limits in events per second
+func NewRateLimiter(topicLimits, eventTypeLimits map[string]float64) *RateLimiter {
+	limiter := &RateLimiter{
+		topicBuckets:     make(map[string]*tokenBucket, len(topicLimits)),
+		eventTypeBuckets: make(map[string]*tokenBucket, len(eventTypeLimits)),
+	}
+
+	now := time.Now()
+	for topic, rate := range topicLimits {
+		limiter.topicBuckets[topic] = newTokenBucket(rate, now)
+	}
+	for eventType, rate := range eventTypeLimits {
+		limiter.eventTypeBuckets[eventType] = newTokenBucket(rate, now)
+	}
+
+	return limiter
+}
+
+func newTokenBucket(rate float64, now time.Time) *tokenBucket {
+	burst := math.Max(1, math.Ceil(rate))
+	return &tokenBucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// Enabled returns true if at least one rate limit is configured
+func (l *RateLimiter) Enabled() bool {
+	return len(l.topicBuckets) > 0 || len(l.eventTypeBuckets) > 0
+}
+
+// Reserve reserves a publish slot for an event and returns how long the caller must wait
+// before publishing. When the wait would exceed maxWait nothing is reserved and false is returned.
+func (l *RateLimiter) Reserve(topic, eventType string, maxWait time.Duration) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	buckets := make([]*tokenBucket, 0, 2)
+	if bucket, ok := l.topicBuckets[topic]; ok {
+		buckets = append(buckets, bucket)
+	}
+	if bucket, ok := l.eventTypeBuckets[eventType]; ok {
+		buckets = append(buckets, bucket)
+	}
+
+	var wait time.Duration
+	for _, bucket := range buckets {
+		bucket.refill(now)
+		if delay := bucket.delay(); delay > wait {
+			wait = delay
+		}
+	}
+
+	if wait > maxWait {
+		return wait, false
+	}
+
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+
+	return wait, true
+}
+
+// refill adds the tokens accumulated since the last refill
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// delay returns how long until a token is available
+func (b *tokenBucket) delay() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
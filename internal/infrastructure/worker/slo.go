@@ -0,0 +1,159 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+// SLOWindow is the delivery latency measured over a window
+type SLOWindow struct {
+	Window     time.Duration
+	Deliveries int64
+	Late       int64
+	// BurnRate is the late ratio divided by the error budget ratio: at 1 the budget is
+	// spent exactly over the SLO window, above 1 it runs out before the window ends
+	BurnRate float64
+}
+
+// SLOReport summarizes an evaluation of the delivery latency SLO
+type SLOReport struct {
+	Objective            float64
+	Threshold            time.Duration
+	Window               SLOWindow
+	BurnRates            []SLOWindow
+	ErrorBudgetRemaining float64
+}
+
+// SLOEvaluator evaluates the delivery latency SLO from the outbox: the ratio of events
+// published within the threshold of their creation over a rolling window. Events still
+// pending past the threshold count as late, so a stalled worker burns the budget before
+// the events are published. The outbox is the source of truth, so the evaluation covers
+// every worker and survives restarts, but events removed by the retention are not counted.
+type SLOEvaluator struct {
+	config     config.SLOConfig
+	outboxRepo repositories.OutboxRepository
+	metrics    *metrics.Metrics
+	logger     *slog.Logger
+	stopChan   chan struct{}
+	stopOnce   sync.Once
+	wg         sync.WaitGroup
+}
+
+// NewSLOEvaluator creates a new SLOEvaluator
+func NewSLOEvaluator(cfg config.SLOConfig, outboxRepo repositories.OutboxRepository, metrics *metrics.Metrics, logger *slog.Logger) *SLOEvaluator {
+	return &SLOEvaluator{
+		config:     cfg,
+		outboxRepo: outboxRepo,
+		metrics:    metrics,
+		logger:     logging.OrDefault(logger),
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// Start evaluates the SLO immediately and then periodically until the context is cancelled or Stop is called
+func (e *SLOEvaluator) Start(ctx context.Context) {
+	e.logger.InfoContext(ctx, "Starting delivery SLO evaluator",
+		"objective", e.config.Objective,
+		"threshold", e.config.Threshold,
+		"window", e.config.Window,
+		"burn_rate_windows", e.config.BurnRateWindows)
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(e.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := e.Evaluate(ctx); err != nil {
+				e.logger.ErrorContext(ctx, "SLO evaluation failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-e.stopChan:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic evaluation
+func (e *SLOEvaluator) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.stopChan)
+	})
+	e.wg.Wait()
+}
+
+// Evaluate runs a single SLO evaluation and exports its gauges
+func (e *SLOEvaluator) Evaluate(ctx context.Context) (*SLOReport, error) {
+	now := time.Now()
+	report := &SLOReport{
+		Objective: e.config.Objective,
+		Threshold: e.config.Threshold,
+	}
+
+	window, err := e.evaluateWindow(ctx, now, e.config.Window)
+	if err != nil {
+		return nil, err
+	}
+	report.Window = window
+	report.ErrorBudgetRemaining = 1 - window.BurnRate
+
+	for _, burnRateWindow := range e.config.GetBurnRateWindows() {
+		window, err := e.evaluateWindow(ctx, now, burnRateWindow)
+		if err != nil {
+			return nil, err
+		}
+		report.BurnRates = append(report.BurnRates, window)
+	}
+
+	if e.metrics != nil {
+		e.metrics.SetSLOObjective(report.Objective)
+		e.metrics.SetSLOErrorBudgetRemaining(report.ErrorBudgetRemaining)
+		e.metrics.SetSLOBurnRate(WindowLabel(report.Window.Window), report.Window.BurnRate)
+		for _, window := range report.BurnRates {
+			e.metrics.SetSLOBurnRate(WindowLabel(window.Window), window.BurnRate)
+		}
+	}
+
+	return report, nil
+}
+
+func (e *SLOEvaluator) evaluateWindow(ctx context.Context, now time.Time, window time.Duration) (SLOWindow, error) {
+	deliveries, late, err := e.outboxRepo.CountDeliveries(ctx, now.Add(-window), e.config.Threshold)
+	if err != nil {
+		return SLOWindow{}, fmt.Errorf("failed to count deliveries over %v: %w", window, err)
+	}
+
+	result := SLOWindow{Window: window, Deliveries: deliveries, Late: late}
+	if deliveries > 0 {
+		result.BurnRate = float64(late) / float64(deliveries) / (1 - e.config.Objective)
+	}
+	return result, nil
+}
+
+// WindowLabel formats a window without its zero minutes and seconds, such as "6h" or "720h"
+func WindowLabel(window time.Duration) string {
+	label := window.String()
+	if strings.HasSuffix(label, "m0s") {
+		label = strings.TrimSuffix(label, "0s")
+	}
+	if strings.HasSuffix(label, "h0m") {
+		label = strings.TrimSuffix(label, "0m")
+	}
+	return label
+}
@@ -0,0 +1,179 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+// WatchdogReport summarizes a watchdog check
+type WatchdogReport struct {
+	StatusCounts     map[models.OutboxStatus]int64
+	OldestPendingAge time.Duration
+	ReleasedClaims   int64
+	StuckEvents      int64
+	EscalatedEvents  int64
+}
+
+// Watchdog detects events that never leave pending. Claims whose lease expired, left behind
+// by a crashed or stalled worker, are released so the events are re-queued; pending events
+// older than the stuck threshold are reported, and escalated past EscalateStuckAfter. Stuck
+// events are never moved out of pending, the worker would not pick them up again.
+// It also reports the per status counts and the age of the oldest pending event from SQL.
+type Watchdog struct {
+	config     config.WatchdogConfig
+	outboxRepo repositories.OutboxRepository
+	metrics    *metrics.Metrics
+	logger     *slog.Logger
+	stopChan   chan struct{}
+	stopOnce   sync.Once
+	wg         sync.WaitGroup
+}
+
+// NewWatchdog creates a new Watchdog
+func NewWatchdog(cfg config.WatchdogConfig, outboxRepo repositories.OutboxRepository, metrics *metrics.Metrics, logger *slog.Logger) *Watchdog {
+	return &Watchdog{
+		config:     cfg,
+		outboxRepo: outboxRepo,
+		metrics:    metrics,
+		logger:     logging.OrDefault(logger),
+		stopChan:   make(chan struct{}),
+	}
+}
+
+// Start runs the check immediately and then periodically until the context is cancelled or Stop is called
+func (d *Watchdog) Start(ctx context.Context) {
+	d.logger.InfoContext(ctx, "Starting outbox watchdog",
+		"interval", d.config.Interval,
+		"stuck_threshold", d.config.StuckThreshold,
+		"escalate_stuck_after", d.config.EscalateStuckAfter)
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ticker := time.NewTicker(d.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := d.Check(ctx); err != nil {
+				d.logger.ErrorContext(ctx, "Watchdog check failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-d.stopChan:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic checks
+func (d *Watchdog) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stopChan)
+	})
+	d.wg.Wait()
+}
+
+// Check runs a single watchdog check
+func (d *Watchdog) Check(ctx context.Context) (*WatchdogReport, error) {
+	report := &WatchdogReport{}
+	now := time.Now()
+
+	released, err := d.outboxRepo.ReleaseExpiredClaims(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to release expired claims: %w", err)
+	}
+	report.ReleasedClaims = released
+	if released > 0 {
+		d.logger.WarnContext(ctx, "Watchdog re-queued events with expired claims", "released", released)
+		d.recordRecovered("expired_claim", released)
+	}
+
+	stuckBefore := now.Add(-d.config.StuckThreshold)
+	stuck, err := d.outboxRepo.CountStuckEvents(ctx, stuckBefore)
+	if err != nil {
+		return report, fmt.Errorf("failed to count stuck events: %w", err)
+	}
+	report.StuckEvents = stuck
+	if stuck > 0 {
+		d.alertStuckEvents(ctx, stuck, stuckBefore, now)
+	}
+
+	if d.config.EscalateStuckAfter > 0 && stuck > 0 {
+		escalated, err := d.outboxRepo.CountStuckEvents(ctx, now.Add(-d.config.EscalateStuckAfter))
+		if err != nil {
+			return report, fmt.Errorf("failed to count escalated stuck events: %w", err)
+		}
+		report.EscalatedEvents = escalated
+		if escalated > 0 {
+			d.logger.ErrorContext(ctx, "Events stuck in pending beyond the escalation threshold",
+				"escalated", escalated, "escalate_stuck_after", d.config.EscalateStuckAfter)
+		}
+	}
+
+	oldest, err := d.outboxRepo.GetOldestPendingCreatedAt(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to get oldest pending event: %w", err)
+	}
+	if oldest != nil && now.After(*oldest) {
+		report.OldestPendingAge = now.Sub(*oldest)
+	}
+
+	counts, err := d.outboxRepo.CountEventsByStatus(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to count events by status: %w", err)
+	}
+	report.StatusCounts = counts
+
+	if d.metrics != nil {
+		d.metrics.SetStuckEvents(int(stuck))
+		d.metrics.SetEscalatedStuckEvents(int(report.EscalatedEvents))
+		d.metrics.SetOldestPendingAge(report.OldestPendingAge)
+		for _, status := range models.OutboxStatuses {
+			d.metrics.SetEventsInQueue(string(status), int(counts[status]))
+		}
+	}
+
+	return report, nil
+}
+
+// alertStuckEvents logs the oldest stuck events up to the alert limit
+func (d *Watchdog) alertStuckEvents(ctx context.Context, stuck int64, stuckBefore, now time.Time) {
+	d.logger.WarnContext(ctx, "Events stuck in pending", "stuck", stuck, "stuck_threshold", d.config.StuckThreshold)
+	if d.config.AlertLimit == 0 {
+		return
+	}
+
+	events, err := d.outboxRepo.GetStuckEvents(ctx, stuckBefore, d.config.AlertLimit)
+	if err != nil {
+		d.logger.ErrorContext(ctx, "Failed to get stuck events", "error", err)
+		return
+	}
+
+	for _, event := range events {
+		d.logger.WarnContext(eventContext(ctx, &event), "Event stuck in pending",
+			"pending_for", now.Sub(event.CreatedAt).Truncate(time.Second),
+			"retry_count", event.RetryCount,
+			"claimed_by", event.ClaimedBy,
+			"last_error", event.ErrorMessage)
+	}
+}
+
+func (d *Watchdog) recordRecovered(reason string, count int64) {
+	if d.metrics != nil {
+		d.metrics.RecordEventsRecovered(reason, int(count))
+	}
+}
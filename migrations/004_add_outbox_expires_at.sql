@@ -0,0 +1,10 @@
+-- Migration 004: Add event expiry to the outbox table
+-- Events past their expiry are moved to the expired status instead of being published late
+
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_expires_at ON outbox (expires_at);
+
+-- Comments for documentation
+COMMENT ON COLUMN outbox.expires_at IS 'Date after which the event is discarded instead of published';
+COMMENT ON COLUMN outbox.status IS 'Event status: pending, published, failed, expired';
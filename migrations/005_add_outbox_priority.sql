@@ -0,0 +1,9 @@
+-- Migration 005: Add priority lanes to the outbox table
+-- Allows latency-critical events to be scheduled ahead of bulk events
+
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_status_priority ON outbox (status, priority, created_at);
+
+-- Comments for documentation
+COMMENT ON COLUMN outbox.priority IS 'Priority lane of the event: 1 (high), 0 (normal), -1 (low)';
@@ -0,0 +1,12 @@
+-- Migration 006: Add worker claims to the outbox table
+-- A worker claims an event for a lease while publishing it, so claims left behind by a
+-- worker that stopped or crashed can be detected and taken over
+
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS claimed_by VARCHAR(255);
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_claimed_until ON outbox (claimed_until);
+
+-- Comments for documentation
+COMMENT ON COLUMN outbox.claimed_by IS 'ID of the worker currently publishing the event';
+COMMENT ON COLUMN outbox.claimed_until IS 'Date until which the worker claim is valid';
@@ -0,0 +1,40 @@
+-- Migration 007: Create replay jobs table
+-- A replay job republishes historical events and checkpoints its progress, so a large
+-- replay interrupted by a crash resumes after the last checkpoint
+
+CREATE TABLE IF NOT EXISTS replay_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    status VARCHAR(20) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    aggregate_id VARCHAR(255),
+    aggregate_type VARCHAR(100),
+    event_type VARCHAR(100),
+    created_after TIMESTAMP WITH TIME ZONE,
+    created_before TIMESTAMP WITH TIME ZONE,
+    topic VARCHAR(255),
+    rate_limit DOUBLE PRECISION NOT NULL DEFAULT 0,
+    checkpoint_source VARCHAR(20),
+    checkpoint_segment VARCHAR(255),
+    checkpoint_offset INTEGER NOT NULL DEFAULT 0,
+    checkpoint_created_at TIMESTAMP WITH TIME ZONE,
+    checkpoint_event_id UUID,
+    replayed BIGINT NOT NULL DEFAULT 0,
+    error_message TEXT,
+    heartbeat_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_replay_jobs_status ON replay_jobs (status);
+
+-- Comments for documentation
+COMMENT ON TABLE replay_jobs IS 'Replays of historical events with their progress checkpoint';
+COMMENT ON COLUMN replay_jobs.source IS 'Where events are read from: outbox, archive or all';
+COMMENT ON COLUMN replay_jobs.topic IS 'Topic override, the configured events topic when empty';
+COMMENT ON COLUMN replay_jobs.checkpoint_source IS 'Source of the last replayed event';
+COMMENT ON COLUMN replay_jobs.checkpoint_segment IS 'Archive segment of the last replayed event';
+COMMENT ON COLUMN replay_jobs.checkpoint_offset IS 'Number of events of the checkpoint segment already replayed';
+COMMENT ON COLUMN replay_jobs.checkpoint_created_at IS 'Creation date of the last replayed outbox event';
+COMMENT ON COLUMN replay_jobs.checkpoint_event_id IS 'ID of the last replayed outbox event';
+COMMENT ON COLUMN replay_jobs.heartbeat_at IS 'Last time the running job saved its progress';
@@ -0,0 +1,21 @@
+-- Migration 008: Create backfill checkpoints table
+-- A backfill job saves its progress after each page, so an interrupted run resumes
+-- from the last processed page instead of starting over
+
+CREATE TABLE IF NOT EXISTS backfill_checkpoints (
+    name VARCHAR(100) PRIMARY KEY,
+    "offset" INTEGER NOT NULL DEFAULT 0,
+    processed BIGINT NOT NULL DEFAULT 0,
+    created BIGINT NOT NULL DEFAULT 0,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Comments for documentation
+COMMENT ON TABLE backfill_checkpoints IS 'Progress of the backfill jobs';
+COMMENT ON COLUMN backfill_checkpoints.name IS 'Name of the backfill job';
+COMMENT ON COLUMN backfill_checkpoints."offset" IS 'Rows already processed in the current pass';
+COMMENT ON COLUMN backfill_checkpoints.processed IS 'Rows processed in the current pass';
+COMMENT ON COLUMN backfill_checkpoints.created IS 'Events created in the current pass';
+COMMENT ON COLUMN backfill_checkpoints.completed_at IS 'Date the current pass completed';
@@ -0,0 +1,12 @@
+-- Migration 009: Add delivery receipts to the outbox table
+-- The worker records where each event was written in Kafka, so the reconciler can check
+-- that published events actually exist in their partition log
+
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS kafka_topic VARCHAR(255);
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS kafka_partition INTEGER;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS kafka_offset BIGINT;
+
+-- Comments for documentation
+COMMENT ON COLUMN outbox.kafka_topic IS 'Topic the event was published to';
+COMMENT ON COLUMN outbox.kafka_partition IS 'Partition the event was published to';
+COMMENT ON COLUMN outbox.kafka_offset IS 'Offset of the published message, NULL when no receipt was recorded';
@@ -0,0 +1,26 @@
+-- Migration 010: Create inbox table
+-- The relay delivers events at least once, so a consumer records the ID of each processed
+-- event in the same transaction as its own writes and skips the events already recorded
+
+CREATE TABLE IF NOT EXISTS inbox (
+    consumer_group VARCHAR(255) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    aggregate_id VARCHAR(255),
+    topic VARCHAR(255),
+    "partition" INTEGER,
+    "offset" BIGINT,
+    processed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (consumer_group, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_inbox_processed_at ON inbox (processed_at);
+
+-- Comments for documentation
+COMMENT ON TABLE inbox IS 'Events already processed by each consumer group, to skip redeliveries';
+COMMENT ON COLUMN inbox.consumer_group IS 'Consumer group that processed the event';
+COMMENT ON COLUMN inbox.event_id IS 'Value of the event_id header of the processed message';
+COMMENT ON COLUMN inbox.topic IS 'Topic of the first delivery of the event';
+COMMENT ON COLUMN inbox."partition" IS 'Partition of the first delivery of the event';
+COMMENT ON COLUMN inbox."offset" IS 'Offset of the first delivery of the event';
+COMMENT ON COLUMN inbox.processed_at IS 'Date the event was processed, entries are deleted after the inbox retention';
@@ -0,0 +1,18 @@
+-- Migration 011: Add per-aggregate versions to the outbox table
+-- Each event gets the next version of its aggregate in the transaction that writes it,
+-- so consumers can detect missed, duplicated and reordered events
+
+CREATE TABLE IF NOT EXISTS aggregate_versions (
+    aggregate_id VARCHAR(255) PRIMARY KEY,
+    version BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS aggregate_version BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_aggregate_version ON outbox (aggregate_id, aggregate_version);
+
+-- Comments for documentation
+COMMENT ON TABLE aggregate_versions IS 'Last version assigned to the events of each aggregate';
+COMMENT ON COLUMN aggregate_versions.version IS 'Version of the last event written for the aggregate';
+COMMENT ON COLUMN outbox.aggregate_version IS 'Position of the event in the sequence of its aggregate, starting at 1; 0 for events written before versioning';
@@ -0,0 +1,13 @@
+-- Migration 012: Add correlation and causation IDs to the outbox table
+-- The events descending from the same original request share a correlation ID, and each
+-- event records the request or event that caused it
+
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS correlation_id VARCHAR(255);
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS causation_id VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_correlation_id ON outbox (correlation_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_causation_id ON outbox (causation_id);
+
+-- Comments for documentation
+COMMENT ON COLUMN outbox.correlation_id IS 'ID shared by the events descending from the same original request; the event ID when it started its own flow';
+COMMENT ON COLUMN outbox.causation_id IS 'ID of the request or event that caused the event, empty when unknown';
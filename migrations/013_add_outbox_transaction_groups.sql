@@ -0,0 +1,29 @@
+-- Migration 013: Group the outbox events written by the same database transaction
+-- Each event records the transaction that wrote it and how many events that transaction
+-- wrote, so consumers can apply the events of a transaction together
+
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS transaction_id UUID;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS transaction_event_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_transaction_id ON outbox (transaction_id);
+
+-- Events received by a consumer group for a transaction whose other events have not all
+-- arrived yet; the rows of a transaction are deleted once its events are applied
+CREATE TABLE IF NOT EXISTS inbox_group_events (
+    consumer_group VARCHAR(255) NOT NULL,
+    transaction_id VARCHAR(255) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    event_count INTEGER NOT NULL,
+    event JSONB NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (consumer_group, transaction_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_inbox_group_events_received_at ON inbox_group_events (received_at);
+
+-- Comments for documentation
+COMMENT ON COLUMN outbox.transaction_id IS 'ID of the database transaction that wrote the event';
+COMMENT ON COLUMN outbox.transaction_event_count IS 'Number of events written by the transaction; 0 for events written before grouping';
+COMMENT ON TABLE inbox_group_events IS 'Events waiting for the other events of their transaction, per consumer group';
+COMMENT ON COLUMN inbox_group_events.event_count IS 'Number of events written by the transaction';
+COMMENT ON COLUMN inbox_group_events.event IS 'Decoded event, handed to the group handler once the transaction is complete';
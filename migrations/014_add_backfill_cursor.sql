@@ -0,0 +1,13 @@
+-- Migration 014: Checkpoint the backfills with a keyset cursor
+-- The backfill walks the orders by creation date and ID and saves the last order processed,
+-- so orders created or deleted during a run no longer shift the pages like an offset did
+
+ALTER TABLE backfill_checkpoints ADD COLUMN IF NOT EXISTS cursor_created_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE backfill_checkpoints ADD COLUMN IF NOT EXISTS cursor_id UUID;
+ALTER TABLE backfill_checkpoints DROP COLUMN IF EXISTS "offset";
+
+CREATE INDEX IF NOT EXISTS idx_orders_created_at_id ON orders (created_at, id);
+
+-- Comments for documentation
+COMMENT ON COLUMN backfill_checkpoints.cursor_created_at IS 'Creation date of the last row processed in the current pass, NULL before the first page';
+COMMENT ON COLUMN backfill_checkpoints.cursor_id IS 'ID of the last row processed in the current pass, NULL before the first page';
@@ -0,0 +1,16 @@
+-- Migration 015: Count the outbox events of a transaction on a single row
+-- Writing an event increments the count of its transaction instead of rewriting the count on
+-- every event of the transaction; the final count is copied onto an event when it is claimed
+
+CREATE TABLE IF NOT EXISTS outbox_transactions (
+    transaction_id UUID PRIMARY KEY,
+    event_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_transactions_created_at ON outbox_transactions (created_at);
+
+-- Comments for documentation
+COMMENT ON TABLE outbox_transactions IS 'Number of outbox events written by each database transaction, deleted by the outbox retention once its events are claimed';
+COMMENT ON COLUMN outbox_transactions.event_count IS 'Number of events written by the transaction, final once it commits';
+COMMENT ON COLUMN outbox.transaction_event_count IS 'Number of events written by the transaction, copied when the event is claimed for publication; 0 before, and for events written before grouping';
@@ -0,0 +1,16 @@
+-- Migration 016: Record the order versions snapshotted by the backfill
+-- The backfill writes a snapshot only when it inserts the version here, in the same transaction
+-- as the outbox event, so concurrent runs and runs after the outbox retention never write twice
+
+CREATE TABLE IF NOT EXISTS backfill_order_snapshots (
+    order_id UUID NOT NULL,
+    order_updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    event_id UUID NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (order_id, order_updated_at)
+);
+
+-- Comments for documentation
+COMMENT ON TABLE backfill_order_snapshots IS 'Order versions the snapshot backfill wrote an OrderSnapshot event for';
+COMMENT ON COLUMN backfill_order_snapshots.order_updated_at IS 'updated_at of the order when it was snapshotted';
+COMMENT ON COLUMN backfill_order_snapshots.event_id IS 'ID of the OrderSnapshot outbox event';
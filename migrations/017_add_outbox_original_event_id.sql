@@ -0,0 +1,8 @@
+-- Migration 017: Publish the redeliveries with the ID of the original event
+-- A copy of an event missing from Kafka keeps its own ID as the outbox key and carries the ID
+-- of the original in the event_id of the message, so inbox consumers deduplicate it
+
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS original_event_id UUID;
+
+-- Comments for documentation
+COMMENT ON COLUMN outbox.original_event_id IS 'ID of the event a redelivery copies, published as the event_id of the message; NULL for other events';
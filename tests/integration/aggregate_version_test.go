@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/tests"
+)
+
+func TestAggregateVersion(t *testing.T) {
+	db := tests.SetupTestDatabase(t)
+	defer tests.TeardownTestDatabase(t)
+
+	ctx := context.Background()
+	outboxRepo := repositories.NewOutboxRepository(db, nil)
+
+	newEvent := func(aggregateID string) *models.OutboxEvent {
+		return &models.OutboxEvent{
+			AggregateID:   aggregateID,
+			AggregateType: "Order",
+			EventType:     "OrderUpdated",
+			EventData:     models.JSON{"order_id": aggregateID},
+			Status:        models.OutboxStatusPending,
+			CreatedAt:     time.Now(),
+		}
+	}
+
+	t.Run("versions_increment_per_aggregate", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		first, second := uuid.New().String(), uuid.New().String()
+
+		var versions []int64
+		for _, aggregateID := range []string{first, first, second, first} {
+			event := newEvent(aggregateID)
+			require.NoError(t, outboxRepo.Create(ctx, event))
+			versions = append(versions, event.AggregateVersion)
+		}
+
+		assert.Equal(t, []int64{1, 2, 1, 3}, versions)
+	})
+
+	t.Run("rolled_back_transaction_leaves_no_gap", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		aggregateID := uuid.New().String()
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			require.NoError(t, tx.Create(newEvent(aggregateID)).Error)
+			return errors.New("business rule violated")
+		})
+		require.Error(t, err)
+
+		event := newEvent(aggregateID)
+		require.NoError(t, outboxRepo.Create(ctx, event))
+		assert.Equal(t, int64(1), event.AggregateVersion)
+	})
+
+	t.Run("concurrent_writers_get_distinct_versions", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		aggregateID := uuid.New().String()
+
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			versions []int64
+		)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				event := newEvent(aggregateID)
+				if assert.NoError(t, outboxRepo.Create(ctx, event)) {
+					mu.Lock()
+					versions = append(versions, event.AggregateVersion)
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+		assert.Equal(t, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, versions)
+	})
+}
@@ -31,8 +31,8 @@ func TestCircuitBreakerIntegration(t *testing.T) {
 			ResetTimeout:          10 * time.Second,
 		}
 
-		metrics := metrics.NewMetrics()
-		producer, err := kafka.NewProducer(kafkaConfig, metrics)
+		metrics := metrics.NewMetrics(nil)
+		producer, err := kafka.NewProducer(kafkaConfig, metrics, nil)
 		require.NoError(t, err)
 		defer producer.Close()
 
@@ -54,8 +54,8 @@ func TestCircuitBreakerIntegration(t *testing.T) {
 			CircuitBreakerEnabled: false,
 		}
 
-		metrics := metrics.NewMetrics()
-		producer, err := kafka.NewProducer(kafkaConfig, metrics)
+		metrics := metrics.NewMetrics(nil)
+		producer, err := kafka.NewProducer(kafkaConfig, metrics, nil)
 		require.NoError(t, err)
 		defer producer.Close()
 
@@ -78,8 +78,8 @@ func TestCircuitBreakerIntegration(t *testing.T) {
 			ResetTimeout:          10 * time.Second,
 		}
 
-		metrics := metrics.NewMetrics()
-		producer, err := kafka.NewProducer(kafkaConfig, metrics)
+		metrics := metrics.NewMetrics(nil)
+		producer, err := kafka.NewProducer(kafkaConfig, metrics, nil)
 		require.NoError(t, err)
 		defer producer.Close()
 
@@ -113,8 +113,8 @@ func TestCircuitBreakerIntegration(t *testing.T) {
 			ResetTimeout:          10 * time.Second,
 		}
 
-		metrics := metrics.NewMetrics()
-		producer, err := kafka.NewProducer(kafkaConfig, metrics)
+		metrics := metrics.NewMetrics(nil)
+		producer, err := kafka.NewProducer(kafkaConfig, metrics, nil)
 		require.NoError(t, err)
 		defer producer.Close()
 
@@ -154,8 +154,8 @@ func TestCircuitBreakerIntegration(t *testing.T) {
 			ResetTimeout:          100 * time.Millisecond, // Short timeout for testing
 		}
 
-		metrics := metrics.NewMetrics()
-		producer, err := kafka.NewProducer(kafkaConfig, metrics)
+		metrics := metrics.NewMetrics(nil)
+		producer, err := kafka.NewProducer(kafkaConfig, metrics, nil)
 		require.NoError(t, err)
 		defer producer.Close()
 
@@ -189,8 +189,8 @@ func TestCircuitBreakerIntegration(t *testing.T) {
 			ResetTimeout:          10 * time.Second,
 		}
 
-		metrics := metrics.NewMetrics()
-		producer, err := kafka.NewProducer(kafkaConfig, metrics)
+		metrics := metrics.NewMetrics(nil)
+		producer, err := kafka.NewProducer(kafkaConfig, metrics, nil)
 		require.NoError(t, err)
 		defer producer.Close()
 
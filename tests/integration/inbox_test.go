@@ -0,0 +1,155 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/consumer"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/inbox"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/tests"
+)
+
+func TestInboxDeduplication(t *testing.T) {
+	db := tests.SetupTestDatabase(t)
+	defer tests.TeardownTestDatabase(t)
+
+	ctx := context.Background()
+	inboxRepo := repositories.NewInboxRepository(db)
+
+	newEvent := func() *consumer.Event {
+		return &consumer.Event{
+			ID:          uuid.New().String(),
+			AggregateID: uuid.New().String(),
+			EventType:   "OrderCreated",
+			Topic:       "txstream.events",
+			Offset:      42,
+		}
+	}
+
+	// createEvent writes an outbox event, standing for the handler's own writes
+	createEvent := func(ctx context.Context, tx *gorm.DB, event *consumer.Event) error {
+		return tx.Create(&models.OutboxEvent{
+			ID:            uuid.New(),
+			AggregateID:   event.AggregateID,
+			AggregateType: "Invoice",
+			EventType:     "InvoiceIssued",
+			EventData:     models.JSON{"order_event_id": event.ID},
+			Status:        models.OutboxStatusPending,
+			CreatedAt:     time.Now(),
+		}).Error
+	}
+
+	countEvents := func(t *testing.T, aggregateID string) int64 {
+		var count int64
+		require.NoError(t, db.Model(&models.OutboxEvent{}).Where("aggregate_id = ?", aggregateID).Count(&count).Error)
+		return count
+	}
+
+	t.Run("redelivered_event_is_skipped", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		eventInbox := inbox.NewInbox(db, "txstream-test", nil, nil)
+		event := newEvent()
+
+		processed, err := eventInbox.Process(ctx, event, createEvent)
+		require.NoError(t, err)
+		assert.True(t, processed)
+
+		processed, err = eventInbox.Process(ctx, event, createEvent)
+		require.NoError(t, err)
+		assert.False(t, processed)
+
+		assert.Equal(t, int64(1), countEvents(t, event.AggregateID))
+		exists, err := inboxRepo.Exists(ctx, "txstream-test", event.ID)
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("consumer_groups_are_deduplicated_separately", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		event := newEvent()
+
+		for _, group := range []string{"billing", "shipping"} {
+			processed, err := inbox.NewInbox(db, group, nil, nil).Process(ctx, event, createEvent)
+			require.NoError(t, err)
+			assert.True(t, processed, group)
+		}
+	})
+
+	t.Run("handler_error_rolls_back_the_entry", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		eventInbox := inbox.NewInbox(db, "txstream-test", nil, nil)
+		event := newEvent()
+
+		_, err := eventInbox.Process(ctx, event, func(ctx context.Context, tx *gorm.DB, event *consumer.Event) error {
+			require.NoError(t, createEvent(ctx, tx, event))
+			return errors.New("downstream unavailable")
+		})
+		require.Error(t, err)
+		assert.Equal(t, int64(0), countEvents(t, event.AggregateID))
+
+		processed, err := eventInbox.Process(ctx, event, createEvent)
+		require.NoError(t, err)
+		assert.True(t, processed, "the retry must handle the event again")
+		assert.Equal(t, int64(1), countEvents(t, event.AggregateID))
+	})
+
+	t.Run("concurrent_deliveries_are_processed_once", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		eventInbox := inbox.NewInbox(db, "txstream-test", nil, nil)
+		event := newEvent()
+
+		var wg sync.WaitGroup
+		results := make(chan bool, 5)
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				processed, err := eventInbox.Process(ctx, event, createEvent)
+				assert.NoError(t, err)
+				results <- processed
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		processedCount := 0
+		for processed := range results {
+			if processed {
+				processedCount++
+			}
+		}
+		assert.Equal(t, 1, processedCount)
+		assert.Equal(t, int64(1), countEvents(t, event.AggregateID))
+	})
+
+	t.Run("retention_deletes_old_entries", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		old := &models.InboxEntry{ConsumerGroup: "txstream-test", EventID: uuid.New().String(), EventType: "OrderCreated", ProcessedAt: time.Now().Add(-48 * time.Hour)}
+		recent := &models.InboxEntry{ConsumerGroup: "txstream-test", EventID: uuid.New().String(), EventType: "OrderCreated"}
+		for _, entry := range []*models.InboxEntry{old, recent} {
+			recorded, err := inboxRepo.Record(ctx, entry)
+			require.NoError(t, err)
+			require.True(t, recorded)
+		}
+
+		job := inbox.NewRetentionJob(config.InboxConfig{Enabled: true, Retention: 24 * time.Hour, Interval: time.Hour, BatchSize: 100}, inboxRepo, nil, nil)
+		purged, err := job.Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		exists, err := inboxRepo.Exists(ctx, "txstream-test", recent.EventID)
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+}
@@ -20,7 +20,7 @@ func TestKafkaProducerIntegration(t *testing.T) {
 	db := tests.SetupTestDatabase(t)
 	defer tests.TeardownTestDatabase(t)
 
-	outboxRepo := repositories.NewOutboxRepository(db)
+	outboxRepo := repositories.NewOutboxRepository(db, nil)
 
 	t.Run("successful_event_publication", func(t *testing.T) {
 		tests.CleanupTestDatabase(t, db)
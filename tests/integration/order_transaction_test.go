@@ -16,6 +16,8 @@ import (
 
 	"github.com/lorenaziviani/txstream/internal/application/dto"
 	"github.com/lorenaziviani/txstream/internal/application/usecases"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/correlation"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/handlers"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
@@ -28,9 +30,9 @@ func TestOrderTransactionWithOutbox(t *testing.T) {
 	defer tests.TeardownTestDatabase(t)
 
 	orderRepo := repositories.NewOrderRepository(db)
-	outboxRepo := repositories.NewOutboxRepository(db)
-	orderUseCase := usecases.NewOrderUseCase(orderRepo, outboxRepo, db)
-	orderHandler := handlers.NewOrderHandler(orderUseCase)
+	outboxRepo := repositories.NewOutboxRepository(db, nil)
+	orderUseCase := usecases.NewOrderUseCase(orderRepo, outboxRepo, db, nil)
+	orderHandler := handlers.NewOrderHandler(orderUseCase, nil, nil)
 
 	router := mux.NewRouter()
 	apiRouter := router.PathPrefix("/api/v1").Subrouter()
@@ -142,6 +144,24 @@ func TestOrderTransactionWithOutbox(t *testing.T) {
 		assert.Equal(t, 1, orderCount, "Should have exactly one order with this number")
 	})
 
+	t.Run("configured_ttl_is_stamped_at_creation", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+
+		workerConfig := &config.WorkerConfig{DefaultEventTTL: time.Hour, EventTTLs: "OrderCreated=10m"}
+		ttlUseCase := usecases.NewOrderUseCase(orderRepo, outboxRepo, db, workerConfig)
+
+		request := createValidOrderRequest()
+		request.OrderNumber = fmt.Sprintf("ORD-TTL-%d", time.Now().UnixNano())
+		response, err := ttlUseCase.CreateOrder(context.Background(), request)
+		require.NoError(t, err)
+
+		events, err := outboxRepo.GetEventsByAggregate(context.Background(), response.ID.String(), "Order")
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.NotNil(t, events[0].ExpiresAt, "the TTL is stored with the event")
+		assert.WithinDuration(t, events[0].CreatedAt.Add(10*time.Minute), *events[0].ExpiresAt, time.Millisecond)
+	})
+
 	t.Run("invalid_request_returns_bad_request", func(t *testing.T) {
 		tests.CleanupTestDatabase(t, db)
 
@@ -176,8 +196,8 @@ func TestOutboxEventConsistency(t *testing.T) {
 	defer tests.TeardownTestDatabase(t)
 
 	orderRepo := repositories.NewOrderRepository(db)
-	outboxRepo := repositories.NewOutboxRepository(db)
-	orderUseCase := usecases.NewOrderUseCase(orderRepo, outboxRepo, db)
+	outboxRepo := repositories.NewOutboxRepository(db, nil)
+	orderUseCase := usecases.NewOrderUseCase(orderRepo, outboxRepo, db, nil)
 
 	t.Run("outbox_event_contains_correct_data", func(t *testing.T) {
 		tests.CleanupTestDatabase(t, db)
@@ -226,10 +246,27 @@ func TestOutboxEventConsistency(t *testing.T) {
 		assert.Equal(t, "txstream-api", eventMetadata["source"])
 		assert.Equal(t, "1.0", eventMetadata["version"])
 
-		// Verify correlation_id is a string and matches response ID
-		correlationID, ok := eventMetadata["correlation_id"].(string)
-		assert.True(t, ok)
-		assert.Equal(t, response.ID.String(), correlationID)
+		// An event written outside of a request starts its own correlation
+		assert.NotContains(t, eventMetadata, "correlation_id")
+		assert.Equal(t, event.ID.String(), event.CorrelationID)
+	})
+
+	t.Run("outbox_event_is_correlated_with_the_request", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+
+		ctx := correlation.WithIDs(context.Background(), correlation.IDs{CorrelationID: "checkout-42", CausationID: "request-7"})
+		response, err := orderUseCase.CreateOrder(ctx, createValidOrderRequest())
+		require.NoError(t, err)
+
+		var event models.OutboxEvent
+		require.NoError(t, db.Where("aggregate_id = ?", response.ID.String()).First(&event).Error)
+		assert.Equal(t, "checkout-42", event.CorrelationID)
+		assert.Equal(t, "request-7", event.CausationID)
+
+		correlated, err := usecases.NewOutboxUseCase(outboxRepo).ListCorrelatedEvents(ctx, "checkout-42")
+		require.NoError(t, err)
+		require.Equal(t, 1, correlated.Count)
+		assert.Equal(t, event.ID, correlated.Events[0].ID)
 	})
 
 	t.Run("outbox_event_has_correct_timestamps", func(t *testing.T) {
@@ -23,8 +23,8 @@ func TestOutboxFailureScenarios(t *testing.T) {
 	defer tests.TeardownTestDatabase(t)
 
 	orderRepo := repositories.NewOrderRepository(db)
-	outboxRepo := repositories.NewOutboxRepository(db)
-	orderUseCase := usecases.NewOrderUseCase(orderRepo, outboxRepo, db)
+	outboxRepo := repositories.NewOutboxRepository(db, nil)
+	orderUseCase := usecases.NewOrderUseCase(orderRepo, outboxRepo, db, nil)
 
 	t.Run("database_constraint_violation_rollbacks_transaction", func(t *testing.T) {
 		tests.CleanupTestDatabase(t, db)
@@ -188,8 +188,8 @@ func TestTransactionIsolation(t *testing.T) {
 	defer tests.TeardownTestDatabase(t)
 
 	orderRepo := repositories.NewOrderRepository(db)
-	outboxRepo := repositories.NewOutboxRepository(db)
-	orderUseCase := usecases.NewOrderUseCase(orderRepo, outboxRepo, db)
+	outboxRepo := repositories.NewOutboxRepository(db, nil)
+	orderUseCase := usecases.NewOrderUseCase(orderRepo, outboxRepo, db, nil)
 
 	t.Run("concurrent_transactions_are_isolated", func(t *testing.T) {
 		tests.CleanupTestDatabase(t, db)
@@ -271,8 +271,8 @@ func TestOutboxEventDataIntegrity(t *testing.T) {
 	defer tests.TeardownTestDatabase(t)
 
 	orderRepo := repositories.NewOrderRepository(db)
-	outboxRepo := repositories.NewOutboxRepository(db)
-	orderUseCase := usecases.NewOrderUseCase(orderRepo, outboxRepo, db)
+	outboxRepo := repositories.NewOutboxRepository(db, nil)
+	orderUseCase := usecases.NewOrderUseCase(orderRepo, outboxRepo, db, nil)
 
 	t.Run("outbox_event_data_matches_order_data", func(t *testing.T) {
 		tests.CleanupTestDatabase(t, db)
@@ -350,10 +350,9 @@ func TestOutboxEventDataIntegrity(t *testing.T) {
 		assert.Equal(t, "txstream-api", eventMetadata["source"])
 		assert.Equal(t, "1.0", eventMetadata["version"])
 
-		// Verify correlation_id is a string and matches response ID
-		correlationID, ok := eventMetadata["correlation_id"].(string)
-		assert.True(t, ok)
-		assert.NotEmpty(t, correlationID)
-		assert.Equal(t, response.ID.String(), correlationID)
+		// An event written outside of a request starts its own correlation
+		assert.NotContains(t, eventMetadata, "correlation_id")
+		assert.Equal(t, event.ID.String(), event.CorrelationID)
+		assert.Empty(t, event.CausationID)
 	})
 }
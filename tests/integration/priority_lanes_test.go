@@ -0,0 +1,105 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/tests"
+)
+
+func TestPriorityLanesKeepAggregateOrder(t *testing.T) {
+	db := tests.SetupTestDatabase(t)
+	defer tests.TeardownTestDatabase(t)
+	tests.CleanupTestDatabase(t, db)
+
+	ctx := context.Background()
+	outboxRepo := repositories.NewOutboxRepository(db, nil)
+	aggregateID := uuid.New().String()
+
+	newEvent := func(eventType string, priority models.OutboxPriority, createdAt time.Time) *models.OutboxEvent {
+		return &models.OutboxEvent{
+			AggregateID:   aggregateID,
+			AggregateType: "Order",
+			EventType:     eventType,
+			EventData:     models.JSON{"order_id": aggregateID},
+			Status:        models.OutboxStatusPending,
+			Priority:      priority,
+			CreatedAt:     createdAt,
+		}
+	}
+
+	createdAt := time.Now().Add(-time.Minute)
+	created := newEvent("OrderCreated", models.OutboxPriorityNormal, createdAt)
+	cancelled := newEvent("OrderCancelled", models.OutboxPriorityHigh, createdAt.Add(time.Second))
+	require.NoError(t, outboxRepo.Create(ctx, created))
+	require.NoError(t, outboxRepo.Create(ctx, cancelled))
+
+	high, err := outboxRepo.GetPendingEventsByPriority(ctx, models.OutboxPriorityHigh, 10)
+	require.NoError(t, err)
+	assert.Empty(t, high, "the high lane waits for the older event of the aggregate in the normal lane")
+
+	normal, err := outboxRepo.GetPendingEventsByPriority(ctx, models.OutboxPriorityNormal, 10)
+	require.NoError(t, err)
+	require.Len(t, normal, 1)
+	assert.Equal(t, created.ID, normal[0].ID)
+
+	require.NoError(t, outboxRepo.MarkAsPublished(ctx, created.ID.String()))
+
+	high, err = outboxRepo.GetPendingEventsByPriority(ctx, models.OutboxPriorityHigh, 10)
+	require.NoError(t, err)
+	require.Len(t, high, 1)
+	assert.Equal(t, cancelled.ID, high[0].ID)
+}
+
+func TestPendingEventsFollowAggregateVersions(t *testing.T) {
+	db := tests.SetupTestDatabase(t)
+	defer tests.TeardownTestDatabase(t)
+	tests.CleanupTestDatabase(t, db)
+
+	ctx := context.Background()
+	outboxRepo := repositories.NewOutboxRepository(db, nil)
+	aggregateID := uuid.New().String()
+
+	newEvent := func(eventType string, version int64, createdAt time.Time) *models.OutboxEvent {
+		return &models.OutboxEvent{
+			AggregateID:      aggregateID,
+			AggregateType:    "Order",
+			AggregateVersion: version,
+			EventType:        eventType,
+			EventData:        models.JSON{"order_id": aggregateID},
+			Status:           models.OutboxStatusPending,
+			CreatedAt:        createdAt,
+		}
+	}
+
+	// A redelivered version 1 is written after version 2
+	createdAt := time.Now().Add(-time.Minute)
+	paid := newEvent("OrderPaid", 2, createdAt)
+	redelivered := newEvent("OrderCreated", 1, createdAt.Add(time.Second))
+	require.NoError(t, outboxRepo.Create(ctx, paid))
+	require.NoError(t, outboxRepo.Create(ctx, redelivered))
+
+	pending, err := outboxRepo.GetPendingEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1, "version 2 waits for the version 1 created after it")
+	assert.Equal(t, redelivered.ID, pending[0].ID)
+
+	normal, err := outboxRepo.GetPendingEventsByPriority(ctx, models.OutboxPriorityNormal, 10)
+	require.NoError(t, err)
+	require.Len(t, normal, 1)
+	assert.Equal(t, redelivered.ID, normal[0].ID)
+
+	require.NoError(t, outboxRepo.MarkAsPublished(ctx, redelivered.ID.String()))
+
+	pending, err = outboxRepo.GetPendingEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, paid.ID, pending[0].ID)
+}
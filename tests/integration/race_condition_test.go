@@ -22,7 +22,7 @@ func TestRaceConditionHandling(t *testing.T) {
 	db := tests.SetupTestDatabase(t)
 	defer tests.TeardownTestDatabase(t)
 
-	outboxRepo := repositories.NewOutboxRepository(db)
+	outboxRepo := repositories.NewOutboxRepository(db, nil)
 
 	workerConfig := &config.WorkerConfig{
 		PoolSize:   2,
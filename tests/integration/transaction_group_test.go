@@ -0,0 +1,238 @@
+package integration
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/consumer"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/inbox"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/tests"
+)
+
+func TestOutboxTransactionGroups(t *testing.T) {
+	db := tests.SetupTestDatabase(t)
+	defer tests.TeardownTestDatabase(t)
+
+	newEvent := func(aggregateID string) *models.OutboxEvent {
+		return &models.OutboxEvent{
+			AggregateID:   aggregateID,
+			AggregateType: "Order",
+			EventType:     "OrderCreated",
+			EventData:     models.JSON{"order_id": aggregateID},
+			Status:        models.OutboxStatusPending,
+		}
+	}
+
+	stored := func(t *testing.T, event *models.OutboxEvent) models.OutboxEvent {
+		var row models.OutboxEvent
+		require.NoError(t, db.First(&row, "id = ?", event.ID).Error)
+		return row
+	}
+
+	// claimed returns the event as claimed by a worker, with the count of its transaction
+	outboxRepo := repositories.NewOutboxRepository(db, nil)
+	claimed := func(t *testing.T, event *models.OutboxEvent) *models.OutboxEvent {
+		row, err := outboxRepo.ClaimEvent(context.Background(), event.ID.String(), "worker-1", time.Minute)
+		require.NoError(t, err)
+		return row
+	}
+
+	counted := func(t *testing.T, transactionID uuid.UUID) int {
+		var transaction models.OutboxTransaction
+		require.NoError(t, db.First(&transaction, "transaction_id = ?", transactionID).Error)
+		return transaction.EventCount
+	}
+
+	t.Run("events_of_a_transaction_share_its_id_and_count", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		written := []*models.OutboxEvent{newEvent("order-1"), newEvent("order-2"), newEvent("order-3")}
+
+		require.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+			for _, event := range written {
+				if err := tx.Create(event).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+
+		first := stored(t, written[0])
+		require.NotNil(t, first.TransactionID)
+		assert.Equal(t, 3, counted(t, *first.TransactionID), "the transaction is counted once, not on every event")
+		for _, event := range written {
+			row := stored(t, event)
+			assert.Equal(t, *first.TransactionID, *row.TransactionID)
+			assert.Equal(t, 0, row.TransactionEventCount, "the count is copied when the event is claimed")
+			assert.Equal(t, 3, claimed(t, event).TransactionEventCount)
+		}
+		assert.Equal(t, 3, stored(t, written[0]).TransactionEventCount)
+	})
+
+	t.Run("batch_insert_counts_every_event", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		written := []models.OutboxEvent{*newEvent("order-1"), *newEvent("order-2")}
+
+		require.NoError(t, db.CreateInBatches(&written, 100).Error)
+
+		for i := range written {
+			assert.Equal(t, 2, claimed(t, &written[i]).TransactionEventCount)
+		}
+	})
+
+	t.Run("separate_transactions_are_separate_groups", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		first, second := newEvent("order-1"), newEvent("order-1")
+
+		require.NoError(t, db.Create(first).Error)
+		require.NoError(t, db.Create(second).Error)
+
+		assert.NotEqual(t, *stored(t, first).TransactionID, *stored(t, second).TransactionID)
+		assert.Equal(t, 1, claimed(t, first).TransactionEventCount)
+		assert.Equal(t, 1, claimed(t, second).TransactionEventCount)
+	})
+}
+
+func TestInboxTransactionGroups(t *testing.T) {
+	db := tests.SetupTestDatabase(t)
+	defer tests.TeardownTestDatabase(t)
+
+	ctx := context.Background()
+
+	newGroup := func(size int) []*consumer.Event {
+		transactionID := uuid.New().String()
+		createdAt := time.Now().Truncate(time.Second)
+		group := make([]*consumer.Event, size)
+		for i := range group {
+			group[i] = &consumer.Event{
+				ID:                    uuid.New().String(),
+				AggregateID:           "order-1",
+				AggregateVersion:      int64(i + 1),
+				EventType:             "OrderCreated",
+				CreatedAt:             createdAt,
+				TransactionID:         transactionID,
+				TransactionEventCount: size,
+			}
+		}
+		return group
+	}
+
+	// recorder collects the groups passed to the handler
+	type recorder struct {
+		mu     sync.Mutex
+		groups [][]string
+	}
+	record := func(r *recorder) inbox.GroupHandlerFunc {
+		return func(ctx context.Context, tx *gorm.DB, events []*consumer.Event) error {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			var ids []string
+			for _, event := range events {
+				ids = append(ids, event.ID)
+			}
+			r.groups = append(r.groups, ids)
+			return nil
+		}
+	}
+
+	t.Run("group_is_applied_once_complete", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		eventInbox := inbox.NewInbox(db, "txstream-test", nil, nil)
+		group := newGroup(3)
+		received := &recorder{}
+
+		for _, event := range []*consumer.Event{group[2], group[0]} {
+			applied, err := eventInbox.Collect(ctx, event, record(received))
+			require.NoError(t, err)
+			assert.False(t, applied)
+		}
+		assert.Empty(t, received.groups)
+
+		applied, err := eventInbox.Collect(ctx, group[1], record(received))
+		require.NoError(t, err)
+		assert.True(t, applied)
+		assert.Equal(t, [][]string{{group[0].ID, group[1].ID, group[2].ID}}, received.groups, "events are ordered by version")
+
+		applied, err = eventInbox.Collect(ctx, group[1], record(received))
+		require.NoError(t, err)
+		assert.False(t, applied, "a redelivery after the group was applied is skipped")
+
+		var held int64
+		require.NoError(t, db.Model(&models.InboxGroupEvent{}).Count(&held).Error)
+		assert.Equal(t, int64(0), held)
+	})
+
+	t.Run("incomplete_group_is_handed_over_after_the_timeout", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		eventInbox := inbox.NewInbox(db, "txstream-test", nil, nil)
+		group := newGroup(3)
+		received := &recorder{}
+
+		for _, event := range group[:2] {
+			applied, err := eventInbox.Collect(ctx, event, record(received))
+			require.NoError(t, err)
+			assert.False(t, applied)
+		}
+
+		job := inbox.NewGroupTimeoutJob(config.InboxConfig{GroupTimeout: time.Millisecond, BatchSize: 10}, eventInbox, record(received), nil)
+		time.Sleep(10 * time.Millisecond)
+		completed, err := job.Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, completed)
+		assert.Equal(t, [][]string{{group[0].ID, group[1].ID}}, received.groups, "the partial group reaches the handler")
+
+		completed, err = job.Run(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, completed, "a group is handed over once")
+
+		applied, err := eventInbox.Collect(ctx, group[2], record(received))
+		require.NoError(t, err)
+		assert.True(t, applied, "the missing event arriving late is applied alone")
+		assert.Equal(t, []string{group[2].ID}, received.groups[1])
+
+		var held int64
+		require.NoError(t, db.Model(&models.InboxGroupEvent{}).Count(&held).Error)
+		assert.Equal(t, int64(0), held)
+	})
+
+	t.Run("ungrouped_event_is_applied_alone", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		received := &recorder{}
+		event := &consumer.Event{ID: uuid.New().String(), EventType: "OrderCreated"}
+
+		applied, err := inbox.NewInbox(db, "txstream-test", nil, nil).Collect(ctx, event, record(received))
+		require.NoError(t, err)
+		assert.True(t, applied)
+		assert.Equal(t, [][]string{{event.ID}}, received.groups)
+	})
+
+	t.Run("concurrent_deliveries_apply_the_group_once", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+		eventInbox := inbox.NewInbox(db, "txstream-test", nil, nil)
+		group := newGroup(4)
+		received := &recorder{}
+
+		var wg sync.WaitGroup
+		for _, event := range group {
+			wg.Add(1)
+			go func(event *consumer.Event) {
+				defer wg.Done()
+				_, err := eventInbox.Collect(ctx, event, record(received))
+				assert.NoError(t, err)
+			}(event)
+		}
+		wg.Wait()
+
+		require.Len(t, received.groups, 1)
+		assert.Len(t, received.groups[0], 4)
+	})
+}
@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/worker"
+	"github.com/lorenaziviani/txstream/tests"
+)
+
+func TestOutboxWatchdog(t *testing.T) {
+	db := tests.SetupTestDatabase(t)
+	defer tests.TeardownTestDatabase(t)
+
+	outboxRepo := repositories.NewOutboxRepository(db, nil)
+	ctx := context.Background()
+
+	watchdogConfig := config.WatchdogConfig{
+		Enabled:        true,
+		Interval:       time.Second,
+		StuckThreshold: 5 * time.Minute,
+		AlertLimit:     10,
+	}
+
+	newEvent := func(age time.Duration) *models.OutboxEvent {
+		event := &models.OutboxEvent{
+			ID:            uuid.New(),
+			AggregateID:   uuid.New().String(),
+			AggregateType: "Order",
+			EventType:     "OrderCreated",
+			EventData:     models.JSON{"order_id": uuid.New().String()},
+			Status:        models.OutboxStatusPending,
+			CreatedAt:     time.Now().Add(-age),
+		}
+		require.NoError(t, outboxRepo.Create(ctx, event))
+		return event
+	}
+
+	t.Run("releases_expired_claims", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+
+		event := newEvent(time.Minute)
+		_, err := outboxRepo.ClaimEvent(ctx, event.ID.String(), "crashed-worker", time.Millisecond)
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+
+		report, err := worker.NewWatchdog(watchdogConfig, outboxRepo, nil, nil).Check(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), report.ReleasedClaims)
+
+		dbEvent, err := outboxRepo.GetByID(ctx, event.ID.String())
+		require.NoError(t, err)
+		assert.Empty(t, dbEvent.ClaimedBy)
+		assert.Nil(t, dbEvent.ClaimedUntil)
+	})
+
+	t.Run("reports_stuck_events_and_counts_from_sql", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+
+		newEvent(time.Hour)
+		newEvent(time.Second)
+		published := newEvent(time.Second)
+		require.NoError(t, outboxRepo.MarkAsPublished(ctx, published.ID.String()))
+
+		report, err := worker.NewWatchdog(watchdogConfig, outboxRepo, nil, nil).Check(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1), report.StuckEvents)
+		assert.GreaterOrEqual(t, report.OldestPendingAge, time.Hour)
+		assert.Equal(t, int64(2), report.StatusCounts[models.OutboxStatusPending])
+		assert.Equal(t, int64(1), report.StatusCounts[models.OutboxStatusPublished])
+	})
+
+	t.Run("escalates_events_stuck_beyond_limit_without_failing_them", func(t *testing.T) {
+		tests.CleanupTestDatabase(t, db)
+
+		stuck := newEvent(2 * time.Hour)
+		newEvent(10 * time.Minute)
+
+		cfg := watchdogConfig
+		cfg.EscalateStuckAfter = time.Hour
+
+		report, err := worker.NewWatchdog(cfg, outboxRepo, nil, nil).Check(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), report.StuckEvents)
+		assert.Equal(t, int64(1), report.EscalatedEvents)
+
+		dbEvent, err := outboxRepo.GetByID(ctx, stuck.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, models.OutboxStatusPending, dbEvent.Status, "the worker keeps the escalated event queued")
+	})
+}
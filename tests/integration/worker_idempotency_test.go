@@ -21,7 +21,7 @@ func TestWorkerIdempotency(t *testing.T) {
 	db := tests.SetupTestDatabase(t)
 	defer tests.TeardownTestDatabase(t)
 
-	outboxRepo := repositories.NewOutboxRepository(db)
+	outboxRepo := repositories.NewOutboxRepository(db, nil)
 
 	workerConfig := &config.WorkerConfig{
 		PoolSize:   2,
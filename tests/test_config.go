@@ -40,7 +40,7 @@ func SetupTestDatabase(t *testing.T) *gorm.DB {
 	os.Setenv("DB_NAME", config.DBName)
 	os.Setenv("DB_SSL_MODE", config.DBSSLMode)
 
-	err := database.InitializeDatabase()
+	err := database.InitializeDatabase(nil, nil)
 	require.NoError(t, err)
 
 	db := database.GetDB()
@@ -56,6 +56,11 @@ func CleanupTestDatabase(t *testing.T, db *gorm.DB) {
 	db.Exec("DELETE FROM order_items")
 	db.Exec("DELETE FROM orders")
 	db.Exec("DELETE FROM events")
+	db.Exec("DELETE FROM inbox")
+	db.Exec("DELETE FROM inbox_group_events")
+	db.Exec("DELETE FROM aggregate_versions")
+	db.Exec("DELETE FROM outbox_transactions")
+	db.Exec("DELETE FROM backfill_order_snapshots")
 }
 
 func TeardownTestDatabase(t *testing.T) {
@@ -0,0 +1,134 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/application/dto"
+	"github.com/lorenaziviani/txstream/internal/application/usecases"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/handlers"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+)
+
+// gatheredValue returns the value of the counter or the sample count of the histogram
+// named name whose labels include labels
+func gatheredValue(t *testing.T, registry *prometheus.Registry, name string, labels map[string]string) float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metricLoop:
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if expected, ok := labels[label.GetName()]; ok && expected != label.GetValue() {
+					continue metricLoop
+				}
+			}
+			if metric.GetHistogram() != nil {
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+			return metric.GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestAccessLogMiddleware(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	apiMetrics := metrics.NewMetrics(registry)
+
+	var buffer bytes.Buffer
+	logger, err := logging.NewWithWriter(&buffer, loggingTestConfig())
+	require.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.Use(handlers.RequestIDMiddleware)
+	router.Use(handlers.AccessLogMiddleware(apiMetrics, logger))
+	router.HandleFunc("/api/v1/orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"order not found"}`))
+	}).Methods(http.MethodGet)
+
+	for _, id := range []string{"a", "b", "c"} {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/orders/"+id, nil)
+		request.Header.Set(handlers.RequestIDHeader, "req-"+id)
+		router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	labels := map[string]string{"method": "GET", "route": "/api/v1/orders/{id}", "status": "404"}
+	assert.Equal(t, float64(3), gatheredValue(t, registry, "txstream_http_requests_total", labels))
+	assert.Equal(t, float64(3), gatheredValue(t, registry, "txstream_http_request_duration_seconds", labels))
+	assert.Equal(t, float64(3), gatheredValue(t, registry, "txstream_http_response_size_bytes", labels))
+
+	records := decodeLogLines(t, &buffer)
+	require.Len(t, records, 3)
+	assert.Equal(t, "Request handled", records[0]["msg"])
+	assert.Equal(t, "req-a", records[0][logging.RequestIDKey])
+	assert.Equal(t, "/api/v1/orders/{id}", records[0]["route"])
+	assert.Equal(t, "/api/v1/orders/a", records[0]["path"])
+	assert.Equal(t, float64(http.StatusNotFound), records[0]["status"])
+	assert.Equal(t, float64(len(`{"error":"order not found"}`)), records[0]["bytes"])
+}
+
+func TestAccessLogMiddlewareUnmatchedRoute(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	apiMetrics := metrics.NewMetrics(registry)
+
+	handler := handlers.AccessLogMiddleware(apiMetrics, nil)(http.NotFoundHandler())
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown/path", nil))
+
+	labels := map[string]string{"route": handlers.UnmatchedRoute, "status": "404"}
+	assert.Equal(t, float64(1), gatheredValue(t, registry, "txstream_http_requests_total", labels))
+}
+
+// failingOrderUseCase fails every order creation with err
+type failingOrderUseCase struct {
+	usecases.OrderUseCase
+	err error
+}
+
+func (u *failingOrderUseCase) CreateOrder(ctx context.Context, request *dto.CreateOrderRequest) (*dto.OrderResponse, error) {
+	return nil, u.err
+}
+
+func TestOrderHandlerFailureMetrics(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		reason string
+	}{
+		{"invalid_payload", "{", nil, handlers.OrderFailureInvalidPayload},
+		{"validation", "{}", errors.New("validation error: customer_id is required"), handlers.OrderFailureValidation},
+		{"duplicate", "{}", errors.New("order with number ORD-1 already exists"), handlers.OrderFailureDuplicate},
+		{"internal", "{}", errors.New("connection refused"), handlers.OrderFailureInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			handler := handlers.NewOrderHandler(&failingOrderUseCase{err: tt.err}, metrics.NewMetrics(registry), nil)
+
+			recorder := httptest.NewRecorder()
+			handler.CreateOrderHandler(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(tt.body)))
+
+			assert.GreaterOrEqual(t, recorder.Code, http.StatusBadRequest)
+			assert.Equal(t, float64(1), gatheredValue(t, registry, "txstream_orders_failed_total", map[string]string{"reason": tt.reason}))
+			assert.Equal(t, float64(0), gatheredValue(t, registry, "txstream_orders_created_total", nil))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/worker"
+)
+
+func TestAdaptiveController(t *testing.T) {
+	workerConfig := config.WorkerConfig{
+		BatchSize:           10,
+		Interval:            5 * time.Second,
+		AdaptiveEnabled:     true,
+		MinInterval:         100 * time.Millisecond,
+		MaxInterval:         30 * time.Second,
+		MinBatchSize:        10,
+		MaxBatchSize:        500,
+		TargetBatchDuration: 2 * time.Second,
+	}
+
+	t.Run("full_batch_drains_without_waiting", func(t *testing.T) {
+		controller := worker.NewAdaptiveController(workerConfig)
+
+		controller.Observe(10, 0, 10, 5*time.Millisecond, 10*time.Millisecond)
+
+		assert.Zero(t, controller.Interval())
+	})
+
+	t.Run("idle_polls_back_off_up_to_max_interval", func(t *testing.T) {
+		controller := worker.NewAdaptiveController(workerConfig)
+		controller.Observe(10, 0, 10, 5*time.Millisecond, 10*time.Millisecond)
+
+		controller.Observe(0, 0, 10, 5*time.Millisecond, 0)
+		assert.Equal(t, 100*time.Millisecond, controller.Interval())
+
+		controller.Observe(0, 0, 10, 5*time.Millisecond, 0)
+		assert.Equal(t, 200*time.Millisecond, controller.Interval())
+
+		for i := 0; i < 20; i++ {
+			controller.Observe(0, 0, 10, 5*time.Millisecond, 0)
+		}
+		assert.Equal(t, 30*time.Second, controller.Interval())
+	})
+
+	t.Run("fast_publishes_grow_batch_size", func(t *testing.T) {
+		controller := worker.NewAdaptiveController(workerConfig)
+
+		for i := 0; i < 10; i++ {
+			controller.Observe(controller.BatchSize(), 0, controller.BatchSize(), 10*time.Millisecond, 5*time.Millisecond)
+		}
+
+		assert.Equal(t, 398, controller.BatchSize(), "batch should fit the target duration")
+	})
+
+	t.Run("slow_publishes_shrink_batch_size", func(t *testing.T) {
+		slowConfig := workerConfig
+		slowConfig.BatchSize = 400
+		controller := worker.NewAdaptiveController(slowConfig)
+
+		for i := 0; i < 10; i++ {
+			controller.Observe(controller.BatchSize(), 0, controller.BatchSize(), 10*time.Millisecond, 100*time.Millisecond)
+		}
+
+		assert.Equal(t, 19, controller.BatchSize())
+	})
+
+	t.Run("partial_batches_never_grow_batch_size", func(t *testing.T) {
+		controller := worker.NewAdaptiveController(workerConfig)
+
+		controller.Observe(3, 0, 10, 5*time.Millisecond, time.Millisecond)
+
+		assert.Equal(t, 10, controller.BatchSize())
+		assert.Equal(t, 100*time.Millisecond, controller.Interval())
+	})
+
+	t.Run("failed_publishes_back_off", func(t *testing.T) {
+		controller := worker.NewAdaptiveController(workerConfig)
+		controller.Observe(10, 0, 10, 5*time.Millisecond, 10*time.Millisecond)
+		require.Zero(t, controller.Interval())
+		batchSize := controller.BatchSize()
+
+		controller.Observe(0, 10, 10, 5*time.Millisecond, time.Millisecond)
+		assert.Equal(t, 100*time.Millisecond, controller.Interval(), "a batch of failures does not poll again immediately")
+
+		controller.Observe(0, 10, 10, 5*time.Millisecond, time.Millisecond)
+		assert.Equal(t, 200*time.Millisecond, controller.Interval())
+		assert.Equal(t, batchSize, controller.BatchSize(), "failed publishes do not tune the batch size")
+	})
+
+	t.Run("partially_failed_batch_backs_off", func(t *testing.T) {
+		controller := worker.NewAdaptiveController(workerConfig)
+		controller.Observe(10, 0, 10, 5*time.Millisecond, 10*time.Millisecond)
+
+		controller.Observe(7, 3, 10, 5*time.Millisecond, 10*time.Millisecond)
+
+		assert.Equal(t, 100*time.Millisecond, controller.Interval())
+	})
+}
@@ -0,0 +1,133 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/worker"
+)
+
+func chainEvent(aggregateID, eventType string) models.OutboxEvent {
+	return models.OutboxEvent{
+		ID:            uuid.New(),
+		AggregateID:   aggregateID,
+		AggregateType: "Order",
+		EventType:     eventType,
+	}
+}
+
+func TestGroupByAggregate(t *testing.T) {
+	events := []models.OutboxEvent{
+		chainEvent("order-1", "OrderCreated"),
+		chainEvent("order-2", "OrderCreated"),
+		chainEvent("order-1", "OrderPaid"),
+		chainEvent("order-1", "OrderShipped"),
+	}
+
+	chains := worker.GroupByAggregate(events)
+
+	require.Len(t, chains, 2)
+	assert.Equal(t, []models.OutboxEvent{events[0], events[2], events[3]}, chains[0])
+	assert.Equal(t, []models.OutboxEvent{events[1]}, chains[1])
+}
+
+func TestGroupByAggregateOrdersChainsByVersion(t *testing.T) {
+	createdAt := time.Now()
+	versioned := func(version int64, age time.Duration) models.OutboxEvent {
+		event := chainEvent("order-1", "OrderUpdated")
+		event.AggregateVersion = version
+		event.CreatedAt = createdAt.Add(-age)
+		return event
+	}
+	// A redelivery keeps its original version but is created last
+	events := []models.OutboxEvent{versioned(3, 3*time.Second), versioned(2, time.Second), versioned(1, 0)}
+	legacy := []models.OutboxEvent{versioned(0, time.Second), versioned(0, 2*time.Second)}
+
+	chains := worker.GroupByAggregate(events)
+	require.Len(t, chains, 1)
+	assert.Equal(t, []models.OutboxEvent{events[2], events[1], events[0]}, chains[0])
+
+	chains = worker.GroupByAggregate(legacy)
+	require.Len(t, chains, 1)
+	assert.Equal(t, []models.OutboxEvent{legacy[1], legacy[0]}, chains[0], "events without version follow their creation date")
+}
+
+func TestChainPool(t *testing.T) {
+	t.Run("processes_each_chain_in_order", func(t *testing.T) {
+		events := []models.OutboxEvent{
+			chainEvent("order-1", "OrderCreated"),
+			chainEvent("order-2", "OrderCreated"),
+			chainEvent("order-1", "OrderPaid"),
+			chainEvent("order-2", "OrderPaid"),
+		}
+
+		var mu sync.Mutex
+		processed := map[string][]string{}
+		worker.NewChainPool(2, nil).Run(context.Background(), worker.GroupByAggregate(events), func(ctx context.Context, event *models.OutboxEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			processed[event.AggregateID] = append(processed[event.AggregateID], event.EventType)
+			return nil
+		})
+
+		assert.Equal(t, []string{"OrderCreated", "OrderPaid"}, processed["order-1"])
+		assert.Equal(t, []string{"OrderCreated", "OrderPaid"}, processed["order-2"])
+	})
+
+	t.Run("error_stops_only_its_chain", func(t *testing.T) {
+		events := []models.OutboxEvent{
+			chainEvent("order-1", "OrderCreated"),
+			chainEvent("order-1", "OrderPaid"),
+			chainEvent("order-2", "OrderCreated"),
+			chainEvent("order-2", "OrderPaid"),
+		}
+
+		var mu sync.Mutex
+		var processed []models.OutboxEvent
+		worker.NewChainPool(1, nil).Run(context.Background(), worker.GroupByAggregate(events), func(ctx context.Context, event *models.OutboxEvent) error {
+			mu.Lock()
+			processed = append(processed, *event)
+			mu.Unlock()
+			if event.ID == events[0].ID {
+				return errors.New("broker unavailable")
+			}
+			return nil
+		})
+
+		assert.Equal(t, []models.OutboxEvent{events[0], events[2], events[3]}, processed,
+			"the rest of the failed aggregate is left for the next batch")
+	})
+
+	t.Run("runs_at_most_pool_size_chains_at_once", func(t *testing.T) {
+		var events []models.OutboxEvent
+		for i := 0; i < 8; i++ {
+			events = append(events, chainEvent(uuid.NewString(), "OrderCreated"))
+		}
+
+		var running, peak int64
+		worker.NewChainPool(3, nil).Run(context.Background(), worker.GroupByAggregate(events), func(ctx context.Context, event *models.OutboxEvent) error {
+			current := atomic.AddInt64(&running, 1)
+			for {
+				observed := atomic.LoadInt64(&peak)
+				if current <= observed || atomic.CompareAndSwapInt64(&peak, observed, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt64(&running, -1)
+			return nil
+		})
+
+		assert.LessOrEqual(t, peak, int64(3))
+		assert.Greater(t, peak, int64(1), "independent aggregates are processed concurrently")
+	})
+}
@@ -11,8 +11,8 @@ import (
 )
 
 func TestCircuitBreakerCreation(t *testing.T) {
-	metrics := metrics.NewMetrics()
-	cb := kafka.NewCircuitBreaker(5, 3, 10*time.Second, 30*time.Second, metrics)
+	metrics := metrics.NewMetrics(nil)
+	cb := kafka.NewCircuitBreaker(5, 3, 10*time.Second, 30*time.Second, metrics, nil)
 
 	assert.NotNil(t, cb, "Circuit breaker should not be nil")
 	assert.Equal(t, kafka.StateClosed, cb.GetState(), "Initial state should be CLOSED")
@@ -21,8 +21,8 @@ func TestCircuitBreakerCreation(t *testing.T) {
 }
 
 func TestCircuitBreakerClosedState(t *testing.T) {
-	metrics := metrics.NewMetrics()
-	cb := kafka.NewCircuitBreaker(3, 2, 5*time.Second, 10*time.Second, metrics)
+	metrics := metrics.NewMetrics(nil)
+	cb := kafka.NewCircuitBreaker(3, 2, 5*time.Second, 10*time.Second, metrics, nil)
 
 	err := cb.Execute(context.Background(), func() error {
 		return nil
@@ -35,8 +35,8 @@ func TestCircuitBreakerClosedState(t *testing.T) {
 }
 
 func TestCircuitBreakerFailureThreshold(t *testing.T) {
-	metrics := metrics.NewMetrics()
-	cb := kafka.NewCircuitBreaker(2, 1, 5*time.Second, 10*time.Second, metrics)
+	metrics := metrics.NewMetrics(nil)
+	cb := kafka.NewCircuitBreaker(2, 1, 5*time.Second, 10*time.Second, metrics, nil)
 
 	for i := 0; i < 2; i++ {
 		err := cb.Execute(context.Background(), func() error {
@@ -51,8 +51,8 @@ func TestCircuitBreakerFailureThreshold(t *testing.T) {
 }
 
 func TestCircuitBreakerOpenState(t *testing.T) {
-	metrics := metrics.NewMetrics()
-	cb := kafka.NewCircuitBreaker(2, 1, 5*time.Second, 10*time.Second, metrics)
+	metrics := metrics.NewMetrics(nil)
+	cb := kafka.NewCircuitBreaker(2, 1, 5*time.Second, 10*time.Second, metrics, nil)
 
 	// Trigger circuit to open
 	for i := 0; i < 2; i++ {
@@ -73,8 +73,8 @@ func TestCircuitBreakerOpenState(t *testing.T) {
 }
 
 func TestCircuitBreakerTimeout(t *testing.T) {
-	metrics := metrics.NewMetrics()
-	cb := kafka.NewCircuitBreaker(2, 1, 5*time.Second, 100*time.Millisecond, metrics)
+	metrics := metrics.NewMetrics(nil)
+	cb := kafka.NewCircuitBreaker(2, 1, 5*time.Second, 100*time.Millisecond, metrics, nil)
 
 	// Trigger circuit to open
 	for i := 0; i < 2; i++ {
@@ -98,8 +98,8 @@ func TestCircuitBreakerTimeout(t *testing.T) {
 }
 
 func TestCircuitBreakerHalfOpenSuccess(t *testing.T) {
-	metrics := metrics.NewMetrics()
-	cb := kafka.NewCircuitBreaker(2, 2, 5*time.Second, 100*time.Millisecond, metrics)
+	metrics := metrics.NewMetrics(nil)
+	cb := kafka.NewCircuitBreaker(2, 2, 5*time.Second, 100*time.Millisecond, metrics, nil)
 
 	// Trigger circuit to open
 	for i := 0; i < 2; i++ {
@@ -133,8 +133,8 @@ func TestCircuitBreakerHalfOpenSuccess(t *testing.T) {
 }
 
 func TestCircuitBreakerHalfOpenFailure(t *testing.T) {
-	metrics := metrics.NewMetrics()
-	cb := kafka.NewCircuitBreaker(2, 2, 5*time.Second, 100*time.Millisecond, metrics)
+	metrics := metrics.NewMetrics(nil)
+	cb := kafka.NewCircuitBreaker(2, 2, 5*time.Second, 100*time.Millisecond, metrics, nil)
 
 	// Trigger circuit to open
 	for i := 0; i < 2; i++ {
@@ -158,8 +158,8 @@ func TestCircuitBreakerHalfOpenFailure(t *testing.T) {
 }
 
 func TestCircuitBreakerForceMethods(t *testing.T) {
-	metrics := metrics.NewMetrics()
-	cb := kafka.NewCircuitBreaker(5, 3, 10*time.Second, 30*time.Second, metrics)
+	metrics := metrics.NewMetrics(nil)
+	cb := kafka.NewCircuitBreaker(5, 3, 10*time.Second, 30*time.Second, metrics, nil)
 
 	// Test force open
 	cb.ForceOpen()
@@ -171,8 +171,8 @@ func TestCircuitBreakerForceMethods(t *testing.T) {
 }
 
 func TestCircuitBreakerStats(t *testing.T) {
-	metrics := metrics.NewMetrics()
-	cb := kafka.NewCircuitBreaker(3, 2, 5*time.Second, 10*time.Second, metrics)
+	metrics := metrics.NewMetrics(nil)
+	cb := kafka.NewCircuitBreaker(3, 2, 5*time.Second, 10*time.Second, metrics, nil)
 
 	stats := cb.GetStats()
 
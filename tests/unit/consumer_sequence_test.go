@@ -0,0 +1,214 @@
+package unit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/consumer"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/replay"
+)
+
+// handledEvent is an event received by a recordingHandler
+type handledEvent struct {
+	version  int64
+	sequence string
+}
+
+// recordingHandler records the versions and sequence statuses of the handled events
+type recordingHandler struct {
+	mu     sync.Mutex
+	events []handledEvent
+}
+
+func (h *recordingHandler) Handle(_ context.Context, event *consumer.Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, handledEvent{version: event.AggregateVersion, sequence: event.Sequence})
+	return nil
+}
+
+func (h *recordingHandler) handled() []handledEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]handledEvent(nil), h.events...)
+}
+
+func (h *recordingHandler) versions() []int64 {
+	versions := []int64{}
+	for _, event := range h.handled() {
+		versions = append(versions, event.version)
+	}
+	return versions
+}
+
+func newSequencedConsumer(t *testing.T, policy string, consumerMetrics *metrics.Metrics, adjust func(*config.ConsumerConfig)) (*consumer.Consumer, *recordingHandler) {
+	cfg := consumerTestConfig()
+	cfg.SequencePolicy = policy
+	if adjust != nil {
+		adjust(&cfg)
+	}
+	require.NoError(t, cfg.Validate())
+
+	handler := &recordingHandler{}
+	registry := consumer.NewRegistry()
+	require.NoError(t, registry.Register("OrderCreated", handler))
+
+	return newConfiguredConsumer(cfg, registry, nil, consumerMetrics), handler
+}
+
+func TestSequenceTracker(t *testing.T) {
+	tracker := consumer.NewSequenceTracker(2)
+
+	assert.Equal(t, consumer.SequenceUnversioned, tracker.Check("order-1", 0))
+	assert.Equal(t, consumer.SequenceInOrder, tracker.Check("order-1", 5), "the first version seen is in order")
+	tracker.Record("order-1", 5)
+
+	assert.Equal(t, consumer.SequenceInOrder, tracker.Check("order-1", 6))
+	assert.Equal(t, consumer.SequenceGap, tracker.Check("order-1", 8))
+	tracker.Record("order-1", 8)
+	assert.Equal(t, int64(8), tracker.Last("order-1"))
+
+	assert.Equal(t, consumer.SequenceLate, tracker.Check("order-1", 6))
+	tracker.Record("order-1", 6)
+	assert.Equal(t, consumer.SequenceDuplicate, tracker.Check("order-1", 6), "a late version is only accepted once")
+	assert.Equal(t, consumer.SequenceLate, tracker.Check("order-1", 7))
+	assert.Equal(t, consumer.SequenceDuplicate, tracker.Check("order-1", 8))
+
+	tracker.Record("order-2", 1)
+	tracker.Record("order-3", 1)
+	assert.Equal(t, int64(0), tracker.Last("order-1"), "the least recently seen aggregate is forgotten")
+	assert.Equal(t, int64(1), tracker.Last("order-3"))
+}
+
+func TestConsumerSequence(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("flag_policy_handles_and_reports_every_event", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		eventConsumer, handler := newSequencedConsumer(t, config.SequencePolicyFlag, metrics.NewMetrics(registry), nil)
+		session := newFakeGroupSession(ctx)
+
+		require.NoError(t, eventConsumer.ConsumeClaim(session, newFakeGroupClaim(
+			newVersionedMessage(1, "order-1", 1),
+			newVersionedMessage(2, "order-1", 3),
+			newVersionedMessage(3, "order-1", 2),
+			newVersionedMessage(4, "order-1", 2),
+		)))
+
+		assert.Equal(t, []handledEvent{
+			{1, consumer.SequenceInOrder},
+			{3, consumer.SequenceGap},
+			{2, consumer.SequenceLate},
+			{2, consumer.SequenceDuplicate},
+		}, handler.handled())
+		assert.Equal(t, []int64{1, 2, 3, 4}, session.markedOffsets())
+		for _, status := range []string{consumer.SequenceGap, consumer.SequenceLate, consumer.SequenceDuplicate} {
+			assert.Equal(t, float64(1), gatheredValue(t, registry, "txstream_consumer_sequence_anomalies_total",
+				map[string]string{"status": status}), status)
+		}
+	})
+
+	t.Run("buffer_policy_reorders_and_skips_duplicates", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		eventConsumer, handler := newSequencedConsumer(t, config.SequencePolicyBuffer, metrics.NewMetrics(registry), nil)
+		session := newFakeGroupSession(ctx)
+
+		require.NoError(t, eventConsumer.ConsumeClaim(session, newFakeGroupClaim(
+			newVersionedMessage(1, "order-1", 1),
+			newVersionedMessage(2, "order-1", 3),
+			newVersionedMessage(3, "order-2", 1),
+			newVersionedMessage(4, "order-1", 4),
+			newVersionedMessage(5, "order-1", 2),
+			newVersionedMessage(6, "order-1", 2),
+		)))
+
+		assert.Equal(t, []int64{1, 1, 2, 3, 4}, handler.versions())
+		for _, event := range handler.handled() {
+			assert.Equal(t, consumer.SequenceInOrder, event.sequence)
+		}
+		assert.Equal(t, int64(7), session.committedPosition())
+		assert.Equal(t, float64(1), gatheredValue(t, registry, "txstream_consumer_messages_total",
+			map[string]string{"outcome": consumer.OutcomeDuplicate}))
+	})
+
+	t.Run("held_events_are_not_committed", func(t *testing.T) {
+		eventConsumer, handler := newSequencedConsumer(t, config.SequencePolicyBuffer, nil, nil)
+		session := newFakeGroupSession(ctx)
+
+		require.NoError(t, eventConsumer.ConsumeClaim(session, newFakeGroupClaim(
+			newVersionedMessage(10, "order-1", 1),
+			newVersionedMessage(11, "order-1", 3),
+			newVersionedMessage(12, "order-2", 1),
+		)))
+
+		assert.Equal(t, []int64{1, 1}, handler.versions())
+		assert.Equal(t, int64(11), session.committedPosition(), "the held event must be redelivered")
+	})
+
+	t.Run("held_events_are_released_after_the_timeout", func(t *testing.T) {
+		eventConsumer, handler := newSequencedConsumer(t, config.SequencePolicyBuffer, nil, func(cfg *config.ConsumerConfig) {
+			cfg.SequenceBufferTimeout = 20 * time.Millisecond
+		})
+		sessionCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		session := newFakeGroupSession(sessionCtx)
+
+		claim := &fakeGroupClaim{messages: make(chan *sarama.ConsumerMessage, 2)}
+		claim.messages <- newVersionedMessage(1, "order-1", 1)
+		claim.messages <- newVersionedMessage(2, "order-1", 3)
+
+		done := make(chan error)
+		go func() { done <- eventConsumer.ConsumeClaim(session, claim) }()
+
+		assert.Eventually(t, func() bool { return len(handler.handled()) == 2 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, []handledEvent{{1, consumer.SequenceInOrder}, {3, consumer.SequenceGap}}, handler.handled())
+		assert.Eventually(t, func() bool { return session.committedPosition() == 3 }, time.Second, 5*time.Millisecond)
+
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	t.Run("full_buffer_releases_the_oldest_held_event", func(t *testing.T) {
+		eventConsumer, handler := newSequencedConsumer(t, config.SequencePolicyBuffer, nil, func(cfg *config.ConsumerConfig) {
+			cfg.SequenceBufferSize = 1
+		})
+		session := newFakeGroupSession(ctx)
+
+		require.NoError(t, eventConsumer.ConsumeClaim(session, newFakeGroupClaim(
+			newVersionedMessage(1, "order-1", 1),
+			newVersionedMessage(2, "order-1", 3),
+			newVersionedMessage(3, "order-1", 4),
+		)))
+
+		assert.Equal(t, []handledEvent{
+			{1, consumer.SequenceInOrder},
+			{3, consumer.SequenceGap},
+			{4, consumer.SequenceInOrder},
+		}, handler.handled())
+		assert.Equal(t, int64(4), session.committedPosition())
+	})
+
+	t.Run("replayed_events_are_not_checked", func(t *testing.T) {
+		eventConsumer, handler := newSequencedConsumer(t, config.SequencePolicyBuffer, nil, nil)
+		session := newFakeGroupSession(ctx)
+
+		replayed := newVersionedMessage(2, "order-1", 1)
+		replayed.Headers = append(replayed.Headers, &sarama.RecordHeader{Key: []byte(replay.HeaderReplay), Value: []byte("true")})
+
+		require.NoError(t, eventConsumer.ConsumeClaim(session, newFakeGroupClaim(
+			newVersionedMessage(1, "order-1", 1),
+			replayed,
+		)))
+
+		assert.Equal(t, []int64{1, 1}, handler.versions())
+	})
+}
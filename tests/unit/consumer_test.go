@@ -0,0 +1,494 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	saramamocks "github.com/IBM/sarama/mocks"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/application/events"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/consumer"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/correlation"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/kafka"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+)
+
+// fakeGroupSession records the messages and offsets marked by the consumer
+type fakeGroupSession struct {
+	ctx context.Context
+
+	mu       sync.Mutex
+	marked   []int64
+	position int64
+}
+
+func newFakeGroupSession(ctx context.Context) *fakeGroupSession {
+	return &fakeGroupSession{ctx: ctx}
+}
+
+func (s *fakeGroupSession) Claims() map[string][]int32                           { return nil }
+func (s *fakeGroupSession) MemberID() string                                     { return "member-1" }
+func (s *fakeGroupSession) GenerationID() int32                                  { return 1 }
+func (s *fakeGroupSession) MarkOffset(_ string, _ int32, offset int64, _ string) { s.advance(offset) }
+func (s *fakeGroupSession) Commit()                                              {}
+func (s *fakeGroupSession) ResetOffset(string, int32, int64, string)             {}
+func (s *fakeGroupSession) Context() context.Context                             { return s.ctx }
+func (s *fakeGroupSession) MarkMessage(msg *sarama.ConsumerMessage, _ string)    { s.mark(msg.Offset) }
+
+func (s *fakeGroupSession) mark(offset int64) {
+	s.mu.Lock()
+	s.marked = append(s.marked, offset)
+	s.mu.Unlock()
+	s.advance(offset + 1)
+}
+
+// advance moves the position to commit forward, like the sarama offset manager
+func (s *fakeGroupSession) advance(offset int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if offset > s.position {
+		s.position = offset
+	}
+}
+
+// committedPosition returns the next offset to consume after a commit
+func (s *fakeGroupSession) committedPosition() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.position
+}
+
+func (s *fakeGroupSession) markedOffsets() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64(nil), s.marked...)
+}
+
+// fakeGroupClaim delivers the given messages, then closes the channel
+type fakeGroupClaim struct {
+	messages chan *sarama.ConsumerMessage
+}
+
+func newFakeGroupClaim(messages ...*sarama.ConsumerMessage) *fakeGroupClaim {
+	claim := &fakeGroupClaim{messages: make(chan *sarama.ConsumerMessage, len(messages))}
+	for _, message := range messages {
+		claim.messages <- message
+	}
+	close(claim.messages)
+	return claim
+}
+
+func (c *fakeGroupClaim) Topic() string                            { return "txstream.events" }
+func (c *fakeGroupClaim) Partition() int32                         { return 0 }
+func (c *fakeGroupClaim) InitialOffset() int64                     { return 0 }
+func (c *fakeGroupClaim) HighWaterMarkOffset() int64               { return 10 }
+func (c *fakeGroupClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+func consumerTestConfig() config.ConsumerConfig {
+	return config.ConsumerConfig{
+		MaxRetries:        2,
+		RetryBackoff:      time.Millisecond,
+		MaxRetryBackoff:   5 * time.Millisecond,
+		HandlerTimeout:    time.Second,
+		DLQEnabled:        true,
+		DLQTopic:          "txstream.events.dlq",
+		RebalanceStrategy: config.RebalanceStrategySticky,
+		CommitInterval:    time.Second,
+
+		SequencePolicy:        config.SequencePolicyOff,
+		SequenceBufferSize:    100,
+		SequenceBufferTimeout: time.Minute,
+		SequenceMaxAggregates: 1000,
+	}
+}
+
+// newConsumerMessage builds a message in the producer payload format
+func newConsumerMessage(offset int64, eventType string, data interface{}) *sarama.ConsumerMessage {
+	eventID := uuid.New().String()
+	value, _ := json.Marshal(map[string]interface{}{
+		"event_id":       eventID,
+		"aggregate_id":   "order-1",
+		"aggregate_type": "Order",
+		"event_type":     eventType,
+		"event_data":     data,
+		"created_at":     time.Now().Format(time.RFC3339),
+	})
+
+	return &sarama.ConsumerMessage{
+		Topic:     "txstream.events",
+		Partition: 0,
+		Offset:    offset,
+		Key:       []byte("order-1"),
+		Value:     value,
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte(consumer.EventTypeHeader), Value: []byte(eventType)},
+			{Key: []byte(consumer.EventIDHeader), Value: []byte(eventID)},
+		},
+	}
+}
+
+// newVersionedMessage builds a message of an OrderCreated event of the aggregate at version
+func newVersionedMessage(offset int64, aggregateID string, version int64) *sarama.ConsumerMessage {
+	message := newConsumerMessage(offset, "OrderCreated", map[string]interface{}{})
+	message.Key = []byte(aggregateID)
+	message.Value = []byte(fmt.Sprintf(`{"aggregate_id": %q, "event_type": "OrderCreated", "event_data": {}}`, aggregateID))
+	message.Headers = append(message.Headers, &sarama.RecordHeader{
+		Key: []byte(consumer.AggregateVersionHeader), Value: []byte(fmt.Sprint(version)),
+	})
+	return message
+}
+
+func newTestConsumer(t *testing.T, registry *consumer.Registry, dlq sarama.SyncProducer, consumerMetrics *metrics.Metrics) *consumer.Consumer {
+	return newConfiguredConsumer(consumerTestConfig(), registry, dlq, consumerMetrics)
+}
+
+func newConfiguredConsumer(cfg config.ConsumerConfig, registry *consumer.Registry, dlq sarama.SyncProducer, consumerMetrics *metrics.Metrics) *consumer.Consumer {
+	kafkaConfig := &config.KafkaConfig{TopicEvents: "txstream.events", GroupID: "txstream-test"}
+	return consumer.NewConsumerWithClients(kafkaConfig, cfg, registry, nil, dlq, consumerMetrics, nil)
+}
+
+// dlqHeaders returns the headers of a message published to the DLQ
+func dlqHeaders(message *sarama.ProducerMessage) map[string]string {
+	headers := make(map[string]string, len(message.Headers))
+	for _, header := range message.Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+	return headers
+}
+
+func TestConsumerRegistry(t *testing.T) {
+	registry := consumer.NewRegistry()
+	handler := consumer.HandlerFunc(func(context.Context, *consumer.Event) error { return nil })
+
+	require.NoError(t, registry.Register("OrderShipped", handler))
+	require.NoError(t, registry.Register("OrderCreated", handler))
+	assert.ErrorIs(t, registry.Register("OrderCreated", handler), consumer.ErrHandlerAlreadyRegistered)
+
+	_, ok := registry.Handler("OrderCreated")
+	assert.True(t, ok)
+	_, ok = registry.Handler("OrderCancelled")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"OrderCreated", "OrderShipped"}, registry.EventTypes())
+}
+
+func TestConsumerDecodesProducerPayload(t *testing.T) {
+	syncProducer := saramamocks.NewSyncProducer(t, nil)
+	producer := kafka.NewProducerWithSyncProducer(&config.KafkaConfig{TopicEvents: "txstream.events"}, syncProducer, nil, nil)
+
+	transactionID := uuid.New()
+	outboxEvent := &models.OutboxEvent{
+		ID:            uuid.New(),
+		AggregateID:   uuid.New().String(),
+		AggregateType: "Order",
+		EventType:     models.EventTypeOrderCreated,
+		EventData: models.JSON{
+			"order_number": "ORD-001",
+			"customer_id":  "customer-1",
+			"total_amount": 99.9,
+			"currency":     "BRL",
+			"items_count":  2,
+		},
+		EventMetadata:         models.JSON{"source": "txstream-api"},
+		AggregateVersion:      3,
+		CorrelationID:         "checkout-42",
+		CausationID:           "req-123",
+		TransactionID:         &transactionID,
+		TransactionEventCount: 2,
+		CreatedAt:             time.Now().Truncate(time.Second),
+	}
+
+	var published *sarama.ProducerMessage
+	syncProducer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(message *sarama.ProducerMessage) error {
+		published = message
+		return nil
+	})
+	require.NoError(t, producer.PublishEvent(context.Background(), outboxEvent))
+
+	value, err := published.Value.Encode()
+	require.NoError(t, err)
+	message := &sarama.ConsumerMessage{Topic: published.Topic, Offset: 7, Value: value}
+	for i := range published.Headers {
+		message.Headers = append(message.Headers, &published.Headers[i])
+	}
+
+	event, err := consumer.DecodeMessage(message)
+	require.NoError(t, err)
+	assert.Equal(t, outboxEvent.ID.String(), event.ID)
+	assert.Equal(t, outboxEvent.AggregateID, event.AggregateID)
+	assert.Equal(t, models.EventTypeOrderCreated, event.EventType)
+	assert.Equal(t, int64(3), event.AggregateVersion)
+	assert.Equal(t, "checkout-42", event.CorrelationID)
+	assert.Equal(t, "req-123", event.CausationID)
+	assert.Equal(t, transactionID.String(), event.TransactionID)
+	assert.Equal(t, 2, event.TransactionEventCount)
+	assert.True(t, outboxEvent.CreatedAt.Equal(event.CreatedAt))
+	assert.Equal(t, "txstream-api", event.Metadata["source"])
+
+	order, err := consumer.Decode[events.OrderCreated](event)
+	require.NoError(t, err)
+	assert.Equal(t, "ORD-001", order.OrderNumber)
+	assert.Equal(t, 99.9, order.TotalAmount)
+	assert.Equal(t, 2, order.ItemsCount)
+}
+
+func TestConsumerHandling(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("typed_handler_and_unknown_event_type", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		handlers := consumer.NewRegistry()
+
+		var received []string
+		require.NoError(t, handlers.Register("OrderCreated", consumer.Typed(
+			func(ctx context.Context, event *consumer.Event, order events.OrderCreated) error {
+				received = append(received, order.OrderNumber)
+				return nil
+			})))
+
+		eventConsumer := newTestConsumer(t, handlers, nil, metrics.NewMetrics(registry))
+		session := newFakeGroupSession(ctx)
+
+		err := eventConsumer.ConsumeClaim(session, newFakeGroupClaim(
+			newConsumerMessage(1, "OrderCreated", map[string]interface{}{"order_number": "ORD-1"}),
+			newConsumerMessage(2, "OrderShipped", map[string]interface{}{}),
+		))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"ORD-1"}, received)
+		assert.Equal(t, []int64{1, 2}, session.markedOffsets())
+		assert.Equal(t, float64(1), gatheredValue(t, registry, "txstream_consumer_messages_total",
+			map[string]string{"event_type": "OrderCreated", "outcome": consumer.OutcomeHandled}))
+		assert.Equal(t, float64(1), gatheredValue(t, registry, "txstream_consumer_messages_total",
+			map[string]string{"event_type": "OrderShipped", "outcome": consumer.OutcomeSkipped}))
+	})
+
+	t.Run("handler_context_is_caused_by_the_event", func(t *testing.T) {
+		handlers := consumer.NewRegistry()
+
+		var received []correlation.IDs
+		require.NoError(t, handlers.Register("OrderCreated", consumer.HandlerFunc(func(ctx context.Context, event *consumer.Event) error {
+			received = append(received, correlation.FromContext(ctx))
+			return nil
+		})))
+
+		correlated := newConsumerMessage(1, "OrderCreated", map[string]interface{}{})
+		correlated.Headers = append(correlated.Headers, &sarama.RecordHeader{
+			Key: []byte(consumer.CorrelationIDHeader), Value: []byte("checkout-42"),
+		})
+		uncorrelated := newConsumerMessage(2, "OrderCreated", map[string]interface{}{})
+
+		err := newTestConsumer(t, handlers, nil, nil).ConsumeClaim(newFakeGroupSession(ctx), newFakeGroupClaim(correlated, uncorrelated))
+		require.NoError(t, err)
+
+		eventIDs := []string{consumer.MessageHeaders(correlated)[consumer.EventIDHeader], consumer.MessageHeaders(uncorrelated)[consumer.EventIDHeader]}
+		assert.Equal(t, []correlation.IDs{
+			{CorrelationID: "checkout-42", CausationID: eventIDs[0]},
+			{CorrelationID: eventIDs[1], CausationID: eventIDs[1]},
+		}, received, "an event without correlation starts its own")
+	})
+
+	t.Run("transient_failure_is_retried", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		handlers := consumer.NewRegistry()
+
+		var attempts []int
+		require.NoError(t, handlers.Register("OrderCreated", consumer.HandlerFunc(func(ctx context.Context, event *consumer.Event) error {
+			attempts = append(attempts, event.Attempt)
+			if event.Attempt < 3 {
+				return errors.New("downstream unavailable")
+			}
+			return nil
+		})))
+
+		eventConsumer := newTestConsumer(t, handlers, nil, metrics.NewMetrics(registry))
+		session := newFakeGroupSession(ctx)
+
+		require.NoError(t, eventConsumer.ConsumeClaim(session, newFakeGroupClaim(newConsumerMessage(1, "OrderCreated", nil))))
+
+		assert.Equal(t, []int{1, 2, 3}, attempts)
+		assert.Equal(t, []int64{1}, session.markedOffsets())
+		assert.Equal(t, float64(2), gatheredValue(t, registry, "txstream_consumer_retries_total", nil))
+	})
+
+	t.Run("exhausted_retries_go_to_dlq", func(t *testing.T) {
+		handlers := consumer.NewRegistry()
+		require.NoError(t, handlers.Register("OrderCreated", consumer.HandlerFunc(func(context.Context, *consumer.Event) error {
+			return errors.New("downstream unavailable")
+		})))
+
+		dlq := saramamocks.NewSyncProducer(t, nil)
+		var dlqMessage *sarama.ProducerMessage
+		dlq.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(message *sarama.ProducerMessage) error {
+			dlqMessage = message
+			return nil
+		})
+
+		message := newConsumerMessage(4, "OrderCreated", nil)
+		session := newFakeGroupSession(ctx)
+		require.NoError(t, newTestConsumer(t, handlers, dlq, nil).ConsumeClaim(session, newFakeGroupClaim(message)))
+		require.NoError(t, dlq.Close())
+
+		assert.Equal(t, []int64{4}, session.markedOffsets())
+		assert.Equal(t, "txstream.events.dlq", dlqMessage.Topic)
+
+		value, err := dlqMessage.Value.Encode()
+		require.NoError(t, err)
+		assert.Equal(t, message.Value, value)
+
+		headers := dlqHeaders(dlqMessage)
+		assert.Equal(t, "OrderCreated", headers[consumer.EventTypeHeader])
+		assert.Equal(t, "downstream unavailable", headers[consumer.DLQErrorHeader])
+		assert.Equal(t, "3", headers[consumer.DLQAttemptsHeader])
+		assert.Equal(t, "txstream.events", headers[consumer.DLQOriginalTopicHeader])
+		assert.Equal(t, "4", headers[consumer.DLQOriginalOffsetHeader])
+		assert.Equal(t, "txstream-test", headers[consumer.DLQConsumerGroupHeader])
+	})
+
+	t.Run("permanent_failure_skips_retries", func(t *testing.T) {
+		handlers := consumer.NewRegistry()
+		calls := 0
+		require.NoError(t, handlers.Register("OrderCreated", consumer.HandlerFunc(func(context.Context, *consumer.Event) error {
+			calls++
+			return consumer.Permanent(errors.New("unknown customer"))
+		})))
+
+		dlq := saramamocks.NewSyncProducer(t, nil)
+		var headers map[string]string
+		dlq.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(message *sarama.ProducerMessage) error {
+			headers = dlqHeaders(message)
+			return nil
+		})
+
+		session := newFakeGroupSession(ctx)
+		require.NoError(t, newTestConsumer(t, handlers, dlq, nil).ConsumeClaim(session, newFakeGroupClaim(newConsumerMessage(1, "OrderCreated", nil))))
+		require.NoError(t, dlq.Close())
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, "1", headers[consumer.DLQAttemptsHeader])
+		assert.Equal(t, []int64{1}, session.markedOffsets())
+	})
+
+	t.Run("undecodable_event_data_goes_to_dlq", func(t *testing.T) {
+		handlers := consumer.NewRegistry()
+		require.NoError(t, handlers.Register("OrderCreated", consumer.Typed(
+			func(context.Context, *consumer.Event, events.OrderCreated) error { return nil })))
+
+		dlq := saramamocks.NewSyncProducer(t, nil)
+		dlq.ExpectSendMessageAndSucceed()
+
+		message := newConsumerMessage(1, "OrderCreated", "not an object")
+		session := newFakeGroupSession(ctx)
+		require.NoError(t, newTestConsumer(t, handlers, dlq, nil).ConsumeClaim(session, newFakeGroupClaim(message)))
+		require.NoError(t, dlq.Close())
+
+		assert.Equal(t, []int64{1}, session.markedOffsets())
+	})
+
+	t.Run("invalid_payload_goes_to_dlq", func(t *testing.T) {
+		dlq := saramamocks.NewSyncProducer(t, nil)
+		dlq.ExpectSendMessageAndSucceed()
+
+		message := newConsumerMessage(1, "OrderCreated", nil)
+		message.Value = []byte("not json")
+		session := newFakeGroupSession(ctx)
+		require.NoError(t, newTestConsumer(t, consumer.NewRegistry(), dlq, nil).ConsumeClaim(session, newFakeGroupClaim(message)))
+		require.NoError(t, dlq.Close())
+
+		assert.Equal(t, []int64{1}, session.markedOffsets())
+	})
+
+	t.Run("dlq_failure_leaves_message_unmarked", func(t *testing.T) {
+		handlers := consumer.NewRegistry()
+		require.NoError(t, handlers.Register("OrderCreated", consumer.HandlerFunc(func(context.Context, *consumer.Event) error {
+			return consumer.Permanent(errors.New("invalid order"))
+		})))
+
+		dlq := saramamocks.NewSyncProducer(t, nil)
+		dlq.ExpectSendMessageAndFail(sarama.ErrNotEnoughReplicas)
+
+		session := newFakeGroupSession(ctx)
+		err := newTestConsumer(t, handlers, dlq, nil).ConsumeClaim(session, newFakeGroupClaim(
+			newConsumerMessage(1, "OrderCreated", nil),
+			newConsumerMessage(2, "OrderCreated", nil),
+		))
+		require.NoError(t, dlq.Close())
+
+		assert.ErrorIs(t, err, sarama.ErrNotEnoughReplicas)
+		assert.Empty(t, session.markedOffsets(), "the failed message and the ones after it must be redelivered")
+	})
+
+	t.Run("dlq_disabled_discards", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		handlers := consumer.NewRegistry()
+		require.NoError(t, handlers.Register("OrderCreated", consumer.HandlerFunc(func(context.Context, *consumer.Event) error {
+			panic("handler bug")
+		})))
+
+		session := newFakeGroupSession(ctx)
+		require.NoError(t, newTestConsumer(t, handlers, nil, metrics.NewMetrics(registry)).ConsumeClaim(session, newFakeGroupClaim(newConsumerMessage(1, "OrderCreated", nil))))
+
+		assert.Equal(t, []int64{1}, session.markedOffsets())
+		assert.Equal(t, float64(1), gatheredValue(t, registry, "txstream_consumer_messages_total",
+			map[string]string{"outcome": consumer.OutcomeDiscarded}))
+	})
+
+	t.Run("session_end_interrupts_retry", func(t *testing.T) {
+		sessionCtx, cancel := context.WithCancel(ctx)
+		handlers := consumer.NewRegistry()
+
+		var handlerCtxErr error
+		require.NoError(t, handlers.Register("OrderCreated", consumer.HandlerFunc(func(handlerCtx context.Context, event *consumer.Event) error {
+			cancel()
+			handlerCtxErr = handlerCtx.Err()
+			return fmt.Errorf("attempt %d failed", event.Attempt)
+		})))
+
+		session := newFakeGroupSession(sessionCtx)
+		require.NoError(t, newTestConsumer(t, handlers, nil, nil).ConsumeClaim(session, newFakeGroupClaim(newConsumerMessage(1, "OrderCreated", nil))))
+
+		assert.NoError(t, handlerCtxErr, "the end of the session must not cancel the handler in progress")
+		assert.Empty(t, session.markedOffsets(), "the event must be redelivered to the next owner of the partition")
+	})
+}
+
+func TestConsumerConfigValidation(t *testing.T) {
+	valid := consumerTestConfig()
+	assert.NoError(t, valid.Validate())
+
+	invalidStrategy := valid
+	invalidStrategy.RebalanceStrategy = "cooperative"
+	assert.Error(t, invalidStrategy.Validate())
+
+	missingDLQTopic := valid
+	missingDLQTopic.DLQTopic = ""
+	assert.Error(t, missingDLQTopic.Validate())
+
+	dlqDisabled := missingDLQTopic
+	dlqDisabled.DLQEnabled = false
+	assert.NoError(t, dlqDisabled.Validate())
+
+	invalidBackoff := valid
+	invalidBackoff.MaxRetryBackoff = 0
+	assert.Error(t, invalidBackoff.Validate())
+
+	invalidSequencePolicy := valid
+	invalidSequencePolicy.SequencePolicy = "reorder"
+	assert.Error(t, invalidSequencePolicy.Validate())
+
+	unboundedBuffer := valid
+	unboundedBuffer.SequencePolicy = config.SequencePolicyBuffer
+	unboundedBuffer.SequenceBufferTimeout = 0
+	assert.Error(t, unboundedBuffer.Validate())
+}
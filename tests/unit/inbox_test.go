@@ -0,0 +1,204 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/lorenaziviani/txstream/internal/application/events"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/consumer"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/inbox"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+)
+
+// stubInboxRepository deletes the given number of entries, then of held group events, on
+// each call
+type stubInboxRepository struct {
+	deletes      []int64
+	groupDeletes []int64
+	err          error
+	cutoffs      []time.Time
+	limits       []int
+	groupLimits  []int
+}
+
+func (r *stubInboxRepository) Record(context.Context, *models.InboxEntry) (bool, error) {
+	return true, nil
+}
+
+func (r *stubInboxRepository) Exists(context.Context, string, string) (bool, error) {
+	return false, nil
+}
+
+func (r *stubInboxRepository) DeleteProcessedBefore(_ context.Context, cutoff time.Time, limit int) (int64, error) {
+	r.cutoffs = append(r.cutoffs, cutoff)
+	r.limits = append(r.limits, limit)
+	if r.err != nil {
+		return 0, r.err
+	}
+	if len(r.deletes) == 0 {
+		return 0, nil
+	}
+	deleted := r.deletes[0]
+	r.deletes = r.deletes[1:]
+	return deleted, nil
+}
+
+func (r *stubInboxRepository) LockGroup(context.Context, string, string) error {
+	return nil
+}
+
+func (r *stubInboxRepository) AddGroupEvent(context.Context, *models.InboxGroupEvent) error {
+	return nil
+}
+
+func (r *stubInboxRepository) ListGroupEvents(context.Context, string, string) ([]models.InboxGroupEvent, error) {
+	return nil, nil
+}
+
+func (r *stubInboxRepository) ListExpiredGroups(context.Context, string, time.Time, int) ([]string, error) {
+	return nil, nil
+}
+
+func (r *stubInboxRepository) DeleteGroup(context.Context, string, string) error {
+	return nil
+}
+
+func (r *stubInboxRepository) DeleteGroupEventsReceivedBefore(_ context.Context, _ time.Time, limit int) (int64, error) {
+	r.groupLimits = append(r.groupLimits, limit)
+	if len(r.groupDeletes) == 0 {
+		return 0, nil
+	}
+	deleted := r.groupDeletes[0]
+	r.groupDeletes = r.groupDeletes[1:]
+	return deleted, nil
+}
+
+func inboxTestConfig() config.InboxConfig {
+	return config.InboxConfig{
+		Enabled:      true,
+		Retention:    24 * time.Hour,
+		Interval:     time.Hour,
+		BatchSize:    2,
+		GroupTimeout: 5 * time.Minute,
+	}
+}
+
+func TestInboxRetentionJob(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deletes_in_batches_until_a_partial_batch", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		repo := &stubInboxRepository{deletes: []int64{2, 2, 1}}
+		job := inbox.NewRetentionJob(inboxTestConfig(), repo, metrics.NewMetrics(registry), nil)
+
+		purged, err := job.Run(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(5), purged)
+		assert.Equal(t, []int{2, 2, 2}, repo.limits)
+		assert.WithinDuration(t, time.Now().Add(-24*time.Hour), repo.cutoffs[0], time.Minute)
+		assert.Equal(t, float64(5), gatheredValue(t, registry, "txstream_inbox_rows_purged_total", nil))
+	})
+
+	t.Run("deletes_events_of_incomplete_transactions", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		repo := &stubInboxRepository{groupDeletes: []int64{2, 1}}
+		job := inbox.NewRetentionJob(inboxTestConfig(), repo, metrics.NewMetrics(registry), nil)
+
+		purged, err := job.Run(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(0), purged)
+		assert.Equal(t, []int{2, 2}, repo.groupLimits)
+		assert.Equal(t, float64(3), gatheredValue(t, registry, "txstream_inbox_group_events_abandoned_total", nil))
+	})
+
+	t.Run("returns_repository_errors", func(t *testing.T) {
+		repo := &stubInboxRepository{err: errors.New("connection refused")}
+		job := inbox.NewRetentionJob(inboxTestConfig(), repo, nil, nil)
+
+		_, err := job.Run(ctx)
+		assert.ErrorIs(t, err, repo.err)
+	})
+}
+
+func TestInboxRejectsUndeduplicableEvents(t *testing.T) {
+	eventInbox := inbox.NewInbox(nil, "txstream-test", nil, nil)
+	called := false
+	handle := func(context.Context, *gorm.DB, *consumer.Event) error {
+		called = true
+		return nil
+	}
+
+	t.Run("missing_event_id_is_permanent", func(t *testing.T) {
+		processed, err := eventInbox.Process(context.Background(), &consumer.Event{EventType: "OrderCreated"}, handle)
+
+		assert.False(t, processed)
+		assert.ErrorIs(t, err, inbox.ErrMissingEventID)
+		assert.True(t, consumer.IsPermanent(err))
+		assert.False(t, called)
+	})
+
+	t.Run("grouped_event_without_id_is_permanent", func(t *testing.T) {
+		processed, err := eventInbox.Collect(context.Background(), &consumer.Event{
+			EventType:             "OrderCreated",
+			TransactionID:         "transaction-1",
+			TransactionEventCount: 2,
+		}, func(context.Context, *gorm.DB, []*consumer.Event) error {
+			called = true
+			return nil
+		})
+
+		assert.False(t, processed)
+		assert.ErrorIs(t, err, inbox.ErrMissingEventID)
+		assert.True(t, consumer.IsPermanent(err))
+		assert.False(t, called)
+	})
+
+	t.Run("undecodable_event_data_is_permanent", func(t *testing.T) {
+		handler := inbox.Typed(eventInbox, func(context.Context, *gorm.DB, *consumer.Event, events.OrderCreated) error {
+			called = true
+			return nil
+		})
+
+		err := handler.Handle(context.Background(), &consumer.Event{
+			ID:        "event-1",
+			EventType: "OrderCreated",
+			Data:      json.RawMessage(`"not an object"`),
+		})
+
+		assert.True(t, consumer.IsPermanent(err))
+		assert.False(t, called)
+	})
+}
+
+func TestInboxConfigValidation(t *testing.T) {
+	valid := inboxTestConfig()
+	assert.NoError(t, valid.Validate())
+
+	invalidRetention := valid
+	invalidRetention.Retention = 0
+	assert.Error(t, invalidRetention.Validate())
+
+	invalidBatchSize := valid
+	invalidBatchSize.BatchSize = 0
+	assert.Error(t, invalidBatchSize.Validate())
+
+	invalidGroupTimeout := valid
+	invalidGroupTimeout.GroupTimeout = valid.Retention
+	assert.Error(t, invalidGroupTimeout.Validate(), "groups must be handed over before the retention deletes them")
+
+	disabled := invalidRetention
+	disabled.Enabled = false
+	assert.NoError(t, disabled.Validate())
+}
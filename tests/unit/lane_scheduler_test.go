@@ -0,0 +1,120 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/worker"
+)
+
+func TestLaneSchedulerQuotas(t *testing.T) {
+	weights := map[models.OutboxPriority]int{
+		models.OutboxPriorityHigh:   6,
+		models.OutboxPriorityNormal: 3,
+		models.OutboxPriorityLow:    1,
+	}
+
+	t.Run("quotas_follow_weights", func(t *testing.T) {
+		scheduler := worker.NewLaneScheduler(weights)
+		quotas := scheduler.Quotas(10)
+
+		assert.Equal(t, 6, quotas[models.OutboxPriorityHigh])
+		assert.Equal(t, 3, quotas[models.OutboxPriorityNormal])
+		assert.Equal(t, 1, quotas[models.OutboxPriorityLow])
+	})
+
+	t.Run("low_lane_is_never_starved", func(t *testing.T) {
+		scheduler := worker.NewLaneScheduler(map[models.OutboxPriority]int{
+			models.OutboxPriorityHigh:   100,
+			models.OutboxPriorityNormal: 10,
+			models.OutboxPriorityLow:    1,
+		})
+		quotas := scheduler.Quotas(5)
+
+		assert.GreaterOrEqual(t, quotas[models.OutboxPriorityLow], 1)
+		assert.GreaterOrEqual(t, quotas[models.OutboxPriorityNormal], 1)
+		assert.Equal(t, 5, quotas[models.OutboxPriorityHigh]+quotas[models.OutboxPriorityNormal]+quotas[models.OutboxPriorityLow])
+	})
+
+	t.Run("quotas_sum_to_batch_size", func(t *testing.T) {
+		scheduler := worker.NewLaneScheduler(weights)
+		for batchSize := 1; batchSize <= 50; batchSize++ {
+			quotas := scheduler.Quotas(batchSize)
+			total := 0
+			for _, quota := range quotas {
+				total += quota
+			}
+			assert.Equal(t, batchSize, total, "batch size %d", batchSize)
+		}
+	})
+}
+
+func TestLaneSchedulerInterleave(t *testing.T) {
+	newEvents := func(count int, priority models.OutboxPriority) []models.OutboxEvent {
+		events := make([]models.OutboxEvent, count)
+		for i := range events {
+			events[i] = models.OutboxEvent{ID: uuid.New(), Priority: priority}
+		}
+		return events
+	}
+
+	scheduler := worker.NewLaneScheduler(map[models.OutboxPriority]int{
+		models.OutboxPriorityHigh:   2,
+		models.OutboxPriorityNormal: 1,
+		models.OutboxPriorityLow:    1,
+	})
+
+	high := newEvents(4, models.OutboxPriorityHigh)
+	low := newEvents(2, models.OutboxPriorityLow)
+
+	ordered := scheduler.Interleave(map[models.OutboxPriority][]models.OutboxEvent{
+		models.OutboxPriorityHigh: high,
+		models.OutboxPriorityLow:  low,
+	})
+
+	require.Len(t, ordered, 6)
+	assert.Equal(t, models.OutboxPriorityHigh, ordered[0].Priority)
+	assert.Contains(t, []models.OutboxPriority{ordered[1].Priority, ordered[2].Priority}, models.OutboxPriorityLow,
+		"low lane should be served within the first weighted round")
+
+	var highOrder []models.OutboxEvent
+	for _, event := range ordered {
+		if event.Priority == models.OutboxPriorityHigh {
+			highOrder = append(highOrder, event)
+		}
+	}
+	assert.Equal(t, high, highOrder, "order within a lane should be preserved")
+}
+
+func TestWorkerPriorityConfig(t *testing.T) {
+	workerConfig := &config.WorkerConfig{
+		PoolSize:        1,
+		BatchSize:       10,
+		Interval:        1,
+		LeaseDuration:   1,
+		EventPriorities: "OrderCancelled=high,OrderSnapshot=low",
+		PriorityWeights: "high=8,low=2",
+	}
+
+	require.NoError(t, workerConfig.Validate())
+	assert.Equal(t, config.PriorityLaneHigh, workerConfig.GetEventPriority("OrderCancelled"))
+	assert.Equal(t, config.PriorityLaneLow, workerConfig.GetEventPriority("OrderSnapshot"))
+	assert.Equal(t, config.PriorityLaneNormal, workerConfig.GetEventPriority("OrderCreated"))
+
+	weights := worker.PriorityWeights(workerConfig.GetPriorityWeights())
+	assert.Equal(t, 8, weights[models.OutboxPriorityHigh])
+	assert.Equal(t, 1, weights[models.OutboxPriorityNormal])
+	assert.Equal(t, 2, weights[models.OutboxPriorityLow])
+
+	workerConfig.EventPriorities = "OrderCancelled=urgent"
+	assert.Error(t, workerConfig.Validate())
+
+	workerConfig.EventPriorities = ""
+	workerConfig.PriorityWeights = "high=0"
+	assert.Error(t, workerConfig.Validate())
+}
@@ -0,0 +1,226 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/correlation"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/handlers"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+)
+
+func loggingTestConfig() config.LoggingConfig {
+	return config.LoggingConfig{
+		Level:     "info",
+		Format:    "json",
+		MaxSizeMB: 100,
+	}
+}
+
+// decodeLogLines decodes the JSON records written to buffer
+func decodeLogLines(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLoggerLevelAndFormat(t *testing.T) {
+	t.Run("json_records_above_level", func(t *testing.T) {
+		var buffer bytes.Buffer
+		cfg := loggingTestConfig()
+		cfg.Level = "warn"
+
+		logger, err := logging.NewWithWriter(&buffer, cfg)
+		require.NoError(t, err)
+
+		logger.Info("ignored")
+		logger.Warn("kept", "attempt", 2)
+
+		records := decodeLogLines(t, &buffer)
+		require.Len(t, records, 1)
+		assert.Equal(t, "kept", records[0]["msg"])
+		assert.Equal(t, "WARN", records[0]["level"])
+		assert.Equal(t, float64(2), records[0]["attempt"])
+	})
+
+	t.Run("text_format", func(t *testing.T) {
+		var buffer bytes.Buffer
+		cfg := loggingTestConfig()
+		cfg.Format = "text"
+
+		logger, err := logging.NewWithWriter(&buffer, cfg)
+		require.NoError(t, err)
+
+		logger.Info("started", "worker_id", "w-1")
+		assert.Contains(t, buffer.String(), "msg=started worker_id=w-1")
+	})
+
+	t.Run("fatal_level", func(t *testing.T) {
+		var buffer bytes.Buffer
+		cfg := loggingTestConfig()
+		cfg.Level = "fatal"
+
+		logger, err := logging.NewWithWriter(&buffer, cfg)
+		require.NoError(t, err)
+
+		logger.Error("ignored")
+		logger.Log(context.Background(), logging.LevelFatal, "exiting")
+
+		records := decodeLogLines(t, &buffer)
+		require.Len(t, records, 1)
+		assert.Equal(t, "FATAL", records[0]["level"])
+	})
+
+	t.Run("invalid_level", func(t *testing.T) {
+		cfg := loggingTestConfig()
+		cfg.Level = "verbose"
+
+		_, err := logging.NewWithWriter(&bytes.Buffer{}, cfg)
+		assert.Error(t, err)
+	})
+}
+
+func TestLoggerContextFields(t *testing.T) {
+	var buffer bytes.Buffer
+	logger, err := logging.NewWithWriter(&buffer, loggingTestConfig())
+	require.NoError(t, err)
+
+	ctx := logging.WithAttrs(context.Background(),
+		slog.String(logging.RequestIDKey, "req-1"),
+		slog.String(logging.EventIDKey, "event-1"),
+	)
+	ctx = logging.WithAttrs(ctx, slog.String(logging.EventIDKey, "event-2"))
+
+	logger.With(logging.WorkerIDKey, "worker-1").InfoContext(ctx, "published")
+	logger.Info("no context")
+
+	records := decodeLogLines(t, &buffer)
+	require.Len(t, records, 2)
+	assert.Equal(t, "req-1", records[0][logging.RequestIDKey])
+	assert.Equal(t, "event-2", records[0][logging.EventIDKey])
+	assert.Equal(t, "worker-1", records[0][logging.WorkerIDKey])
+	assert.NotContains(t, records[1], logging.RequestIDKey)
+}
+
+func TestLoggerFileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "txstream.log")
+	cfg := loggingTestConfig()
+	cfg.OutputPath = path
+
+	logger, closer, err := logging.New(cfg)
+	require.NoError(t, err)
+
+	logger.Info("written to file")
+	require.NoError(t, closer.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"msg":"written to file"`)
+}
+
+func TestLoggingConfigValidation(t *testing.T) {
+	cfg := loggingTestConfig()
+	assert.NoError(t, cfg.Validate())
+
+	invalidSize := cfg
+	invalidSize.MaxSizeMB = 0
+	assert.Error(t, invalidSize.Validate())
+
+	invalidBackups := cfg
+	invalidBackups.MaxBackups = -1
+	assert.Error(t, invalidBackups.Validate())
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var buffer bytes.Buffer
+	logger, err := logging.NewWithWriter(&buffer, loggingTestConfig())
+	require.NoError(t, err)
+
+	var (
+		requestID string
+		ids       correlation.IDs
+	)
+	handler := handlers.RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = handlers.RequestIDFromContext(r.Context())
+		ids = correlation.FromContext(r.Context())
+		logger.InfoContext(r.Context(), "handled")
+	}))
+
+	t.Run("propagates_request_id", func(t *testing.T) {
+		buffer.Reset()
+		request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		request.Header.Set(handlers.RequestIDHeader, "req-123")
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, "req-123", requestID)
+		assert.Equal(t, "req-123", recorder.Header().Get(handlers.RequestIDHeader))
+		assert.Equal(t, "req-123", decodeLogLines(t, &buffer)[0][logging.RequestIDKey])
+	})
+
+	t.Run("generates_request_id", func(t *testing.T) {
+		buffer.Reset()
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+		assert.NotEmpty(t, requestID)
+		assert.Equal(t, requestID, recorder.Header().Get(handlers.RequestIDHeader))
+	})
+
+	t.Run("request_id_correlates_by_default", func(t *testing.T) {
+		buffer.Reset()
+		request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		request.Header.Set(handlers.RequestIDHeader, "req-123")
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, correlation.IDs{CorrelationID: "req-123", CausationID: "req-123"}, ids)
+		assert.Equal(t, "req-123", recorder.Header().Get(handlers.CorrelationIDHeader))
+		assert.Equal(t, "req-123", decodeLogLines(t, &buffer)[0][logging.CorrelationIDKey])
+	})
+
+	t.Run("propagates_correlation_id", func(t *testing.T) {
+		buffer.Reset()
+		request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		request.Header.Set(handlers.RequestIDHeader, "req-123")
+		request.Header.Set(handlers.CorrelationIDHeader, "checkout-42")
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, correlation.IDs{CorrelationID: "checkout-42", CausationID: "req-123"}, ids)
+		assert.Equal(t, "checkout-42", recorder.Header().Get(handlers.CorrelationIDHeader))
+		assert.Equal(t, "checkout-42", decodeLogLines(t, &buffer)[0][logging.CorrelationIDKey])
+	})
+
+	t.Run("ignores_oversized_ids", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		request.Header.Set(handlers.CorrelationIDHeader, strings.Repeat("x", 200))
+
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+
+		assert.Equal(t, requestID, ids.CorrelationID)
+	})
+}
@@ -6,17 +6,19 @@ import (
 	"testing"
 	"time"
 
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
 	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMetricsCreation(t *testing.T) {
-	metrics := metrics.NewMetrics()
+	metrics := metrics.NewMetrics(nil)
 	assert.NotNil(t, metrics, "Metrics should not be nil")
 }
 
 func TestEventProcessingMetrics(t *testing.T) {
-	metrics := metrics.NewMetrics()
+	metrics := metrics.NewMetrics(nil)
 
 	metrics.RecordEventProcessed("published", "order_created")
 	metrics.RecordEventProcessed("failed", "order_created")
@@ -38,7 +40,7 @@ func TestEventProcessingMetrics(t *testing.T) {
 }
 
 func TestDurationMetrics(t *testing.T) {
-	metrics := metrics.NewMetrics()
+	metrics := metrics.NewMetrics(nil)
 
 	processingDuration := 150 * time.Millisecond
 	metrics.RecordEventProcessingDuration("order_created", processingDuration)
@@ -55,7 +57,7 @@ func TestDurationMetrics(t *testing.T) {
 }
 
 func TestGaugeMetrics(t *testing.T) {
-	metrics := metrics.NewMetrics()
+	metrics := metrics.NewMetrics(nil)
 
 	metrics.SetWorkerPoolSize(5)
 	metrics.SetWorkerPoolSize(10)
@@ -73,7 +75,7 @@ func TestGaugeMetrics(t *testing.T) {
 }
 
 func TestTimerHelper(t *testing.T) {
-	metrics := metrics.NewMetrics()
+	metrics := metrics.NewMetrics(nil)
 
 	timer := metrics.Timer()
 	assert.NotNil(t, timer, "Timer should not be nil")
@@ -85,16 +87,70 @@ func TestTimerHelper(t *testing.T) {
 }
 
 func TestMetricsServer(t *testing.T) {
-	metrics := metrics.NewMetrics()
+	registry := metrics.NewRegistry()
+	workerMetrics := metrics.NewMetrics(registry)
+	workerMetrics.RecordEventPublished("txstream.events", "order_created")
+
+	get := func(t *testing.T, cfg config.MetricsConfig, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		metrics.NewServer(cfg, registry).Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	cfg := config.MetricsConfig{Enabled: true, Host: "127.0.0.1", Port: 9091, Path: "/metrics"}
+
+	t.Run("exposes_shared_registry_and_runtime_collectors", func(t *testing.T) {
+		recorder := get(t, cfg, "/metrics")
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		body := recorder.Body.String()
+		assert.Contains(t, body, `txstream_events_published_total{event_type="order_created",topic="txstream.events"} 1`)
+		assert.Contains(t, body, "go_goroutines")
+		assert.Contains(t, body, "promhttp_metric_handler_requests_total")
+	})
+
+	t.Run("pprof_disabled", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get(t, cfg, "/debug/pprof/").Code)
+	})
+
+	t.Run("pprof_enabled", func(t *testing.T) {
+		pprofCfg := cfg
+		pprofCfg.PprofEnabled = true
+		assert.Equal(t, http.StatusOK, get(t, pprofCfg, "/debug/pprof/").Code)
+	})
+
+	t.Run("server_address", func(t *testing.T) {
+		assert.Equal(t, "127.0.0.1:9091", metrics.NewServer(cfg, registry).Addr)
+	})
+}
+
+func TestMetricsSharedRegistry(t *testing.T) {
+	registry := metrics.NewRegistry()
+	metrics.NewMetrics(registry)
+
+	assert.Panics(t, func() { metrics.NewMetrics(registry) }, "metrics must be registered once per registry")
+	assert.NotPanics(t, func() { metrics.NewMetrics(nil) })
+}
+
+func TestMetricsConfigValidation(t *testing.T) {
+	valid := config.MetricsConfig{Enabled: true, Port: 9091, Path: "/metrics"}
+	assert.NoError(t, valid.Validate())
+
+	invalidPort := valid
+	invalidPort.Port = 0
+	assert.Error(t, invalidPort.Validate())
 
-	err := metrics.StartMetricsServer(0, "/metrics")
-	assert.NoError(t, err, "Should start metrics server without error")
+	invalidPath := valid
+	invalidPath.Path = "metrics"
+	assert.Error(t, invalidPath.Validate())
 
-	time.Sleep(100 * time.Millisecond)
+	disabled := invalidPort
+	disabled.Enabled = false
+	assert.NoError(t, disabled.Validate())
 }
 
 func TestMetricsEndpoint(t *testing.T) {
-	metrics := metrics.NewMetrics()
+	metrics := metrics.NewMetrics(nil)
 
 	metrics.RecordEventProcessed("published", "order_created")
 	metrics.RecordEventPublished("txstream.events", "order_created")
@@ -117,7 +173,7 @@ func TestMetricsEndpoint(t *testing.T) {
 }
 
 func TestMetricsConfiguration(t *testing.T) {
-	metrics := metrics.NewMetrics()
+	metrics := metrics.NewMetrics(nil)
 
 	assert.NotNil(t, metrics, "Metrics should be created successfully")
 
@@ -0,0 +1,276 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	saramamocks "github.com/IBM/sarama/mocks"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/application/usecases"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/handlers"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/kafka"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+)
+
+// reconcileOrderRepository joins the memory orders with the memory outbox
+type reconcileOrderRepository struct {
+	*memoryOrderRepository
+	outbox *memoryOutboxRepository
+}
+
+func (r *reconcileOrderRepository) ListWithoutEvent(ctx context.Context, eventType string, createdAfter, createdBefore time.Time, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	for _, order := range r.orders {
+		if order.CreatedAt.Before(createdAfter) || !order.CreatedAt.Before(createdBefore) || r.hasEvent(order.ID.String(), eventType) {
+			continue
+		}
+		orders = append(orders, order)
+	}
+	if len(orders) > limit {
+		orders = orders[:limit]
+	}
+	return orders, nil
+}
+
+func (r *reconcileOrderRepository) CountWithoutEvent(ctx context.Context, eventType string, createdAfter, createdBefore time.Time) (int64, error) {
+	orders, err := r.ListWithoutEvent(ctx, eventType, createdAfter, createdBefore, len(r.orders))
+	return int64(len(orders)), err
+}
+
+func (r *reconcileOrderRepository) hasEvent(aggregateID, eventType string) bool {
+	for _, event := range r.outbox.events {
+		if event.AggregateID == aggregateID && event.EventType == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// stubDeliveryVerifier returns the configured status of each event, found by default, and
+// records the offsets it verified with the event ID expected there
+type stubDeliveryVerifier struct {
+	statuses map[uuid.UUID]kafka.DeliveryStatus
+	verified map[int64]uuid.UUID
+}
+
+func (v *stubDeliveryVerifier) Verify(ctx context.Context, eventID uuid.UUID, receipt kafka.DeliveryReceipt) (kafka.DeliveryStatus, error) {
+	if v.verified != nil {
+		v.verified[receipt.Offset] = eventID
+	}
+	if status, ok := v.statuses[eventID]; ok {
+		return status, nil
+	}
+	return kafka.DeliveryFound, nil
+}
+
+func reconcileTestConfig() config.ReconcileConfig {
+	return config.ReconcileConfig{
+		Interval:    time.Minute,
+		Lookback:    24 * time.Hour,
+		Grace:       5 * time.Minute,
+		VerifyKafka: true,
+		VerifyLimit: 100,
+		ReportLimit: 100,
+	}
+}
+
+// deliver marks an outbox event as published an hour ago with a delivery receipt
+func deliver(event *models.OutboxEvent, offset int64) {
+	publishedAt := time.Now().Add(-time.Hour)
+	partition := int32(0)
+	event.Status = models.OutboxStatusPublished
+	event.PublishedAt = &publishedAt
+	event.KafkaTopic = "txstream.events"
+	event.KafkaPartition = &partition
+	event.KafkaOffset = &offset
+}
+
+func TestOrderReconcilerRepairsOrdersWithoutEvent(t *testing.T) {
+	ctx := context.Background()
+
+	outboxRepo := newMemoryOutboxRepository()
+	orders := newMemoryOrderRepository(4)
+	for i := range orders.orders {
+		orders.orders[i].CreatedAt = time.Now().Add(-time.Hour)
+	}
+	orders.orders[3].CreatedAt = time.Now().Add(-time.Minute)
+	outboxRepo.events = append(outboxRepo.events, models.OutboxEvent{
+		ID:            uuid.New(),
+		AggregateID:   orders.orders[0].ID.String(),
+		AggregateType: "Order",
+		EventType:     "OrderCreated",
+		Status:        models.OutboxStatusPublished,
+	})
+
+	orderRepo := &reconcileOrderRepository{memoryOrderRepository: orders, outbox: outboxRepo}
+	reconciler := usecases.NewOrderReconciler(reconcileTestConfig(), orderRepo, outboxRepo, nil, nil, nil, nil)
+	assert.Nil(t, reconciler.LastReport())
+
+	report, err := reconciler.Reconcile(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Counts[usecases.DiscrepancyMissingEvent], "orders within the grace period are not checked")
+	require.Len(t, report.Discrepancies, 2)
+	assert.Equal(t, orders.orders[1].ID.String(), report.Discrepancies[0].AggregateID)
+	assert.Nil(t, report.Discrepancies[0].RepairEventID)
+	assert.Len(t, outboxRepo.events, 1, "nothing is enqueued without repair")
+	assert.Same(t, report, reconciler.LastReport())
+
+	report, err = reconciler.Reconcile(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Repaired)
+	require.Len(t, outboxRepo.events, 3)
+
+	compensating := outboxRepo.events[1]
+	assert.Equal(t, *report.Discrepancies[0].RepairEventID, compensating.ID)
+	assert.Equal(t, "OrderCreated", compensating.EventType)
+	assert.Equal(t, orders.orders[1].ID.String(), compensating.AggregateID)
+	assert.Equal(t, models.OutboxStatusPending, compensating.Status)
+	assert.Equal(t, "txstream-reconciler", compensating.EventMetadata["source"])
+
+	report, err = reconciler.Reconcile(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Total())
+	assert.Len(t, outboxRepo.events, 3)
+}
+
+func TestOrderReconcilerVerifiesDeliveryReceipts(t *testing.T) {
+	ctx := context.Background()
+
+	outboxRepo := newMemoryOutboxRepository(
+		models.OutboxStatusPending, models.OutboxStatusPending, models.OutboxStatusPending,
+		models.OutboxStatusPending, models.OutboxStatusPublished,
+	)
+	for i := 0; i < 4; i++ {
+		deliver(&outboxRepo.events[i], int64(i))
+	}
+	outboxRepo.events[1].AggregateVersion = 3
+	outboxRepo.events[1].CorrelationID = "request-1"
+	outboxRepo.events[1].CausationID = "command-1"
+
+	verifier := &stubDeliveryVerifier{statuses: map[uuid.UUID]kafka.DeliveryStatus{
+		outboxRepo.events[1].ID: kafka.DeliveryMissing,
+		outboxRepo.events[2].ID: kafka.DeliveryMismatch,
+		outboxRepo.events[3].ID: kafka.DeliveryExpired,
+	}}
+	orderRepo := &reconcileOrderRepository{memoryOrderRepository: newMemoryOrderRepository(0), outbox: outboxRepo}
+	reconciler := usecases.NewOrderReconciler(reconcileTestConfig(), orderRepo, outboxRepo, verifier, nil, nil, nil)
+
+	report, err := reconciler.Reconcile(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.EventsVerified, "the published event without receipt is not verified")
+	assert.Equal(t, 1, report.EventsExpired)
+	assert.Equal(t, 1, report.Counts[usecases.DiscrepancyMissingInKafka])
+	assert.Equal(t, 1, report.Counts[usecases.DiscrepancyOffsetMismatch])
+	assert.Equal(t, 2, report.Repaired)
+	require.Len(t, report.Discrepancies, 2)
+	assert.Equal(t, outboxRepo.events[1].ID, *report.Discrepancies[0].EventID)
+
+	require.Len(t, outboxRepo.events, 7)
+	redelivery := outboxRepo.events[5]
+	assert.Equal(t, *report.Discrepancies[0].RepairEventID, redelivery.ID)
+	assert.Equal(t, outboxRepo.events[1].AggregateID, redelivery.AggregateID)
+	assert.Equal(t, models.OutboxStatusPending, redelivery.Status)
+	assert.Equal(t, outboxRepo.events[1].ID.String(), redelivery.EventMetadata["compensates_event_id"])
+	assert.Equal(t, outboxRepo.events[1].AggregateVersion, redelivery.AggregateVersion)
+	assert.Equal(t, outboxRepo.events[1].CorrelationID, redelivery.CorrelationID)
+	assert.Equal(t, outboxRepo.events[1].CausationID, redelivery.CausationID)
+	assert.NotEqual(t, outboxRepo.events[1].ID, redelivery.ID, "the copy has its own outbox row")
+	assert.Equal(t, outboxRepo.events[1].ID, redelivery.MessageID(), "the copy is published as the original")
+
+	report, err = reconciler.Reconcile(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Total(), "the discrepancies stay reported while in the window")
+	assert.Equal(t, 0, report.Repaired, "a discrepancy is never repaired twice")
+	assert.Len(t, outboxRepo.events, 7)
+}
+
+func TestRedeliveryPublishedWithOriginalEventID(t *testing.T) {
+	outboxRepo := newMemoryOutboxRepository(models.OutboxStatusPending)
+	deliver(&outboxRepo.events[0], 0)
+	original := outboxRepo.events[0]
+
+	verifier := &stubDeliveryVerifier{statuses: map[uuid.UUID]kafka.DeliveryStatus{original.ID: kafka.DeliveryMissing}}
+	orderRepo := &reconcileOrderRepository{memoryOrderRepository: newMemoryOrderRepository(0), outbox: outboxRepo}
+	reconciler := usecases.NewOrderReconciler(reconcileTestConfig(), orderRepo, outboxRepo, verifier, nil, nil, nil)
+
+	_, err := reconciler.Reconcile(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, outboxRepo.events, 2)
+	redelivery := outboxRepo.events[1]
+
+	syncProducer := saramamocks.NewSyncProducer(t, nil)
+	producer := kafka.NewProducerWithSyncProducer(&config.KafkaConfig{TopicEvents: "txstream.events"}, syncProducer, nil, nil)
+
+	syncProducer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(message *sarama.ProducerMessage) error {
+		for _, header := range message.Headers {
+			if string(header.Key) == "event_id" && string(header.Value) != original.ID.String() {
+				return fmt.Errorf("unexpected event_id header %s, expected %s", header.Value, original.ID)
+			}
+		}
+
+		value, err := message.Value.Encode()
+		if err != nil {
+			return err
+		}
+		var payload map[string]interface{}
+		if err := json.Unmarshal(value, &payload); err != nil {
+			return err
+		}
+		if payload["event_id"] != original.ID.String() {
+			return fmt.Errorf("unexpected payload event_id %v, expected %s", payload["event_id"], original.ID)
+		}
+		return nil
+	})
+
+	require.NoError(t, producer.PublishEvent(context.Background(), &redelivery))
+	require.NoError(t, syncProducer.Close())
+
+	// Once delivered, the copy is verified against the event ID of its message
+	deliver(&outboxRepo.events[1], 1)
+	verifier.statuses = nil
+	verifier.verified = map[int64]uuid.UUID{}
+
+	report, err := reconciler.Reconcile(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Total())
+	assert.Equal(t, original.ID, verifier.verified[1])
+}
+
+func TestReconciliationHandler(t *testing.T) {
+	outboxRepo := newMemoryOutboxRepository()
+	orderRepo := &reconcileOrderRepository{memoryOrderRepository: newMemoryOrderRepository(0), outbox: outboxRepo}
+	reconciler := usecases.NewOrderReconciler(reconcileTestConfig(), orderRepo, outboxRepo, nil, nil, nil, nil)
+
+	router := mux.NewRouter()
+	handlers.NewReconciliationHandler(reconciler).RegisterRoutes(router)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reconciliation", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/reconciliation", strings.NewReader(`{"repair": true}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"repair":true`)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/reconciliation", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "the body is optional")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reconciliation", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"repair":false`)
+}
@@ -0,0 +1,273 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/application/usecases"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+// memoryOrderRepository lists orders in memory and fails the listing call number failAt
+type memoryOrderRepository struct {
+	repositories.OrderRepository
+	orders []models.Order
+	calls  int
+	failAt int
+}
+
+func (r *memoryOrderRepository) List(ctx context.Context, limit, offset int) ([]models.Order, error) {
+	r.calls++
+	if r.calls == r.failAt {
+		return nil, errors.New("connection reset")
+	}
+	if offset >= len(r.orders) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > len(r.orders) {
+		end = len(r.orders)
+	}
+	return r.orders[offset:end], nil
+}
+
+func (r *memoryOrderRepository) ListAfter(ctx context.Context, after *repositories.OrderCursor, limit int) ([]models.Order, error) {
+	r.calls++
+	if r.calls == r.failAt {
+		return nil, errors.New("connection reset")
+	}
+
+	sorted := append([]models.Order(nil), r.orders...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return orderBefore(sorted[i].CreatedAt, sorted[i].ID, sorted[j].CreatedAt, sorted[j].ID)
+	})
+
+	var orders []models.Order
+	for _, order := range sorted {
+		if after != nil && !orderBefore(after.CreatedAt, after.ID, order.CreatedAt, order.ID) {
+			continue
+		}
+		if len(orders) == limit {
+			break
+		}
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+// orderBefore compares two (created_at, id) positions like PostgreSQL
+func orderBefore(createdAt time.Time, id uuid.UUID, otherCreatedAt time.Time, otherID uuid.UUID) bool {
+	if !createdAt.Equal(otherCreatedAt) {
+		return createdAt.Before(otherCreatedAt)
+	}
+	return id.String() < otherID.String()
+}
+
+// memoryBackfillRepository stores backfill checkpoints and snapshotted order versions in
+// memory and writes the snapshot events to an in-memory outbox
+type memoryBackfillRepository struct {
+	checkpoints map[string]models.BackfillCheckpoint
+	snapshots   map[string]uuid.UUID
+	outbox      *memoryOutboxRepository
+}
+
+func newMemoryBackfillRepository(outbox *memoryOutboxRepository) *memoryBackfillRepository {
+	return &memoryBackfillRepository{
+		checkpoints: map[string]models.BackfillCheckpoint{},
+		snapshots:   map[string]uuid.UUID{},
+		outbox:      outbox,
+	}
+}
+
+func (r *memoryBackfillRepository) GetCheckpoint(ctx context.Context, name string) (*models.BackfillCheckpoint, error) {
+	checkpoint, ok := r.checkpoints[name]
+	if !ok {
+		return &models.BackfillCheckpoint{Name: name, StartedAt: time.Now()}, nil
+	}
+	return &checkpoint, nil
+}
+
+func (r *memoryBackfillRepository) SaveCheckpoint(ctx context.Context, checkpoint *models.BackfillCheckpoint) error {
+	r.checkpoints[checkpoint.Name] = *checkpoint
+	return nil
+}
+
+func (r *memoryBackfillRepository) CreateOrderSnapshot(ctx context.Context, snapshot *models.BackfillOrderSnapshot, event *models.OutboxEvent) (bool, error) {
+	version := snapshot.OrderID.String() + "@" + snapshot.OrderUpdatedAt.UTC().Format(time.RFC3339Nano)
+	if _, ok := r.snapshots[version]; ok {
+		return false, nil
+	}
+	r.snapshots[version] = snapshot.EventID
+	r.outbox.events = append(r.outbox.events, *event)
+	return true, nil
+}
+
+func newMemoryOrderRepository(count int) *memoryOrderRepository {
+	repo := &memoryOrderRepository{}
+	updatedAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < count; i++ {
+		repo.orders = append(repo.orders, models.Order{
+			ID:          uuid.New(),
+			CustomerID:  "customer-1",
+			OrderNumber: fmt.Sprintf("ORD-%03d", i),
+			Status:      models.OrderStatusPending,
+			TotalAmount: 20,
+			Currency:    "BRL",
+			Items: []models.OrderItem{
+				{ProductID: "prod-1", ProductName: "Product 1", Quantity: 2, UnitPrice: 5, TotalPrice: 10},
+				{ProductID: "prod-2", ProductName: "Product 2", Quantity: 1, UnitPrice: 10, TotalPrice: 10},
+			},
+			CreatedAt: updatedAt.Add(time.Duration(i) * time.Second),
+			UpdatedAt: updatedAt,
+		})
+	}
+	return repo
+}
+
+func newTestBackfill(orderRepo *memoryOrderRepository, backfillRepo *memoryBackfillRepository) *usecases.OrderSnapshotBackfill {
+	return usecases.NewOrderSnapshotBackfill(config.BackfillConfig{PageSize: 2}, orderRepo, backfillRepo, nil)
+}
+
+func TestOrderSnapshotBackfillWritesSnapshots(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := newMemoryOrderRepository(3)
+	outboxRepo := newMemoryOutboxRepository()
+	backfill := newTestBackfill(orderRepo, newMemoryBackfillRepository(outboxRepo))
+
+	checkpoint, err := backfill.Run(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), checkpoint.Processed)
+	assert.Equal(t, int64(3), checkpoint.Created)
+	assert.NotNil(t, checkpoint.CompletedAt)
+	require.Len(t, outboxRepo.events, 3)
+
+	event := outboxRepo.events[0]
+	order := orderRepo.orders[0]
+	assert.Equal(t, usecases.OrderSnapshotID(&order), event.ID)
+	assert.Equal(t, usecases.OrderSnapshotEventType, event.EventType)
+	assert.Equal(t, "Order", event.AggregateType)
+	assert.Equal(t, order.ID.String(), event.AggregateID)
+	assert.Equal(t, models.OutboxStatusPending, event.Status)
+	assert.Equal(t, order.OrderNumber, event.EventData["order_number"])
+	assert.Equal(t, 2, event.EventData["items_count"])
+	assert.Len(t, event.EventData["items"], 2, "the snapshot carries the full item list")
+	assert.Equal(t, "txstream-backfill", event.EventMetadata["source"])
+}
+
+func TestOrderSnapshotBackfillIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := newMemoryOrderRepository(3)
+	outboxRepo := newMemoryOutboxRepository()
+	backfill := newTestBackfill(orderRepo, newMemoryBackfillRepository(outboxRepo))
+
+	_, err := backfill.Run(ctx, false)
+	require.NoError(t, err)
+
+	checkpoint, err := backfill.Run(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), checkpoint.Processed, "a completed backfill starts a new pass")
+	assert.Equal(t, int64(0), checkpoint.Created, "the same order versions are not snapshotted twice")
+	assert.Len(t, outboxRepo.events, 3)
+
+	// The retention purging the published snapshots does not make them written again
+	outboxRepo.events = nil
+	checkpoint, err = backfill.Run(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), checkpoint.Created, "snapshotted versions are recorded by the backfill")
+	assert.Empty(t, outboxRepo.events)
+
+	orderRepo.orders[1].UpdatedAt = orderRepo.orders[1].UpdatedAt.Add(time.Minute)
+
+	checkpoint, err = backfill.Run(ctx, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), checkpoint.Created, "an updated order gets a new snapshot")
+	require.Len(t, outboxRepo.events, 1)
+	assert.Equal(t, usecases.OrderSnapshotID(&orderRepo.orders[1]), outboxRepo.events[0].ID)
+}
+
+func TestOrderSnapshotBackfillResumesFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := newMemoryOrderRepository(5)
+	orderRepo.failAt = 2
+	outboxRepo := newMemoryOutboxRepository()
+	backfillRepo := newMemoryBackfillRepository(outboxRepo)
+	backfill := newTestBackfill(orderRepo, backfillRepo)
+
+	checkpoint, err := backfill.Run(ctx, false)
+	require.Error(t, err)
+	require.NotNil(t, checkpoint.CursorID)
+	assert.Equal(t, orderRepo.orders[1].ID, *checkpoint.CursorID)
+	assert.Nil(t, checkpoint.CompletedAt)
+
+	saved := backfillRepo.checkpoints[usecases.OrderSnapshotBackfillName]
+	require.NotNil(t, saved.CursorID, "the first page was checkpointed")
+	assert.Equal(t, orderRepo.orders[1].ID, *saved.CursorID)
+	assert.Equal(t, orderRepo.orders[1].CreatedAt, *saved.CursorCreatedAt)
+
+	checkpoint, err = backfill.Run(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, orderRepo.orders[4].ID, *checkpoint.CursorID)
+	assert.Equal(t, int64(5), checkpoint.Processed)
+	assert.Equal(t, int64(5), checkpoint.Created)
+	assert.Equal(t, 4, orderRepo.calls, "the resumed run starts at the checkpointed page")
+	assert.Len(t, outboxRepo.events, 5)
+}
+
+func TestOrderSnapshotBackfillStampsConfiguredTTL(t *testing.T) {
+	orderRepo := newMemoryOrderRepository(1)
+	outboxRepo := newMemoryOutboxRepository()
+	workerConfig := &config.WorkerConfig{EventTTLs: usecases.OrderSnapshotEventType + "=30m"}
+	backfill := usecases.NewOrderSnapshotBackfill(config.BackfillConfig{PageSize: 2}, orderRepo,
+		newMemoryBackfillRepository(outboxRepo), workerConfig)
+
+	_, err := backfill.Run(context.Background(), false)
+	require.NoError(t, err)
+	require.Len(t, outboxRepo.events, 1)
+
+	event := outboxRepo.events[0]
+	require.NotNil(t, event.ExpiresAt, "the expiry is set when the event is written")
+	assert.Equal(t, event.CreatedAt.Add(30*time.Minute), *event.ExpiresAt)
+}
+
+func TestOrderSnapshotBackfillCursorIgnoresConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	orderRepo := newMemoryOrderRepository(4)
+	createdAt := orderRepo.orders[0].CreatedAt
+	for i := range orderRepo.orders {
+		orderRepo.orders[i].CreatedAt = createdAt
+	}
+	orderRepo.failAt = 2
+	outboxRepo := newMemoryOutboxRepository()
+	backfill := newTestBackfill(orderRepo, newMemoryBackfillRepository(outboxRepo))
+
+	_, err := backfill.Run(ctx, false)
+	require.Error(t, err)
+
+	// A newer order and the deletion of an order already processed must not shift the next page
+	newer := newMemoryOrderRepository(1).orders[0]
+	newer.CreatedAt = createdAt.Add(time.Hour)
+	orderRepo.orders = append(orderRepo.orders, newer)
+	processed := outboxRepo.events[0].AggregateID
+	for i := range orderRepo.orders {
+		if orderRepo.orders[i].ID.String() == processed {
+			orderRepo.orders = append(orderRepo.orders[:i], orderRepo.orders[i+1:]...)
+			break
+		}
+	}
+
+	checkpoint, err := backfill.Run(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), checkpoint.Processed, "orders created together are told apart by ID")
+	assert.Equal(t, int64(5), checkpoint.Created)
+	assert.Len(t, outboxRepo.events, 5, "every order gets exactly one snapshot")
+}
@@ -0,0 +1,64 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+)
+
+func TestOutboxEventExpiry(t *testing.T) {
+	t.Run("event_without_expiry_never_expires", func(t *testing.T) {
+		event := &models.OutboxEvent{CreatedAt: time.Now().Add(-48 * time.Hour)}
+		assert.False(t, event.IsExpired(time.Now()))
+	})
+
+	t.Run("set_ttl_is_relative_to_creation_date", func(t *testing.T) {
+		createdAt := time.Now().Add(-time.Hour)
+		event := &models.OutboxEvent{CreatedAt: createdAt}
+
+		event.SetTTL(30 * time.Minute)
+
+		require.NotNil(t, event.ExpiresAt)
+		assert.Equal(t, createdAt.Add(30*time.Minute), *event.ExpiresAt)
+		assert.True(t, event.IsExpired(time.Now()))
+	})
+
+	t.Run("zero_ttl_keeps_event_without_expiry", func(t *testing.T) {
+		event := &models.OutboxEvent{CreatedAt: time.Now()}
+		event.SetTTL(0)
+		assert.Nil(t, event.ExpiresAt)
+	})
+
+	t.Run("mark_as_expired_changes_status", func(t *testing.T) {
+		event := &models.OutboxEvent{Status: models.OutboxStatusPending}
+		event.MarkAsExpired()
+		assert.Equal(t, models.OutboxStatusExpired, event.Status)
+	})
+}
+
+func TestWorkerEventTTLConfig(t *testing.T) {
+	workerConfig := &config.WorkerConfig{
+		PoolSize:        1,
+		BatchSize:       10,
+		Interval:        time.Second,
+		LeaseDuration:   time.Minute,
+		DefaultEventTTL: time.Hour,
+		EventTTLs:       "StockReserved=15m, OrderCreated=24h",
+	}
+
+	require.NoError(t, workerConfig.Validate())
+	assert.Equal(t, 15*time.Minute, workerConfig.GetEventTTL("StockReserved"))
+	assert.Equal(t, 24*time.Hour, workerConfig.GetEventTTL("OrderCreated"))
+	assert.Equal(t, time.Hour, workerConfig.GetEventTTL("OrderShipped"))
+
+	workerConfig.EventTTLs = "StockReserved"
+	assert.Error(t, workerConfig.Validate())
+
+	workerConfig.EventTTLs = "StockReserved=soon"
+	assert.Error(t, workerConfig.Validate())
+}
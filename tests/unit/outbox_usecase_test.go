@@ -0,0 +1,366 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/application/dto"
+	"github.com/lorenaziviani/txstream/internal/application/usecases"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+)
+
+// memoryOutboxRepository implements the outbox administration queries in memory
+type memoryOutboxRepository struct {
+	repositories.OutboxRepository
+	events []models.OutboxEvent
+}
+
+func (r *memoryOutboxRepository) GetByID(ctx context.Context, id string) (*models.OutboxEvent, error) {
+	for i := range r.events {
+		if r.events[i].ID.String() == id {
+			return &r.events[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w with id: %s", repositories.ErrEventNotFound, id)
+}
+
+func (r *memoryOutboxRepository) ListEvents(ctx context.Context, filter repositories.OutboxEventFilter, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	for _, event := range r.events {
+		if len(filter.Statuses) > 0 && !containsStatus(filter.Statuses, event.Status) {
+			continue
+		}
+		if len(filter.IDs) > 0 && !containsID(filter.IDs, event.ID) {
+			continue
+		}
+		if filter.EventType != "" && event.EventType != filter.EventType {
+			continue
+		}
+		if filter.AggregateID != "" && event.AggregateID != filter.AggregateID {
+			continue
+		}
+		if filter.CorrelationID != "" && event.CorrelationID != filter.CorrelationID {
+			continue
+		}
+		if filter.After != nil {
+			if filter.Ascending && !event.CreatedAt.After(filter.After.CreatedAt) {
+				continue
+			}
+			if !filter.Ascending && !event.CreatedAt.Before(filter.After.CreatedAt) {
+				continue
+			}
+		}
+		events = append(events, event)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if filter.Ascending {
+			return events[i].CreatedAt.Before(events[j].CreatedAt)
+		}
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (r *memoryOutboxRepository) RetryEvents(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	var affected int64
+	for i := range r.events {
+		if containsID(ids, r.events[i].ID) && containsStatus([]models.OutboxStatus{models.OutboxStatusFailed, models.OutboxStatusDeadLetter}, r.events[i].Status) {
+			r.events[i].Status = models.OutboxStatusPending
+			affected++
+		}
+	}
+	return affected, nil
+}
+
+func (r *memoryOutboxRepository) DeleteEvents(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	deletable := []models.OutboxStatus{models.OutboxStatusPublished, models.OutboxStatusFailed, models.OutboxStatusExpired, models.OutboxStatusDeadLetter}
+	var kept []models.OutboxEvent
+	var affected int64
+	for _, event := range r.events {
+		claimed := event.ClaimedUntil != nil && event.ClaimedUntil.After(time.Now())
+		if containsID(ids, event.ID) && containsStatus(deletable, event.Status) && !claimed {
+			affected++
+			continue
+		}
+		kept = append(kept, event)
+	}
+	r.events = kept
+	return affected, nil
+}
+
+func (r *memoryOutboxRepository) CreateIfNotExists(ctx context.Context, event *models.OutboxEvent) (bool, error) {
+	for _, existing := range r.events {
+		if existing.ID == event.ID {
+			return false, nil
+		}
+	}
+	r.events = append(r.events, *event)
+	return true, nil
+}
+
+func (r *memoryOutboxRepository) GetDeliveredEvents(ctx context.Context, publishedAfter, publishedBefore time.Time, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	for _, event := range r.events {
+		if event.Status != models.OutboxStatusPublished || event.KafkaOffset == nil || event.PublishedAt == nil {
+			continue
+		}
+		if event.PublishedAt.Before(publishedAfter) || !event.PublishedAt.Before(publishedBefore) {
+			continue
+		}
+		events = append(events, event)
+	}
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (r *memoryOutboxRepository) CountDeliveries(ctx context.Context, since time.Time, threshold time.Duration) (int64, int64, error) {
+	var total, late int64
+	for _, event := range r.events {
+		switch {
+		case event.Status == models.OutboxStatusPublished && event.PublishedAt != nil && !event.PublishedAt.Before(since):
+			total++
+			if event.PublishedAt.Sub(event.CreatedAt) > threshold {
+				late++
+			}
+		case event.Status == models.OutboxStatusPending && !event.CreatedAt.Before(since) && time.Since(event.CreatedAt) > threshold,
+			(event.Status == models.OutboxStatusFailed || event.Status == models.OutboxStatusDeadLetter) && !event.CreatedAt.Before(since),
+			event.Status == models.OutboxStatusExpired && event.ExpiresAt != nil && !event.ExpiresAt.Before(since):
+			total++
+			late++
+		}
+	}
+	return total, late, nil
+}
+
+func containsStatus(statuses []models.OutboxStatus, status models.OutboxStatus) bool {
+	for _, candidate := range statuses {
+		if candidate == status {
+			return true
+		}
+	}
+	return false
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func newMemoryOutboxRepository(statuses ...models.OutboxStatus) *memoryOutboxRepository {
+	repo := &memoryOutboxRepository{}
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	for i, status := range statuses {
+		repo.events = append(repo.events, models.OutboxEvent{
+			ID:            uuid.New(),
+			AggregateID:   fmt.Sprintf("order-%d", i),
+			AggregateType: "Order",
+			EventType:     "OrderCreated",
+			Status:        status,
+			CreatedAt:     start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	return repo
+}
+
+func TestOutboxUseCaseListEvents(t *testing.T) {
+	repo := newMemoryOutboxRepository(
+		models.OutboxStatusPublished, models.OutboxStatusFailed, models.OutboxStatusPending,
+		models.OutboxStatusFailed, models.OutboxStatusPublished,
+	)
+	useCase := usecases.NewOutboxUseCase(repo)
+	ctx := context.Background()
+
+	t.Run("paginates_with_cursor", func(t *testing.T) {
+		var seen []uuid.UUID
+		cursor := ""
+		for page := 0; page < 3; page++ {
+			response, err := useCase.ListEvents(ctx, &dto.ListOutboxEventsRequest{Cursor: cursor, Limit: 2})
+			require.NoError(t, err)
+			for _, event := range response.Events {
+				seen = append(seen, event.ID)
+			}
+			cursor = response.NextCursor
+			if cursor == "" {
+				break
+			}
+		}
+
+		require.Len(t, seen, 5)
+		assert.Equal(t, repo.events[4].ID, seen[0], "newest event comes first")
+		assert.Equal(t, repo.events[0].ID, seen[4])
+		assert.Empty(t, cursor)
+	})
+
+	t.Run("filters_by_status", func(t *testing.T) {
+		response, err := useCase.ListEvents(ctx, &dto.ListOutboxEventsRequest{
+			Filter: dto.OutboxEventFilterDTO{Statuses: []string{"failed"}},
+		})
+		require.NoError(t, err)
+		assert.Len(t, response.Events, 2)
+	})
+
+	t.Run("rejects_invalid_status_and_cursor", func(t *testing.T) {
+		_, err := useCase.ListEvents(ctx, &dto.ListOutboxEventsRequest{
+			Filter: dto.OutboxEventFilterDTO{Statuses: []string{"unknown"}},
+		})
+		assert.ErrorIs(t, err, usecases.ErrInvalidOutboxRequest)
+
+		_, err = useCase.ListEvents(ctx, &dto.ListOutboxEventsRequest{Cursor: "not-a-cursor"})
+		assert.ErrorIs(t, err, usecases.ErrInvalidOutboxRequest)
+	})
+}
+
+func TestOutboxUseCaseListCorrelatedEvents(t *testing.T) {
+	statuses := make([]models.OutboxStatus, 1100)
+	for i := range statuses {
+		statuses[i] = models.OutboxStatusPublished
+	}
+	repo := newMemoryOutboxRepository(statuses...)
+	for i := range repo.events {
+		if i%10 != 0 {
+			repo.events[i].CorrelationID = "checkout-42"
+		}
+	}
+	useCase := usecases.NewOutboxUseCase(repo)
+	ctx := context.Background()
+
+	t.Run("lists_every_event_oldest_first", func(t *testing.T) {
+		response, err := useCase.ListCorrelatedEvents(ctx, "checkout-42")
+		require.NoError(t, err)
+
+		assert.Equal(t, 990, response.Count)
+		assert.False(t, response.Truncated)
+		require.Len(t, response.Events, 990)
+		assert.Equal(t, repo.events[1].ID, response.Events[0].ID)
+		assert.Equal(t, repo.events[1099].ID, response.Events[989].ID)
+		for i := 1; i < len(response.Events); i++ {
+			assert.True(t, response.Events[i-1].CreatedAt.Before(response.Events[i].CreatedAt))
+		}
+	})
+
+	t.Run("unknown_correlation_is_empty", func(t *testing.T) {
+		response, err := useCase.ListCorrelatedEvents(ctx, "unknown")
+		require.NoError(t, err)
+		assert.Equal(t, 0, response.Count)
+		assert.NotNil(t, response.Events)
+	})
+
+	t.Run("rejects_empty_correlation", func(t *testing.T) {
+		_, err := useCase.ListCorrelatedEvents(ctx, "")
+		assert.ErrorIs(t, err, usecases.ErrInvalidOutboxRequest)
+	})
+}
+
+func TestOutboxUseCaseActions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("dry_run_reports_without_changing", func(t *testing.T) {
+		repo := newMemoryOutboxRepository(models.OutboxStatusFailed, models.OutboxStatusPublished, models.OutboxStatusDeadLetter)
+		useCase := usecases.NewOutboxUseCase(repo)
+
+		response, err := useCase.ApplyAction(ctx, usecases.OutboxActionRetry, &dto.OutboxBulkActionRequest{
+			Filter: &dto.OutboxEventFilterDTO{},
+			DryRun: true,
+		})
+		require.NoError(t, err)
+
+		assert.True(t, response.DryRun)
+		assert.Equal(t, 2, response.Matched, "only failed and dead-lettered events can be retried")
+		assert.Zero(t, response.Affected)
+		assert.Equal(t, models.OutboxStatusFailed, repo.events[0].Status)
+	})
+
+	t.Run("bulk_retry_by_filter", func(t *testing.T) {
+		repo := newMemoryOutboxRepository(models.OutboxStatusFailed, models.OutboxStatusPublished, models.OutboxStatusDeadLetter)
+		useCase := usecases.NewOutboxUseCase(repo)
+
+		response, err := useCase.ApplyAction(ctx, usecases.OutboxActionRetry, &dto.OutboxBulkActionRequest{
+			Filter: &dto.OutboxEventFilterDTO{Statuses: []string{"failed", "published"}},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, response.Matched)
+		assert.Equal(t, int64(1), response.Affected)
+		assert.Equal(t, models.OutboxStatusPending, repo.events[0].Status)
+		assert.Equal(t, models.OutboxStatusDeadLetter, repo.events[2].Status)
+	})
+
+	t.Run("single_action_on_ineligible_event_conflicts", func(t *testing.T) {
+		repo := newMemoryOutboxRepository(models.OutboxStatusPublished)
+		useCase := usecases.NewOutboxUseCase(repo)
+
+		_, err := useCase.ApplyEventAction(ctx, usecases.OutboxActionRetry, repo.events[0].ID.String(), "")
+		assert.ErrorIs(t, err, usecases.ErrOutboxActionNotAllowed)
+	})
+
+	t.Run("unknown_event_and_action", func(t *testing.T) {
+		repo := newMemoryOutboxRepository(models.OutboxStatusFailed)
+		useCase := usecases.NewOutboxUseCase(repo)
+
+		_, err := useCase.ApplyEventAction(ctx, usecases.OutboxActionRetry, uuid.New().String(), "")
+		assert.ErrorIs(t, err, repositories.ErrEventNotFound)
+
+		_, err = useCase.ApplyAction(ctx, "publish", &dto.OutboxBulkActionRequest{IDs: []string{repo.events[0].ID.String()}})
+		assert.ErrorIs(t, err, usecases.ErrUnknownOutboxAction)
+	})
+
+	t.Run("delete_keeps_pending_and_claimed_events", func(t *testing.T) {
+		repo := newMemoryOutboxRepository(models.OutboxStatusPending, models.OutboxStatusPublished, models.OutboxStatusFailed)
+		claimedUntil := time.Now().Add(time.Minute)
+		repo.events[2].ClaimedUntil = &claimedUntil
+		useCase := usecases.NewOutboxUseCase(repo)
+
+		response, err := useCase.ApplyAction(ctx, usecases.OutboxActionDelete, &dto.OutboxBulkActionRequest{
+			IDs: []string{repo.events[0].ID.String(), repo.events[1].ID.String(), repo.events[2].ID.String()},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, response.Matched, "pending events cannot be deleted")
+		assert.Equal(t, int64(1), response.Affected, "a claimed event is being published")
+		require.Len(t, repo.events, 2)
+		assert.Equal(t, models.OutboxStatusPending, repo.events[0].Status)
+		assert.Equal(t, models.OutboxStatusFailed, repo.events[1].Status)
+	})
+
+	t.Run("destructive_actions_reject_empty_filter", func(t *testing.T) {
+		repo := newMemoryOutboxRepository(models.OutboxStatusFailed, models.OutboxStatusPublished)
+		useCase := usecases.NewOutboxUseCase(repo)
+
+		for _, action := range []string{usecases.OutboxActionDelete, usecases.OutboxActionDeadLetter} {
+			_, err := useCase.ApplyAction(ctx, action, &dto.OutboxBulkActionRequest{Filter: &dto.OutboxEventFilterDTO{}})
+			assert.ErrorIs(t, err, usecases.ErrInvalidOutboxRequest, action)
+		}
+		assert.Len(t, repo.events, 2)
+
+		response, err := useCase.ApplyAction(ctx, usecases.OutboxActionDelete, &dto.OutboxBulkActionRequest{
+			Filter: &dto.OutboxEventFilterDTO{Statuses: []string{"published"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), response.Affected)
+	})
+
+	t.Run("requires_ids_or_filter", func(t *testing.T) {
+		useCase := usecases.NewOutboxUseCase(newMemoryOutboxRepository())
+
+		_, err := useCase.ApplyAction(ctx, usecases.OutboxActionDelete, &dto.OutboxBulkActionRequest{})
+		assert.ErrorIs(t, err, usecases.ErrInvalidOutboxRequest)
+	})
+}
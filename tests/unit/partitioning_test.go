@@ -0,0 +1,92 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/partitioning"
+)
+
+func TestPartitionFor(t *testing.T) {
+	// Sunday evening in São Paulo is already Monday in UTC
+	instant := time.Date(2026, 10, 18, 22, 30, 0, 0, time.FixedZone("BRT", -3*60*60))
+
+	t.Run("daily_partition", func(t *testing.T) {
+		partition := partitioning.PartitionFor(instant, config.PartitionGranularityDay)
+
+		assert.Equal(t, "outbox_p20261019", partition.Name)
+		assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), *partition.From)
+		assert.Equal(t, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), *partition.To)
+		assert.True(t, partition.Covers(instant))
+	})
+
+	t.Run("weekly_partition_starts_on_monday", func(t *testing.T) {
+		partition := partitioning.PartitionFor(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), config.PartitionGranularityWeek)
+
+		assert.Equal(t, "outbox_p20261012", partition.Name)
+		assert.Equal(t, time.Monday, partition.From.Weekday())
+		assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), *partition.To)
+	})
+}
+
+func TestParsePartitionBound(t *testing.T) {
+	t.Run("range_bound", func(t *testing.T) {
+		from, to, err := partitioning.ParseBound("FOR VALUES FROM ('2026-10-19 00:00:00+00') TO ('2026-10-20 00:00:00+00')")
+		require.NoError(t, err)
+
+		assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), *from)
+		assert.Equal(t, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), *to)
+	})
+
+	t.Run("legacy_bound_starts_at_minvalue", func(t *testing.T) {
+		from, to, err := partitioning.ParseBound("FOR VALUES FROM (MINVALUE) TO ('2026-10-19 02:00:00+02')")
+		require.NoError(t, err)
+
+		assert.Nil(t, from)
+		assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), *to)
+
+		partition := partitioning.Partition{Name: "outbox_legacy", From: from, To: to}
+		assert.True(t, partition.Covers(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)))
+		assert.False(t, partition.Covers(*to))
+	})
+
+	t.Run("default_partition_is_unsupported", func(t *testing.T) {
+		_, _, err := partitioning.ParseBound("DEFAULT")
+		assert.Error(t, err)
+	})
+}
+
+func TestPartitioningConfigValidation(t *testing.T) {
+	cfg := config.PartitioningConfig{Granularity: config.PartitionGranularityWeek, Premake: 4, Interval: time.Hour}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Granularity = "month"
+	assert.Error(t, cfg.Validate())
+}
+
+func TestPartitionedIndex(t *testing.T) {
+	t.Run("recreates_the_legacy_index_on_the_partitioned_table", func(t *testing.T) {
+		statement, ok := partitioning.PartitionedIndex("idx_outbox_correlation_id",
+			"CREATE INDEX idx_outbox_correlation_id ON public.outbox_legacy USING btree (correlation_id, created_at)")
+
+		require.True(t, ok)
+		assert.Equal(t, "CREATE INDEX IF NOT EXISTS idx_outbox_correlation_id ON outbox USING btree (correlation_id, created_at)", statement)
+	})
+
+	t.Run("keeps_partial_index_predicates", func(t *testing.T) {
+		statement, ok := partitioning.PartitionedIndex("idx_outbox_claimed_until",
+			"CREATE INDEX idx_outbox_claimed_until ON public.outbox_legacy USING btree (claimed_until) WHERE (claimed_by IS NOT NULL)")
+
+		require.True(t, ok)
+		assert.Equal(t, "CREATE INDEX IF NOT EXISTS idx_outbox_claimed_until ON outbox USING btree (claimed_until) WHERE (claimed_by IS NOT NULL)", statement)
+	})
+
+	t.Run("skips_unique_indexes", func(t *testing.T) {
+		_, ok := partitioning.PartitionedIndex("outbox_pkey", "CREATE UNIQUE INDEX outbox_pkey ON public.outbox_legacy USING btree (id)")
+		assert.False(t, ok)
+	})
+}
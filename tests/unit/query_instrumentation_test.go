@@ -0,0 +1,123 @@
+package unit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/database"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/logging"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/metrics"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+)
+
+// newDryRunDB opens a GORM connection that builds the statements without executing them
+func newDryRunDB(t *testing.T, plugin gorm.Plugin) *gorm.DB {
+	db, err := gorm.Open(postgres.Open("host=localhost user=txstream dbname=txstream"), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               gormlogger.Discard,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(plugin))
+	return db
+}
+
+func TestFingerprintSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{
+			name:     "placeholders_and_literals",
+			sql:      "SELECT * FROM outbox WHERE status = $1 AND retry_count < 3 AND event_type = 'OrderCreated'",
+			expected: "SELECT * FROM outbox WHERE status = ? AND retry_count < ? AND event_type = ?",
+		},
+		{
+			name:     "value_list",
+			sql:      "UPDATE outbox SET status = $1 WHERE id IN ($2,$3, $4)",
+			expected: "UPDATE outbox SET status = ? WHERE id IN (?+)",
+		},
+		{
+			name:     "multi_row_insert",
+			sql:      "INSERT INTO order_items (order_id,quantity) VALUES ($1,$2),($3,$4),($5,$6)",
+			expected: "INSERT INTO order_items (order_id,quantity) VALUES (?+), ...",
+		},
+		{
+			name:     "partition_and_whitespace",
+			sql:      "SELECT COUNT(*)\n\tFROM outbox_p20240101   WHERE status IN ($1, $2)",
+			expected: "SELECT COUNT(*) FROM outbox WHERE status IN (?+)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, database.FingerprintSQL(tt.sql))
+		})
+	}
+}
+
+func TestQueryOperationAndTable(t *testing.T) {
+	assert.Equal(t, "select", database.QueryOperation(`SELECT * FROM "outbox" WHERE status = $1`))
+	assert.Equal(t, "select_for_update", database.QueryOperation("SELECT * FROM outbox WHERE id = $1 FOR UPDATE"))
+	assert.Equal(t, "select_for_update", database.QueryOperation("select * from outbox for no key update skip locked"))
+	assert.Equal(t, "insert", database.QueryOperation("INSERT INTO orders (id) VALUES ($1)"))
+	assert.Equal(t, "other", database.QueryOperation("LOCK TABLE outbox IN ACCESS EXCLUSIVE MODE"))
+
+	assert.Equal(t, "outbox", database.QueryTable(`SELECT * FROM "outbox" WHERE id = $1`))
+	assert.Equal(t, "outbox", database.QueryTable("SELECT COUNT(*) FROM outbox_p20240101 WHERE status = $1"))
+	assert.Equal(t, "orders", database.QueryTable("INSERT INTO orders (id) VALUES ($1)"))
+	assert.Equal(t, database.UnknownTable, database.QueryTable("SELECT 1"))
+}
+
+func TestQueryPlugin(t *testing.T) {
+	t.Run("records_latency_by_operation_and_table", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		db := newDryRunDB(t, database.NewQueryPlugin(0, metrics.NewMetrics(registry), nil))
+
+		var events []models.OutboxEvent
+		require.NoError(t, db.Where("status = ?", models.OutboxStatusPending).Limit(10).Find(&events).Error)
+		require.NoError(t, db.Exec("SELECT * FROM outbox WHERE id = ? FOR UPDATE", "id").Error)
+
+		assert.Equal(t, float64(1), gatheredValue(t, registry, "txstream_db_query_duration_seconds",
+			map[string]string{"operation": "select", "table": "outbox"}))
+		assert.Equal(t, float64(1), gatheredValue(t, registry, "txstream_db_query_duration_seconds",
+			map[string]string{"operation": "select_for_update", "table": "outbox"}))
+		assert.Equal(t, float64(0), gatheredValue(t, registry, "txstream_db_query_errors_total", nil))
+	})
+
+	t.Run("logs_slow_queries_with_fingerprint", func(t *testing.T) {
+		var buffer bytes.Buffer
+		logger, err := logging.NewWithWriter(&buffer, loggingTestConfig())
+		require.NoError(t, err)
+
+		db := newDryRunDB(t, database.NewQueryPlugin(time.Nanosecond, nil, logger))
+		require.NoError(t, db.Exec("UPDATE outbox SET status = ? WHERE id IN ?", "failed", []string{"a", "b"}).Error)
+
+		records := decodeLogLines(t, &buffer)
+		require.Len(t, records, 1)
+		assert.Equal(t, "Slow query", records[0]["msg"])
+		assert.Equal(t, "update", records[0]["operation"])
+		assert.Equal(t, "outbox", records[0]["table"])
+		assert.Equal(t, "UPDATE outbox SET status = ? WHERE id IN (?+)", records[0]["fingerprint"])
+	})
+
+	t.Run("slow_query_log_disabled", func(t *testing.T) {
+		var buffer bytes.Buffer
+		logger, err := logging.NewWithWriter(&buffer, loggingTestConfig())
+		require.NoError(t, err)
+
+		db := newDryRunDB(t, database.NewQueryPlugin(0, nil, logger))
+		require.NoError(t, db.Exec("SELECT 1").Error)
+
+		assert.Empty(t, buffer.String())
+	})
+}
@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/worker"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("disabled_without_limits", func(t *testing.T) {
+		limiter := worker.NewRateLimiter(nil, nil)
+		assert.False(t, limiter.Enabled())
+
+		wait, allowed := limiter.Reserve("txstream.events", "OrderCreated", 0)
+		assert.True(t, allowed)
+		assert.Zero(t, wait)
+	})
+
+	t.Run("burst_is_allowed_without_waiting", func(t *testing.T) {
+		limiter := worker.NewRateLimiter(map[string]float64{"txstream.events": 5}, nil)
+		assert.True(t, limiter.Enabled())
+
+		for i := 0; i < 5; i++ {
+			wait, allowed := limiter.Reserve("txstream.events", "OrderCreated", 0)
+			assert.True(t, allowed)
+			assert.Zero(t, wait)
+		}
+	})
+
+	t.Run("exceeding_max_wait_throttles_without_reserving", func(t *testing.T) {
+		limiter := worker.NewRateLimiter(map[string]float64{"txstream.events": 1}, nil)
+
+		_, allowed := limiter.Reserve("txstream.events", "OrderCreated", 0)
+		assert.True(t, allowed)
+
+		wait, allowed := limiter.Reserve("txstream.events", "OrderCreated", 100*time.Millisecond)
+		assert.False(t, allowed)
+		assert.Greater(t, wait, 100*time.Millisecond)
+
+		wait, allowed = limiter.Reserve("txstream.events", "OrderCreated", 2*time.Second)
+		assert.True(t, allowed)
+		assert.LessOrEqual(t, wait, time.Second, "throttled attempt should not have consumed a token")
+	})
+
+	t.Run("event_type_limit_applies_independently_of_topic", func(t *testing.T) {
+		limiter := worker.NewRateLimiter(
+			map[string]float64{"txstream.events": 1000},
+			map[string]float64{"OrderSnapshot": 1},
+		)
+
+		_, allowed := limiter.Reserve("txstream.events", "OrderSnapshot", 0)
+		assert.True(t, allowed)
+
+		_, allowed = limiter.Reserve("txstream.events", "OrderSnapshot", 0)
+		assert.False(t, allowed)
+
+		_, allowed = limiter.Reserve("txstream.events", "OrderCreated", 0)
+		assert.True(t, allowed)
+	})
+}
@@ -0,0 +1,239 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/kafka"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/replay"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/retention"
+)
+
+// memoryReplayRepository stores replay jobs in memory
+type memoryReplayRepository struct {
+	jobs  map[uuid.UUID]models.ReplayJob
+	saves int
+}
+
+func newMemoryReplayRepository() *memoryReplayRepository {
+	return &memoryReplayRepository{jobs: make(map[uuid.UUID]models.ReplayJob)}
+}
+
+func (r *memoryReplayRepository) Create(ctx context.Context, job *models.ReplayJob) error {
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *memoryReplayRepository) GetByID(ctx context.Context, id string) (*models.ReplayJob, error) {
+	job, ok := r.jobs[uuid.MustParse(id)]
+	if !ok {
+		return nil, fmt.Errorf("%w with id: %s", repositories.ErrReplayJobNotFound, id)
+	}
+	return &job, nil
+}
+
+func (r *memoryReplayRepository) List(ctx context.Context, limit int) ([]models.ReplayJob, error) {
+	var jobs []models.ReplayJob
+	for _, job := range r.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (r *memoryReplayRepository) Save(ctx context.Context, job *models.ReplayJob) error {
+	r.saves++
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+type publishedMessage struct {
+	event models.OutboxEvent
+	opts  kafka.PublishOptions
+}
+
+// recordingProducer records the replayed messages and fails the publication number failAt
+type recordingProducer struct {
+	kafka.EventProducer
+	messages []publishedMessage
+	calls    int
+	failAt   int
+}
+
+func (p *recordingProducer) PublishEventWithOptions(ctx context.Context, event *models.OutboxEvent, opts kafka.PublishOptions) error {
+	p.calls++
+	if p.calls == p.failAt {
+		return errors.New("broker unavailable")
+	}
+	p.messages = append(p.messages, publishedMessage{event: *event, opts: opts})
+	return nil
+}
+
+func (p *recordingProducer) replayedIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, len(p.messages))
+	for i, message := range p.messages {
+		ids[i] = message.event.ID
+	}
+	return ids
+}
+
+func replayTestConfig() config.ReplayConfig {
+	return config.ReplayConfig{
+		RateLimit:          0,
+		BatchSize:          2,
+		CheckpointEvery:    1,
+		CheckpointInterval: time.Minute,
+	}
+}
+
+func TestReplayerRepublishesSelectedEvents(t *testing.T) {
+	ctx := context.Background()
+
+	outboxRepo := newMemoryOutboxRepository(
+		models.OutboxStatusPublished, models.OutboxStatusPublished, models.OutboxStatusFailed,
+		models.OutboxStatusPublished, models.OutboxStatusPublished,
+	)
+	for i := range outboxRepo.events {
+		outboxRepo.events[i].AggregateID = "order-1"
+	}
+	outboxRepo.events[3].AggregateID = "order-2"
+
+	producer := &recordingProducer{}
+	replayRepo := newMemoryReplayRepository()
+	replayer := replay.NewReplayer(replayTestConfig(), replayRepo, outboxRepo, nil, producer, nil, nil)
+
+	req := &replay.Request{Source: models.ReplaySourceOutbox, AggregateID: "order-1", Topic: "txstream.replay"}
+
+	count, err := replayer.Count(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count, "only the published events of the aggregate are selected")
+
+	job, err := replayer.Create(ctx, req)
+	require.NoError(t, err)
+	require.NoError(t, replayer.Run(ctx, job))
+
+	assert.Equal(t, []uuid.UUID{outboxRepo.events[0].ID, outboxRepo.events[1].ID, outboxRepo.events[4].ID},
+		producer.replayedIDs(), "events are replayed oldest first")
+
+	message := producer.messages[0]
+	assert.Equal(t, "txstream.replay", message.opts.Topic)
+	assert.Equal(t, "true", message.opts.Headers[replay.HeaderReplay])
+	assert.Equal(t, outboxRepo.events[0].ID.String(), message.opts.Headers[replay.HeaderOriginalEventID])
+	assert.Equal(t, job.ID.String(), message.opts.Headers[replay.HeaderReplayJobID])
+
+	saved, err := replayRepo.GetByID(ctx, job.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.ReplayStatusCompleted, saved.Status)
+	assert.Equal(t, int64(3), saved.Replayed)
+	assert.NotNil(t, saved.CompletedAt)
+}
+
+func TestReplayerResumesFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+
+	outboxRepo := newMemoryOutboxRepository(
+		models.OutboxStatusPublished, models.OutboxStatusPublished, models.OutboxStatusPublished,
+		models.OutboxStatusPublished, models.OutboxStatusPublished,
+	)
+	producer := &recordingProducer{failAt: 3}
+	replayRepo := newMemoryReplayRepository()
+	replayer := replay.NewReplayer(replayTestConfig(), replayRepo, outboxRepo, nil, producer, nil, nil)
+
+	job, err := replayer.Create(ctx, &replay.Request{Source: models.ReplaySourceOutbox, EventType: "OrderCreated"})
+	require.NoError(t, err)
+	require.Error(t, replayer.Run(ctx, job))
+
+	failed, err := replayRepo.GetByID(ctx, job.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.ReplayStatusFailed, failed.Status)
+	assert.Equal(t, int64(2), failed.Replayed)
+	require.NotNil(t, failed.CheckpointEventID)
+	assert.Equal(t, outboxRepo.events[1].ID, *failed.CheckpointEventID)
+
+	resumed, err := replayer.Resume(ctx, job.ID.String())
+	require.NoError(t, err)
+	require.NoError(t, replayer.Run(ctx, resumed))
+
+	var ids []uuid.UUID
+	for _, event := range outboxRepo.events {
+		ids = append(ids, event.ID)
+	}
+	assert.Equal(t, ids, producer.replayedIDs(), "each event is replayed once across the crash")
+
+	_, err = replayer.Resume(ctx, job.ID.String())
+	assert.ErrorIs(t, err, replay.ErrNotResumable, "a completed job cannot be resumed")
+}
+
+func TestReplayerRunningJobIsNotResumable(t *testing.T) {
+	ctx := context.Background()
+
+	replayRepo := newMemoryReplayRepository()
+	replayer := replay.NewReplayer(replayTestConfig(), replayRepo, newMemoryOutboxRepository(), nil, &recordingProducer{}, nil, nil)
+
+	job, err := replayer.Create(ctx, &replay.Request{AggregateID: "order-1"})
+	require.NoError(t, err)
+
+	_, err = replayer.Resume(ctx, job.ID.String())
+	assert.ErrorIs(t, err, replay.ErrNotResumable, "the job heartbeat is fresh")
+
+	job.HeartbeatAt = time.Now().Add(-time.Hour)
+	require.NoError(t, replayRepo.Save(ctx, job))
+
+	_, err = replayer.Resume(ctx, job.ID.String())
+	assert.NoError(t, err, "a job without heartbeat was abandoned by a crashed process")
+}
+
+func TestReplayerReplaysArchiveBeforeOutbox(t *testing.T) {
+	ctx := context.Background()
+
+	archived := newMemoryOutboxRepository(models.OutboxStatusPublished, models.OutboxStatusPublished, models.OutboxStatusPublished)
+	for i := range archived.events {
+		archived.events[i].CreatedAt = archived.events[i].CreatedAt.Add(-24 * time.Hour)
+	}
+	archived.events[1].EventType = "OrderCancelled"
+
+	archive, err := retention.NewArchive(t.TempDir())
+	require.NoError(t, err)
+	_, err = archive.Write(archived.events)
+	require.NoError(t, err)
+
+	outboxRepo := newMemoryOutboxRepository(models.OutboxStatusPublished)
+	producer := &recordingProducer{}
+	replayer := replay.NewReplayer(replayTestConfig(), newMemoryReplayRepository(), outboxRepo, archive, producer, nil, nil)
+
+	job, err := replayer.Create(ctx, &replay.Request{EventType: "OrderCreated"})
+	require.NoError(t, err)
+	assert.Equal(t, models.ReplaySourceAll, job.Source)
+	require.NoError(t, replayer.Run(ctx, job))
+
+	assert.Equal(t, []uuid.UUID{archived.events[0].ID, archived.events[2].ID, outboxRepo.events[0].ID}, producer.replayedIDs())
+	assert.Equal(t, models.ReplaySourceOutbox, job.CheckpointSource)
+}
+
+func TestReplayerRejectsInvalidRequests(t *testing.T) {
+	ctx := context.Background()
+	replayer := replay.NewReplayer(replayTestConfig(), newMemoryReplayRepository(), newMemoryOutboxRepository(), nil, &recordingProducer{}, nil, nil)
+
+	_, err := replayer.Create(ctx, &replay.Request{})
+	assert.ErrorIs(t, err, replay.ErrInvalidRequest, "replaying the whole history requires an explicit selection")
+
+	_, err = replayer.Create(ctx, &replay.Request{AggregateID: "order-1", Source: models.ReplaySourceArchive})
+	assert.ErrorIs(t, err, replay.ErrArchiveDisabled)
+
+	after := time.Now()
+	before := after.Add(-time.Hour)
+	_, err = replayer.Create(ctx, &replay.Request{CreatedAfter: &after, CreatedBefore: &before})
+	assert.ErrorIs(t, err, replay.ErrInvalidRequest)
+
+	err = replayer.Cancel(uuid.New().String())
+	assert.ErrorIs(t, err, replay.ErrNotRunning)
+}
@@ -0,0 +1,63 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/retention"
+)
+
+func TestRetentionArchive(t *testing.T) {
+	t.Run("empty_archive_has_no_segments", func(t *testing.T) {
+		archive, err := retention.NewArchive(t.TempDir())
+		require.NoError(t, err)
+
+		segments, err := archive.Segments()
+		require.NoError(t, err)
+		assert.Empty(t, segments)
+	})
+
+	t.Run("written_segment_is_indexed_and_readable", func(t *testing.T) {
+		archive, err := retention.NewArchive(t.TempDir())
+		require.NoError(t, err)
+
+		first := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Second)
+		last := first.Add(time.Hour)
+		events := []models.OutboxEvent{
+			{ID: uuid.New(), AggregateID: "order-1", EventType: "OrderCreated", EventData: models.JSON{"id": "1"}, Status: models.OutboxStatusPublished, CreatedAt: last},
+			{ID: uuid.New(), AggregateID: "order-2", EventType: "OrderCreated", EventData: models.JSON{"id": "2"}, Status: models.OutboxStatusPublished, CreatedAt: first},
+		}
+
+		segment, err := archive.Write(events)
+		require.NoError(t, err)
+		assert.Equal(t, 2, segment.Rows)
+		assert.Equal(t, first, segment.FirstCreatedAt)
+		assert.Equal(t, last, segment.LastCreatedAt)
+
+		segments, err := archive.Segments()
+		require.NoError(t, err)
+		require.Len(t, segments, 1)
+		assert.Equal(t, segment.File, segments[0].File)
+
+		restored, err := archive.Read(segment.File)
+		require.NoError(t, err)
+		require.Len(t, restored, 2)
+		assert.Equal(t, events[0].ID, restored[0].ID)
+		assert.Equal(t, events[1].AggregateID, restored[1].AggregateID)
+		assert.Equal(t, "2", restored[1].EventData["id"])
+	})
+
+	t.Run("empty_batch_writes_nothing", func(t *testing.T) {
+		archive, err := retention.NewArchive(t.TempDir())
+		require.NoError(t, err)
+
+		segment, err := archive.Write(nil)
+		require.NoError(t, err)
+		assert.Nil(t, segment)
+	})
+}
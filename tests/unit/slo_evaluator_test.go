@@ -0,0 +1,125 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/worker"
+)
+
+func sloTestConfig() config.SLOConfig {
+	return config.SLOConfig{
+		Enabled:         true,
+		Objective:       0.9,
+		Threshold:       2 * time.Second,
+		Window:          24 * time.Hour,
+		BurnRateWindows: "1h",
+		Interval:        time.Minute,
+	}
+}
+
+// publishedAfter marks an event as created age ago and published after latency
+func publishedAfter(event *models.OutboxEvent, age, latency time.Duration) {
+	createdAt := time.Now().Add(-age)
+	publishedAt := createdAt.Add(latency)
+	event.Status = models.OutboxStatusPublished
+	event.CreatedAt = createdAt
+	event.PublishedAt = &publishedAt
+}
+
+func TestSLOEvaluatorComputesBudgetAndBurnRates(t *testing.T) {
+	repo := newMemoryOutboxRepository(
+		models.OutboxStatusPending, models.OutboxStatusPending, models.OutboxStatusPending,
+		models.OutboxStatusPending, models.OutboxStatusPending,
+	)
+	// 10h ago: one late and two on time, in the last hour: one on time
+	publishedAfter(&repo.events[0], 10*time.Hour, 5*time.Second)
+	publishedAfter(&repo.events[1], 10*time.Hour, time.Second)
+	publishedAfter(&repo.events[2], 10*time.Hour, time.Second)
+	publishedAfter(&repo.events[3], 30*time.Minute, time.Second)
+	// Pending for longer than the threshold counts as late
+	repo.events[4].CreatedAt = time.Now().Add(-20 * time.Minute)
+
+	evaluator := worker.NewSLOEvaluator(sloTestConfig(), repo, nil, nil)
+	report, err := evaluator.Evaluate(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(5), report.Window.Deliveries)
+	assert.Equal(t, int64(2), report.Window.Late)
+	assert.InDelta(t, 4.0, report.Window.BurnRate, 1e-9, "40% late against a 10% budget")
+	assert.InDelta(t, -3.0, report.ErrorBudgetRemaining, 1e-9, "the budget is overspent")
+
+	require.Len(t, report.BurnRates, 1)
+	hour := report.BurnRates[0]
+	assert.Equal(t, time.Hour, hour.Window)
+	assert.Equal(t, int64(2), hour.Deliveries)
+	assert.Equal(t, int64(1), hour.Late)
+	assert.InDelta(t, 5.0, hour.BurnRate, 1e-9)
+}
+
+func TestSLOEvaluatorCountsUndeliveredEventsAsLate(t *testing.T) {
+	repo := newMemoryOutboxRepository(
+		models.OutboxStatusPending, models.OutboxStatusFailed, models.OutboxStatusDeadLetter,
+		models.OutboxStatusExpired, models.OutboxStatusExpired,
+	)
+	publishedAfter(&repo.events[0], 30*time.Minute, time.Second)
+	// Failed within the threshold still never made it, expired before the window is ignored
+	repo.events[1].CreatedAt = time.Now()
+	repo.events[2].CreatedAt = time.Now().Add(-10 * time.Hour)
+	expiredAt := time.Now().Add(-2 * time.Hour)
+	repo.events[3].ExpiresAt = &expiredAt
+	expiredBefore := time.Now().Add(-48 * time.Hour)
+	repo.events[4].ExpiresAt = &expiredBefore
+
+	evaluator := worker.NewSLOEvaluator(sloTestConfig(), repo, nil, nil)
+	report, err := evaluator.Evaluate(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(4), report.Window.Deliveries)
+	assert.Equal(t, int64(3), report.Window.Late, "failed, dead lettered and expired events are late")
+
+	require.Len(t, report.BurnRates, 1)
+	assert.Equal(t, int64(2), report.BurnRates[0].Deliveries)
+	assert.Equal(t, int64(1), report.BurnRates[0].Late)
+}
+
+func TestSLOEvaluatorWithoutDeliveries(t *testing.T) {
+	evaluator := worker.NewSLOEvaluator(sloTestConfig(), newMemoryOutboxRepository(), nil, nil)
+	report, err := evaluator.Evaluate(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.0, report.Window.BurnRate)
+	assert.Equal(t, 1.0, report.ErrorBudgetRemaining, "no traffic spends no budget")
+}
+
+func TestSLOConfigValidate(t *testing.T) {
+	cfg := sloTestConfig()
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, []time.Duration{time.Hour}, cfg.GetBurnRateWindows())
+
+	invalid := sloTestConfig()
+	invalid.Objective = 1
+	assert.Error(t, invalid.Validate())
+
+	invalid = sloTestConfig()
+	invalid.BurnRateWindows = "1h,48h"
+	assert.Error(t, invalid.Validate(), "a burn rate window cannot be longer than the SLO window")
+
+	invalid = sloTestConfig()
+	invalid.BurnRateWindows = "1h,soon"
+	assert.Error(t, invalid.Validate())
+}
+
+func TestSLOWindowLabel(t *testing.T) {
+	assert.Equal(t, "1h", worker.WindowLabel(time.Hour))
+	assert.Equal(t, "720h", worker.WindowLabel(30*24*time.Hour))
+	assert.Equal(t, "1h30m", worker.WindowLabel(90*time.Minute))
+	assert.Equal(t, "30m", worker.WindowLabel(30*time.Minute))
+	assert.Equal(t, "30s", worker.WindowLabel(30*time.Second))
+}
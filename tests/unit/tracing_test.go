@@ -0,0 +1,196 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	saramamocks "github.com/IBM/sarama/mocks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/kafka"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/tracing"
+)
+
+// setupTestTracing installs a tracer provider recording the spans in memory
+func setupTestTracing(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	previousProvider := otel.GetTracerProvider()
+	previousPropagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	return exporter
+}
+
+func newTracedEvent() *models.OutboxEvent {
+	return &models.OutboxEvent{
+		ID:            uuid.New(),
+		AggregateID:   uuid.New().String(),
+		AggregateType: "Order",
+		EventType:     "OrderCreated",
+		EventData:     models.JSON{"order_number": "ORD-001"},
+		EventMetadata: models.JSON{"source": "txstream-api"},
+		Status:        models.OutboxStatusPending,
+		CreatedAt:     time.Now(),
+	}
+}
+
+func TestTracingMetadataPropagation(t *testing.T) {
+	setupTestTracing(t)
+
+	t.Run("request_trace_is_stored_in_metadata", func(t *testing.T) {
+		ctx, span := tracing.Tracer().Start(context.Background(), "request")
+		defer span.End()
+
+		event := newTracedEvent()
+		tracing.InjectMetadata(ctx, event.EventMetadata)
+
+		traceParent, ok := event.EventMetadata[tracing.TraceParentKey].(string)
+		require.True(t, ok)
+		assert.Contains(t, traceParent, span.SpanContext().TraceID().String())
+
+		spanContext := tracing.SpanContextFromMetadata(event.EventMetadata)
+		assert.Equal(t, span.SpanContext().TraceID(), spanContext.TraceID())
+		assert.Equal(t, span.SpanContext().SpanID(), spanContext.SpanID())
+	})
+
+	t.Run("metadata_without_trace", func(t *testing.T) {
+		event := newTracedEvent()
+		tracing.InjectMetadata(context.Background(), event.EventMetadata)
+
+		assert.NotContains(t, event.EventMetadata, tracing.TraceParentKey)
+		assert.False(t, tracing.SpanContextFromMetadata(event.EventMetadata).IsValid())
+	})
+}
+
+func TestTracingPublishSpan(t *testing.T) {
+	exporter := setupTestTracing(t)
+
+	t.Run("publish_span_starts_new_trace_linked_to_request", func(t *testing.T) {
+		exporter.Reset()
+
+		requestCtx, requestSpan := tracing.Tracer().Start(context.Background(), "request")
+		event := newTracedEvent()
+		tracing.InjectMetadata(requestCtx, event.EventMetadata)
+		requestSpan.End()
+
+		_, publishSpan := tracing.StartPublishSpan(context.Background(), event, "txstream.events")
+		publishSpan.End()
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+
+		publish := spans[1]
+		assert.Equal(t, "txstream.events publish", publish.Name)
+		assert.Equal(t, trace.SpanKindProducer, publish.SpanKind)
+		assert.NotEqual(t, requestSpan.SpanContext().TraceID(), publish.SpanContext.TraceID())
+		require.Len(t, publish.Links, 1)
+		assert.Equal(t, requestSpan.SpanContext().TraceID(), publish.Links[0].SpanContext.TraceID())
+		assert.Equal(t, requestSpan.SpanContext().SpanID(), publish.Links[0].SpanContext.SpanID())
+	})
+
+	t.Run("publish_span_without_request_trace", func(t *testing.T) {
+		exporter.Reset()
+
+		_, publishSpan := tracing.StartPublishSpan(context.Background(), newTracedEvent(), "txstream.events")
+		publishSpan.End()
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Empty(t, spans[0].Links)
+	})
+}
+
+func TestTracingKafkaHeaders(t *testing.T) {
+	setupTestTracing(t)
+
+	kafkaConfig := &config.KafkaConfig{TopicEvents: "txstream.events"}
+
+	t.Run("producer_injects_trace_context_headers", func(t *testing.T) {
+		syncProducer := saramamocks.NewSyncProducer(t, nil)
+		producer := kafka.NewProducerWithSyncProducer(kafkaConfig, syncProducer, nil, nil)
+
+		ctx, span := tracing.StartPublishSpan(context.Background(), newTracedEvent(), kafkaConfig.TopicEvents)
+		defer span.End()
+
+		syncProducer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(message *sarama.ProducerMessage) error {
+			for _, header := range message.Headers {
+				if string(header.Key) == tracing.TraceParentKey {
+					expected := fmt.Sprintf("00-%s-%s-01", span.SpanContext().TraceID(), span.SpanContext().SpanID())
+					if string(header.Value) != expected {
+						return fmt.Errorf("unexpected traceparent %s, expected %s", header.Value, expected)
+					}
+					return nil
+				}
+			}
+			return fmt.Errorf("traceparent header not found")
+		})
+
+		require.NoError(t, producer.PublishEvent(ctx, newTracedEvent()))
+		require.NoError(t, syncProducer.Close())
+	})
+
+	t.Run("producer_without_trace_adds_no_headers", func(t *testing.T) {
+		syncProducer := saramamocks.NewSyncProducer(t, nil)
+		producer := kafka.NewProducerWithSyncProducer(kafkaConfig, syncProducer, nil, nil)
+
+		syncProducer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(message *sarama.ProducerMessage) error {
+			for _, header := range message.Headers {
+				if string(header.Key) == tracing.TraceParentKey {
+					return fmt.Errorf("unexpected traceparent header")
+				}
+			}
+			return nil
+		})
+
+		require.NoError(t, producer.PublishEvent(context.Background(), newTracedEvent()))
+		require.NoError(t, syncProducer.Close())
+	})
+}
+
+func TestTracingConfigValidation(t *testing.T) {
+	valid := config.TracingConfig{
+		Enabled:     true,
+		Exporter:    config.TracingExporterOTLP,
+		Endpoint:    "localhost:4318",
+		SampleRatio: 1,
+	}
+	assert.NoError(t, valid.Validate())
+
+	stdout := valid
+	stdout.Exporter = config.TracingExporterStdout
+	stdout.Endpoint = ""
+	assert.NoError(t, stdout.Validate())
+
+	invalidExporter := valid
+	invalidExporter.Exporter = "zipkin"
+	assert.Error(t, invalidExporter.Validate())
+
+	missingEndpoint := valid
+	missingEndpoint.Endpoint = ""
+	assert.Error(t, missingEndpoint.Validate())
+
+	invalidRatio := valid
+	invalidRatio.SampleRatio = 1.5
+	assert.Error(t, invalidRatio.Validate())
+}
@@ -0,0 +1,21 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+)
+
+func TestWatchdogConfigValidation(t *testing.T) {
+	cfg := config.WatchdogConfig{Interval: 30 * time.Second, StuckThreshold: 5 * time.Minute}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.EscalateStuckAfter = time.Minute
+	assert.Error(t, cfg.Validate(), "events must be stuck before they are escalated")
+
+	cfg.EscalateStuckAfter = time.Hour
+	assert.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,148 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/handlers"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/kafka"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/worker"
+)
+
+const adminToken = "test-admin-token"
+
+// breakerProducer is a producer stub that only tracks the forced circuit breaker state
+type breakerProducer struct {
+	open bool
+}
+
+func (p *breakerProducer) PublishEvent(ctx context.Context, event *models.OutboxEvent) error {
+	return nil
+}
+func (p *breakerProducer) PublishEventWithOptions(ctx context.Context, event *models.OutboxEvent, opts kafka.PublishOptions) error {
+	return nil
+}
+func (p *breakerProducer) Close() error                    { return nil }
+func (p *breakerProducer) IsConnected() bool               { return true }
+func (p *breakerProducer) GetConfig() *config.KafkaConfig  { return &config.KafkaConfig{} }
+func (p *breakerProducer) IsCircuitBreakerOpen() bool      { return p.open }
+func (p *breakerProducer) IsCircuitBreakerHalfOpen() bool  { return false }
+func (p *breakerProducer) ForceCircuitBreakerOpen()        { p.open = true }
+func (p *breakerProducer) ForceCircuitBreakerClose()       { p.open = false }
+func (p *breakerProducer) IsExponentialRetryEnabled() bool { return false }
+func (p *breakerProducer) GetRetryConfig() map[string]interface{} {
+	return map[string]interface{}{"max_retries": 3}
+}
+func (p *breakerProducer) GetCircuitBreakerStats() map[string]interface{} {
+	state := "CLOSED"
+	if p.open {
+		state = "OPEN"
+	}
+	return map[string]interface{}{"state": state}
+}
+
+func newAdminRouter(t *testing.T) (*mux.Router, *worker.OutboxWorker, *breakerProducer) {
+	cfg := &config.Config{
+		Worker: config.WorkerConfig{
+			PoolSize:        2,
+			BatchSize:       10,
+			Interval:        time.Second,
+			MaxRetries:      3,
+			LeaseDuration:   time.Minute,
+			ShutdownTimeout: time.Second,
+			PriorityWeights: "high=6,normal=3,low=1",
+		},
+	}
+	producer := &breakerProducer{}
+	outboxWorker := worker.NewOutboxWorker(cfg, nil, producer, nil, nil)
+
+	router := mux.NewRouter()
+	router.Use(handlers.BearerTokenMiddleware(adminToken))
+	handlers.NewWorkerAdminHandler(outboxWorker, producer, cfg.Worker).RegisterRoutes(router)
+
+	return router, outboxWorker, producer
+}
+
+func adminRequest(router http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestWorkerAdminHandler(t *testing.T) {
+	t.Run("rejects_requests_without_token", func(t *testing.T) {
+		router, _, _ := newAdminRouter(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/worker/status", nil)
+		req.Header.Set("Authorization", "Bearer wrong-token")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("pause_drain_and_resume", func(t *testing.T) {
+		router, outboxWorker, _ := newAdminRouter(t)
+
+		rec := adminRequest(router, http.MethodPost, "/worker/pause", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, outboxWorker.IsPaused())
+
+		rec = adminRequest(router, http.MethodPost, "/worker/drain?timeout=1s", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, outboxWorker.IsPaused())
+
+		rec = adminRequest(router, http.MethodPost, "/worker/resume", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.False(t, outboxWorker.IsPaused())
+	})
+
+	t.Run("updates_settings_at_runtime", func(t *testing.T) {
+		router, outboxWorker, _ := newAdminRouter(t)
+
+		rec := adminRequest(router, http.MethodPatch, "/worker/settings", `{"batch_size": 50, "interval": "250ms", "pool_size": 8}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		settings := outboxWorker.Settings()
+		assert.Equal(t, 50, settings.BatchSize)
+		assert.Equal(t, 250*time.Millisecond, settings.Interval)
+		assert.Equal(t, 8, settings.PoolSize)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "250ms", body["interval"])
+	})
+
+	t.Run("rejects_invalid_settings", func(t *testing.T) {
+		router, outboxWorker, _ := newAdminRouter(t)
+
+		rec := adminRequest(router, http.MethodPatch, "/worker/settings", `{"batch_size": 50, "pool_size": 0}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, 10, outboxWorker.Settings().BatchSize)
+	})
+
+	t.Run("forces_circuit_breaker", func(t *testing.T) {
+		router, _, producer := newAdminRouter(t)
+
+		rec := adminRequest(router, http.MethodPost, "/circuit-breaker/open", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, producer.open)
+
+		rec = adminRequest(router, http.MethodPost, "/circuit-breaker/close", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.False(t, producer.open)
+	})
+}
@@ -0,0 +1,176 @@
+package unit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lorenaziviani/txstream/internal/infrastructure/config"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/kafka"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/models"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/repositories"
+	"github.com/lorenaziviani/txstream/internal/infrastructure/worker"
+)
+
+// claimOutboxRepository serves pending events to the worker and records the calls it receives
+type claimOutboxRepository struct {
+	repositories.OutboxRepository
+
+	mu      sync.Mutex
+	pending []models.OutboxEvent
+	calls   []string
+	markErr error
+	// claimedElsewhere are the pending events whose claim another worker holds
+	claimedElsewhere map[uuid.UUID]bool
+}
+
+func (r *claimOutboxRepository) CountPendingEventsByPriority(ctx context.Context) (map[models.OutboxPriority]int64, error) {
+	return map[models.OutboxPriority]int64{}, nil
+}
+
+func (r *claimOutboxRepository) GetPendingEventsByPriority(ctx context.Context, priority models.OutboxPriority, limit int) ([]models.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []models.OutboxEvent
+	for _, event := range r.pending {
+		if event.Priority == priority && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r *claimOutboxRepository) ClaimEvent(ctx context.Context, id, workerID string, lease time.Duration) (*models.OutboxEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, event := range r.pending {
+		if event.ID.String() == id && !r.claimedElsewhere[event.ID] {
+			r.pending = append(r.pending[:i], r.pending[i+1:]...)
+			return &event, nil
+		}
+	}
+	return nil, repositories.ErrEventNotClaimable
+}
+
+func (r *claimOutboxRepository) MarkAsPublished(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, "mark_published")
+	r.markErr = ctx.Err()
+	return ctx.Err()
+}
+
+func (r *claimOutboxRepository) ReleaseClaims(ctx context.Context, workerID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, "release_claims")
+	return 0, nil
+}
+
+func (r *claimOutboxRepository) recorded() ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...), r.markErr
+}
+
+// ackOnCancelProducer blocks every publish until its context is cancelled and then reports
+// it as acknowledged, like a broker ack racing with the shutdown deadline
+type ackOnCancelProducer struct {
+	breakerProducer
+	started chan struct{}
+}
+
+func (p *ackOnCancelProducer) PublishEventWithOptions(ctx context.Context, event *models.OutboxEvent, opts kafka.PublishOptions) error {
+	p.started <- struct{}{}
+	<-ctx.Done()
+	return nil
+}
+
+func TestWorkerShutdownDeadline(t *testing.T) {
+	cfg := &config.Config{
+		Worker: config.WorkerConfig{
+			PoolSize:        1,
+			BatchSize:       10,
+			Interval:        10 * time.Millisecond,
+			MaxRetries:      3,
+			LeaseDuration:   time.Minute,
+			ShutdownTimeout: time.Second,
+		},
+	}
+	repo := &claimOutboxRepository{pending: []models.OutboxEvent{{
+		ID:            uuid.New(),
+		AggregateID:   "order-1",
+		AggregateType: "Order",
+		EventType:     "OrderCreated",
+		Status:        models.OutboxStatusPending,
+		CreatedAt:     time.Now(),
+	}}}
+	producer := &ackOnCancelProducer{started: make(chan struct{}, 1)}
+
+	outboxWorker := worker.NewOutboxWorker(cfg, repo, producer, nil, nil)
+	require.NoError(t, outboxWorker.Start(context.Background()))
+
+	select {
+	case <-producer.started:
+	case <-time.After(time.Second):
+		t.Fatal("the worker never published the pending event")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Error(t, outboxWorker.Shutdown(ctx), "the publish outlived the shutdown deadline")
+
+	calls, markErr := repo.recorded()
+	assert.NoError(t, markErr, "an acknowledged publish is recorded on a context the deadline does not cancel")
+	assert.Equal(t, []string{"mark_published", "release_claims"}, calls,
+		"the claims are released after the cancelled publish returned")
+}
+
+func TestWorkerStopsAggregateBehindEventClaimedElsewhere(t *testing.T) {
+	cfg := &config.Config{
+		Worker: config.WorkerConfig{
+			PoolSize:        1,
+			BatchSize:       10,
+			Interval:        5 * time.Millisecond,
+			MaxRetries:      3,
+			LeaseDuration:   time.Minute,
+			ShutdownTimeout: time.Second,
+		},
+	}
+	newEvent := func(version int64) models.OutboxEvent {
+		return models.OutboxEvent{
+			ID:               uuid.New(),
+			AggregateID:      "order-1",
+			AggregateType:    "Order",
+			AggregateVersion: version,
+			EventType:        "OrderUpdated",
+			Status:           models.OutboxStatusPending,
+			CreatedAt:        time.Now(),
+		}
+	}
+	first, second := newEvent(1), newEvent(2)
+	repo := &claimOutboxRepository{
+		pending:          []models.OutboxEvent{first, second},
+		claimedElsewhere: map[uuid.UUID]bool{first.ID: true},
+	}
+	producer := &recordingProducer{}
+
+	outboxWorker := worker.NewOutboxWorker(cfg, repo, producer, nil, nil)
+	require.NoError(t, outboxWorker.Start(context.Background()))
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, outboxWorker.Shutdown(context.Background()))
+
+	assert.Empty(t, producer.messages, "version 2 waits for the worker publishing version 1")
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	assert.Len(t, repo.pending, 2)
+}